@@ -11,12 +11,13 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/de4et/flight-booking/internal/config"
 	"github.com/de4et/flight-booking/internal/logger"
 	"github.com/de4et/flight-booking/internal/metrics"
 	"github.com/de4et/flight-booking/internal/server"
 )
 
-func gracefulShutdown(apiServer *http.Server, done chan bool) {
+func gracefulShutdown(apiServer *http.Server, workers <-chan struct{}, timeout time.Duration, done chan bool) {
 	// Create context that listens for the interrupt signal from the OS.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -27,14 +28,18 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 	log.Println("shutting down gracefully, press Ctrl+C again to force")
 	stop() // Allow Ctrl+C to force shutdown
 
-	// The context is used to inform the server it has 5 seconds to finish
+	// The context is used to inform the server how long it has to finish
 	// the request it is currently handling
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	if err := apiServer.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown with error: %v", err)
 	}
 
+	// Workers stop on shutdown, the search log still writes what is queued,
+	// every batch within its write timeout.
+	<-workers
+
 	log.Println("Server exiting")
 
 	// Notify the main goroutine that the shutdown is complete
@@ -44,10 +49,16 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 func main() {
 	logger.SetupLog("")
 
-	server := server.NewServer()
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"), os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	server, workers := server.NewServer(cfg)
 
 	go func() {
-		err := metrics.SetupMetrics(":8082")
+		err := metrics.SetupMetrics(cfg.Metrics.Addr)
 		if err != nil {
 			slog.Error("Couldn't setup metrics", "err", err)
 		}
@@ -57,10 +68,10 @@ func main() {
 	done := make(chan bool, 1)
 
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(server, workers, cfg.HTTP.ShutdownTimeout, done)
 
-	slog.Debug("Listening ", "port", os.Getenv("PORT"))
-	err := server.ListenAndServe()
+	slog.Debug("Listening ", "port", cfg.HTTP.Port)
+	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		panic(fmt.Sprintf("http server error: %s", err))
 	}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/de4et/flight-booking/internal/config"
+	"github.com/de4et/flight-booking/internal/database"
+)
+
+// Applies the pending database migrations, or lists them with -status.
+func main() {
+	status := flag.Bool("status", false, "list pending migrations without applying them")
+	flag.Parse()
+
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"), os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := database.New(cfg.Database)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.MigrateTimeout)
+	defer cancel()
+
+	if *status {
+		pending, err := database.Pending(ctx, db.DB())
+		if err != nil {
+			log.Fatalf("couldn't read migrations: %v", err)
+		}
+		for _, m := range pending {
+			fmt.Printf("pending %04d %s\n", m.Version, m.Name)
+		}
+		if len(pending) == 0 {
+			fmt.Println("up to date")
+		}
+		return
+	}
+
+	applied, err := database.Migrate(ctx, db.DB())
+	for _, m := range applied {
+		fmt.Printf("applied %04d %s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Printf("migration failed: %v", err)
+		os.Exit(1)
+	}
+	if len(applied) == 0 {
+		fmt.Println("up to date")
+	}
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ban"
+)
+
+type BanRepository struct {
+	db *sql.DB
+}
+
+func NewBanRepository(db *sql.DB) *BanRepository {
+	return &BanRepository{
+		db: db,
+	}
+}
+
+func (r *BanRepository) List(ctx context.Context) ([]ban.Ban, error) {
+	return r.query(ctx, `SELECT id, type, value, partner_code, reason, expires_at, created_at
+		FROM bans ORDER BY id`)
+}
+
+// ListActive returns bans that have no expiry or expire after now.
+func (r *BanRepository) ListActive(ctx context.Context, now time.Time) ([]ban.Ban, error) {
+	return r.query(ctx, `SELECT id, type, value, partner_code, reason, expires_at, created_at
+		FROM bans WHERE expires_at IS NULL OR expires_at > $1 ORDER BY id`, now)
+}
+
+func (r *BanRepository) Create(ctx context.Context, b ban.Ban) (ban.Ban, error) {
+	err := r.db.QueryRowContext(ctx, `INSERT INTO bans (type, value, partner_code, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		b.Type, b.Value, b.PartnerCode, b.Reason, b.ExpiresAt,
+	).Scan(&b.ID, &b.CreatedAt)
+	if err != nil {
+		return ban.Ban{}, err
+	}
+	return b, nil
+}
+
+func (r *BanRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM bans WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ban.ErrNotFound
+	}
+	return nil
+}
+
+func (r *BanRepository) query(ctx context.Context, q string, args ...any) ([]ban.Ban, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans := make([]ban.Ban, 0)
+	for rows.Next() {
+		var b ban.Ban
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&b.ID, &b.Type, &b.Value, &b.PartnerCode, &b.Reason, &expiresAt, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			b.ExpiresAt = &expiresAt.Time
+		}
+		bans = append(bans, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
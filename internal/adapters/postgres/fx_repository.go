@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+)
+
+type FXRepository struct {
+	db *sql.DB
+}
+
+func NewFXRepository(db *sql.DB) *FXRepository {
+	return &FXRepository{
+		db: db,
+	}
+}
+
+// List returns all rates, the ones taking effect first come first. Past
+// rates are kept, so prices can be checked against the rate of their time.
+func (r *FXRepository) List(ctx context.Context) ([]fx.Rate, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, from_currency, to_currency, rate, effective_from, created_at
+		FROM fx_rates ORDER BY effective_from, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]fx.Rate, 0)
+	for rows.Next() {
+		var rate fx.Rate
+		if err := rows.Scan(&rate.ID, &rate.From, &rate.To, &rate.Rate, &rate.EffectiveFrom, &rate.CreatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// Save stores the rate, replacing the one of the pair with the same
+// effective date, so loading a file again doesn't duplicate rates.
+func (r *FXRepository) Save(ctx context.Context, rate fx.Rate) (fx.Rate, error) {
+	err := r.db.QueryRowContext(ctx, `INSERT INTO fx_rates (from_currency, to_currency, rate, effective_from)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (from_currency, to_currency, effective_from) DO UPDATE SET rate = EXCLUDED.rate
+		RETURNING id, created_at`,
+		rate.From, rate.To, rate.Rate, rate.EffectiveFrom,
+	).Scan(&rate.ID, &rate.CreatedAt)
+	if err != nil {
+		return fx.Rate{}, err
+	}
+	return rate, nil
+}
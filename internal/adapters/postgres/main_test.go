@@ -0,0 +1,74 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/de4et/flight-booking/internal/database"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctr, err := tcpostgres.Run(ctx, "postgres:16-alpine",
+		tcpostgres.WithDatabase("flights"),
+		tcpostgres.WithUsername("flights"),
+		tcpostgres.WithPassword("flights"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	defer func() {
+		if err := testcontainers.TerminateContainer(ctr); err != nil {
+			log.Printf("couldn't terminate postgres container: %v", err)
+		}
+	}()
+	if err != nil {
+		log.Printf("couldn't start postgres container: %v", err)
+		return 1
+	}
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		log.Printf("couldn't get postgres address: %v", err)
+		return 1
+	}
+	testDB, err = sql.Open("pgx", dsn)
+	if err != nil {
+		log.Printf("couldn't connect to postgres: %v", err)
+		return 1
+	}
+	defer testDB.Close()
+
+	if _, err := database.Migrate(ctx, testDB); err != nil {
+		log.Printf("couldn't migrate: %v", err)
+		return 1
+	}
+	return m.Run()
+}
+
+// truncate empties the tables before a test.
+func truncate(t *testing.T, tables ...string) {
+	t.Helper()
+	for _, table := range tables {
+		if _, err := testDB.Exec(`TRUNCATE ` + table + ` RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/order"
+)
+
+const selectOrders = `SELECT id, status, partner_code, token, canonical_token, provider, booking_reference, is_test,
+	trip, passengers, contacts, created_at, updated_at FROM orders`
+
+type OrderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) *OrderRepository {
+	return &OrderRepository{
+		db: db,
+	}
+}
+
+func (r *OrderRepository) Create(ctx context.Context, o order.Order) (order.Order, error) {
+	trip, passengers, contacts, err := marshalOrder(o)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	var holdUntil sql.NullTime
+	if t := o.HoldUntil(); !t.IsZero() {
+		holdUntil = sql.NullTime{Time: t, Valid: true}
+	}
+
+	err = r.db.QueryRowContext(ctx, `INSERT INTO orders
+		(status, partner_code, token, canonical_token, provider, booking_reference, is_test, trip, passengers, contacts, hold_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at, updated_at`,
+		o.Status, o.PartnerCode, o.Token, o.CanonicalToken, o.Provider, o.BookingReference, o.IsTest, trip, passengers, contacts, holdUntil,
+	).Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return order.Order{}, err
+	}
+	return o, nil
+}
+
+func (r *OrderRepository) Get(ctx context.Context, id int64) (order.Order, error) {
+	orders, err := r.query(ctx, selectOrders+` WHERE id = $1`, id)
+	if err != nil {
+		return order.Order{}, err
+	}
+	if len(orders) == 0 {
+		return order.Order{}, order.ErrNotFound
+	}
+	return orders[0], nil
+}
+
+// ListExpiring returns up to limit booked or expiring orders held until
+// before, the ones expiring first come first. Created orders are included
+// only if created before createdBefore, younger ones may be being booked.
+func (r *OrderRepository) ListExpiring(ctx context.Context, before, createdBefore time.Time, limit int) ([]order.Order, error) {
+	return r.query(ctx, selectOrders+` WHERE hold_until <= $1
+		AND (status IN ($2, $3) OR (status = $4 AND created_at <= $5))
+		ORDER BY hold_until LIMIT $6`,
+		before, order.StatusBooked, order.StatusExpiring, order.StatusCreated, createdBefore, limit)
+}
+
+// UpdateStatus stores the order status and booking reference if the stored
+// status is still from, so concurrent changes don't overwrite each other.
+// The change is recorded in order_events in the same transaction.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, o *order.Order, from order.Status, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `UPDATE orders SET status = $1, booking_reference = $2, updated_at = now()
+		WHERE id = $3 AND status = $4 RETURNING updated_at`,
+		o.Status, o.BookingReference, o.ID, from,
+	).Scan(&o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return order.ErrStatusChanged
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO order_events (order_id, from_status, to_status, reason)
+		VALUES ($1, $2, $3, $4)`, o.ID, from, o.Status, reason)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Events returns the status changes of the order, oldest first.
+func (r *OrderRepository) Events(ctx context.Context, orderID int64) ([]order.Event, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, order_id, from_status, to_status, reason, created_at
+		FROM order_events WHERE order_id = $1 ORDER BY id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]order.Event, 0)
+	for rows.Next() {
+		var e order.Event
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.From, &e.To, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *OrderRepository) query(ctx context.Context, q string, args ...any) ([]order.Order, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]order.Order, 0)
+	for rows.Next() {
+		var o order.Order
+		var trip, passengers, contacts []byte
+		if err := rows.Scan(&o.ID, &o.Status, &o.PartnerCode, &o.Token, &o.CanonicalToken, &o.Provider, &o.BookingReference, &o.IsTest,
+			&trip, &passengers, &contacts, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := unmarshalOrder(&o, trip, passengers, contacts); err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func marshalOrder(o order.Order) (trip, passengers, contacts []byte, err error) {
+	if trip, err = json.Marshal(o.Trip); err != nil {
+		return nil, nil, nil, err
+	}
+	if passengers, err = json.Marshal(o.Passengers); err != nil {
+		return nil, nil, nil, err
+	}
+	if contacts, err = json.Marshal(o.Contacts); err != nil {
+		return nil, nil, nil, err
+	}
+	return trip, passengers, contacts, nil
+}
+
+func unmarshalOrder(o *order.Order, trip, passengers, contacts []byte) error {
+	if err := json.Unmarshal(trip, &o.Trip); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(passengers, &o.Passengers); err != nil {
+		return err
+	}
+	return json.Unmarshal(contacts, &o.Contacts)
+}
@@ -0,0 +1,58 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/adapters/postgres"
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func TestOrderRepository_UpdateStatus(t *testing.T) {
+	truncate(t, "orders")
+	ctx := context.Background()
+	repo := postgres.NewOrderRepository(testDB)
+
+	o := order.New("AKV40000OWE1000000091MOWLED20241015", trip.Trip{CacheID: "trip_1"}, nil, order.Contacts{Email: "a@b.c"})
+	o.PartnerCode = "AKV4"
+	o, err := repo.Create(ctx, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Transition(order.StatusBooked); err != nil {
+		t.Fatal(err)
+	}
+	o.BookingReference = "PNR123"
+	if err := repo.UpdateStatus(ctx, &o, order.StatusCreated, "booked"); err != nil {
+		t.Fatal(err)
+	}
+	// The stored status is no longer created.
+	if err := repo.UpdateStatus(ctx, &o, order.StatusCreated, "booked again"); !errors.Is(err, order.ErrStatusChanged) {
+		t.Errorf("UpdateStatus() from a stale status error = %v, want %v", err, order.ErrStatusChanged)
+	}
+
+	got, err := repo.Get(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != order.StatusBooked || got.BookingReference != "PNR123" || got.PartnerCode != "AKV4" {
+		t.Errorf("Get() = %+v, want booked PNR123 of AKV4", got)
+	}
+
+	events, err := repo.Events(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].From != order.StatusCreated || events[0].To != order.StatusBooked {
+		t.Errorf("Events() = %+v, want one created to booked", events)
+	}
+
+	if _, err := repo.Get(ctx, o.ID+1); !errors.Is(err, order.ErrNotFound) {
+		t.Errorf("Get() of a missing order error = %v, want %v", err, order.ErrNotFound)
+	}
+}
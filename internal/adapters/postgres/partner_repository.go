@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+)
+
+const selectPartners = `SELECT id, code, name, secret, sources, providers, max_passengers, booking_enabled,
+	requests_per_second, searches_per_minute, daily_searches, created_at
+	FROM partners`
+
+// uniqueViolation is the Postgres error code of a duplicate key.
+const uniqueViolation = "23505"
+
+type PartnerRepository struct {
+	db *sql.DB
+}
+
+func NewPartnerRepository(db *sql.DB) *PartnerRepository {
+	return &PartnerRepository{
+		db: db,
+	}
+}
+
+func (r *PartnerRepository) List(ctx context.Context) ([]partner.Partner, error) {
+	return r.query(ctx, selectPartners+` ORDER BY id`)
+}
+
+func (r *PartnerRepository) Create(ctx context.Context, p partner.Partner) (partner.Partner, error) {
+	sources, err := json.Marshal(nonNil(p.Sources))
+	if err != nil {
+		return partner.Partner{}, err
+	}
+	providers, err := json.Marshal(nonNil(p.Providers))
+	if err != nil {
+		return partner.Partner{}, err
+	}
+
+	err = r.db.QueryRowContext(ctx, `INSERT INTO partners
+		(code, name, secret, sources, providers, max_passengers, booking_enabled,
+		requests_per_second, searches_per_minute, daily_searches)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`,
+		p.Code, p.Name, p.Secret, sources, providers, p.MaxPassengers, p.BookingEnabled,
+		p.Limits.RequestsPerSecond, p.Limits.SearchesPerMinute, p.Limits.DailySearches,
+	).Scan(&p.ID, &p.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return partner.Partner{}, fmt.Errorf("%w: code %s is taken", partner.ErrInvalidPartner, p.Code)
+	}
+	if err != nil {
+		return partner.Partner{}, err
+	}
+	return p, nil
+}
+
+func (r *PartnerRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM partners WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return partner.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PartnerRepository) query(ctx context.Context, q string, args ...any) ([]partner.Partner, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partners := make([]partner.Partner, 0)
+	for rows.Next() {
+		var p partner.Partner
+		var sources, providers []byte
+		if err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.Secret, &sources, &providers,
+			&p.MaxPassengers, &p.BookingEnabled,
+			&p.Limits.RequestsPerSecond, &p.Limits.SearchesPerMinute, &p.Limits.DailySearches, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(sources, &p.Sources); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(providers, &p.Providers); err != nil {
+			return nil, err
+		}
+		partners = append(partners, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/adapters/postgres"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestPartnerRepository(t *testing.T) {
+	truncate(t, "partners")
+	ctx := context.Background()
+	repo := postgres.NewPartnerRepository(testDB)
+
+	p, err := repo.Create(ctx, partner.Partner{
+		Code:           "AKV4",
+		Name:           "Aviakassa",
+		Secret:         "secret",
+		Sources:        []string{"0000"},
+		MaxPassengers:  4,
+		BookingEnabled: true,
+		Limits:         ratelimit.Limits{RequestsPerSecond: 5, DailySearches: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID == 0 || p.CreatedAt.IsZero() {
+		t.Errorf("Create() didn't set id and creation time: %+v", p)
+	}
+
+	_, err = repo.Create(ctx, partner.Partner{Code: "AKV4", Name: "Other", Secret: "other"})
+	if !errors.Is(err, partner.ErrInvalidPartner) {
+		t.Errorf("Create() with a taken code error = %v, want %v", err, partner.ErrInvalidPartner)
+	}
+
+	got, err := repo.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []partner.Partner{p}
+	want[0].Providers = []string{}
+	if diff := cmp.Diff(want, got, cmpopts.EquateApproxTime(0)); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := repo.Delete(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, p.ID); !errors.Is(err, partner.ErrNotFound) {
+		t.Errorf("Delete() of a deleted partner error = %v, want %v", err, partner.ErrNotFound)
+	}
+}
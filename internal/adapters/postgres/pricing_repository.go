@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/pricing"
+)
+
+const selectPricingRules = `SELECT id, name, priority, partner_code, source_code, route, carrier, class, provider,
+	departure_from, departure_to, valid_from, valid_to,
+	markup_percent, markup_amount, markup_currency, commission_percent, affiliate_fee_percent, cashback_rate, created_at
+	FROM pricing_rules`
+
+type PricingRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRepository(db *sql.DB) *PricingRepository {
+	return &PricingRepository{
+		db: db,
+	}
+}
+
+func (r *PricingRepository) List(ctx context.Context) ([]pricing.Rule, error) {
+	return r.query(ctx, selectPricingRules+` ORDER BY id`)
+}
+
+// ListActive returns rules valid at now.
+func (r *PricingRepository) ListActive(ctx context.Context, now time.Time) ([]pricing.Rule, error) {
+	return r.query(ctx, selectPricingRules+` WHERE (valid_from IS NULL OR valid_from <= $1)
+		AND (valid_to IS NULL OR valid_to > $1) ORDER BY id`, now)
+}
+
+func (r *PricingRepository) Create(ctx context.Context, rule pricing.Rule) (pricing.Rule, error) {
+	err := r.db.QueryRowContext(ctx, `INSERT INTO pricing_rules
+		(name, priority, partner_code, source_code, route, carrier, class, provider,
+		departure_from, departure_to, valid_from, valid_to,
+		markup_percent, markup_amount, markup_currency, commission_percent, affiliate_fee_percent, cashback_rate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, created_at`,
+		rule.Name, rule.Priority, rule.PartnerCode, rule.SourceCode, rule.Route, rule.Carrier, rule.Class, rule.Provider,
+		rule.DepartureFrom, rule.DepartureTo, rule.ValidFrom, rule.ValidTo,
+		rule.MarkupPercent, rule.MarkupAmount, rule.MarkupCurrency, rule.CommissionPercent, rule.AffiliateFeePercent, rule.CashbackRate,
+	).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		return pricing.Rule{}, err
+	}
+	return rule, nil
+}
+
+func (r *PricingRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM pricing_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pricing.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PricingRepository) query(ctx context.Context, q string, args ...any) ([]pricing.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]pricing.Rule, 0)
+	for rows.Next() {
+		var rule pricing.Rule
+		var departureFrom, departureTo, validFrom, validTo sql.NullTime
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Priority, &rule.PartnerCode, &rule.SourceCode,
+			&rule.Route, &rule.Carrier, &rule.Class, &rule.Provider,
+			&departureFrom, &departureTo, &validFrom, &validTo,
+			&rule.MarkupPercent, &rule.MarkupAmount, &rule.MarkupCurrency, &rule.CommissionPercent, &rule.AffiliateFeePercent,
+			&rule.CashbackRate, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rule.DepartureFrom = nullTime(departureFrom)
+		rule.DepartureTo = nullTime(departureTo)
+		rule.ValidFrom = nullTime(validFrom)
+		rule.ValidTo = nullTime(validTo)
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,47 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/adapters/postgres"
+	"github.com/de4et/flight-booking/internal/model/pricing"
+)
+
+func TestPricingRepository_ListActive(t *testing.T) {
+	truncate(t, "pricing_rules")
+	ctx := context.Background()
+	repo := postgres.NewPricingRepository(testDB)
+
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	rules := []pricing.Rule{
+		{Name: "always", MarkupPercent: 2},
+		{Name: "expired", ValidTo: &past, MarkupPercent: 3},
+		{Name: "upcoming", ValidFrom: &future, MarkupPercent: 4},
+		{Name: "current", ValidFrom: &past, ValidTo: &future, MarkupAmount: 100, MarkupCurrency: "RUB"},
+	}
+	for _, r := range rules {
+		if _, err := repo.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active, err := repo.ListActive(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, r := range active {
+		names[r.Name] = true
+	}
+	if len(active) != 2 || !names["always"] || !names["current"] {
+		t.Errorf("ListActive() = %v, want always and current", active)
+	}
+	if active[1].MarkupCurrency != "RUB" {
+		t.Errorf("ListActive() markup currency = %q, want RUB", active[1].MarkupCurrency)
+	}
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/de4et/flight-booking/internal/model/analytics"
+)
+
+// searchLogColumns is the number of values inserted per search.
+const searchLogColumns = 11
+
+// lookToBook counts production searches and orders per partner in [$1, $2).
+// Searches are matched with the booked orders made from them by canonical
+// token. Partners with orders but no logged searches are reported too.
+const lookToBook = `
+SELECT partner_code,
+	COALESCE(s.searches, 0), COALESCE(s.misses, 0), COALESCE(s.booked_searches, 0),
+	COALESCE(o.orders, 0), COALESCE(o.booked, 0)
+FROM (
+	SELECT l.partner_code, count(*) AS searches, count(*) FILTER (WHERE NOT l.cache_hit) AS misses,
+		count(DISTINCT l.token) FILTER (WHERE EXISTS (
+			SELECT 1 FROM orders b
+			WHERE b.canonical_token = l.token AND b.partner_code = l.partner_code
+				AND b.booking_reference <> '' AND NOT b.is_test
+		)) AS booked_searches
+	FROM search_logs l
+	WHERE NOT l.is_test AND l.created_at >= $1 AND l.created_at < $2
+	GROUP BY l.partner_code
+) s
+FULL JOIN (
+	SELECT partner_code, count(*) AS orders, count(*) FILTER (WHERE booking_reference <> '') AS booked
+	FROM orders
+	WHERE NOT is_test AND created_at >= $1 AND created_at < $2
+	GROUP BY partner_code
+) o USING (partner_code)
+ORDER BY partner_code`
+
+type SearchLogRepository struct {
+	db *sql.DB
+}
+
+func NewSearchLogRepository(db *sql.DB) *SearchLogRepository {
+	return &SearchLogRepository{
+		db: db,
+	}
+}
+
+// SaveSearches inserts the searches with one statement.
+func (r *SearchLogRepository) SaveSearches(ctx context.Context, searches []analytics.Search) error {
+	if len(searches) == 0 {
+		return nil
+	}
+
+	var q strings.Builder
+	q.WriteString(`INSERT INTO search_logs
+		(token, partner_code, source_code, cache_hit, providers, results, min_price, currency, latency_ms, is_test, created_at)
+		VALUES `)
+	args := make([]any, 0, len(searches)*searchLogColumns)
+	for i, s := range searches {
+		providers, err := json.Marshal(nonNil(s.Providers))
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			q.WriteString(", ")
+		}
+		q.WriteString("(")
+		for j := range searchLogColumns {
+			if j > 0 {
+				q.WriteString(", ")
+			}
+			fmt.Fprintf(&q, "$%d", i*searchLogColumns+j+1)
+		}
+		q.WriteString(")")
+
+		args = append(args, s.Token, s.PartnerCode, s.SourceCode, s.CacheHit, providers,
+			s.Results, s.MinPrice, s.Currency, s.Latency.Milliseconds(), s.IsTest, s.At)
+	}
+
+	_, err := r.db.ExecContext(ctx, q.String(), args...)
+	return err
+}
+
+// LookToBook returns the production searches and orders per partner created
+// in the period.
+func (r *SearchLogRepository) LookToBook(ctx context.Context, p analytics.Period) ([]analytics.LookToBook, error) {
+	rows, err := r.db.QueryContext(ctx, lookToBook, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]analytics.LookToBook, 0)
+	for rows.Next() {
+		var l analytics.LookToBook
+		if err := rows.Scan(&l.PartnerCode, &l.Searches, &l.CacheMisses, &l.BookedSearches, &l.Orders, &l.Booked); err != nil {
+			return nil, err
+		}
+		l.ComputeRatio()
+		res = append(res, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
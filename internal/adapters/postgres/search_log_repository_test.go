@@ -0,0 +1,67 @@
+//go:build integration
+
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/adapters/postgres"
+	"github.com/de4et/flight-booking/internal/model/analytics"
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/trip"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSearchLogRepository_LookToBook(t *testing.T) {
+	truncate(t, "search_logs", "orders")
+	ctx := context.Background()
+	repo := postgres.NewSearchLogRepository(testDB)
+	orders := postgres.NewOrderRepository(testDB)
+
+	now := time.Now()
+	searches := []analytics.Search{
+		{Token: "a", PartnerCode: "AKV4", SourceCode: "0000", Providers: []string{"MyMan"}, Results: 3, MinPrice: 100, At: now},
+		{Token: "a", PartnerCode: "AKV4", SourceCode: "0000", CacheHit: true, Results: 3, MinPrice: 100, At: now},
+		{Token: "a", PartnerCode: "AKV4", SourceCode: "0000", CacheHit: true, Results: 3, MinPrice: 100, At: now},
+		{Token: "a", PartnerCode: "AKV4", SourceCode: "0000", CacheHit: true, Results: 3, MinPrice: 100, At: now},
+		// Test traffic and searches out of the period are not counted.
+		{Token: "b", PartnerCode: "AKV4", SourceCode: "0000", IsTest: true, At: now},
+		{Token: "c", PartnerCode: "AKV4", SourceCode: "0000", At: now.Add(-48 * time.Hour)},
+		{Token: "d", PartnerCode: "BIGP", SourceCode: "0001", At: now},
+	}
+	if err := repo.SaveSearches(ctx, searches); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{"PNR1", "PNR2", ""} {
+		o := order.New("a", trip.Trip{}, nil, order.Contacts{Email: "a@b.c"})
+		o.PartnerCode = "AKV4"
+		o.CanonicalToken = "a"
+		o.BookingReference = ref
+		if _, err := orders.Create(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	o := order.New("e", trip.Trip{}, nil, order.Contacts{Email: "a@b.c"})
+	o.PartnerCode = "CHEK"
+	o.BookingReference = "PNR3"
+	if _, err := orders.Create(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.LookToBook(ctx, analytics.Period{From: now.Add(-time.Hour), To: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []analytics.LookToBook{
+		{PartnerCode: "AKV4", Searches: 4, CacheMisses: 1, BookedSearches: 1, Orders: 3, Booked: 2, Ratio: 2},
+		{PartnerCode: "BIGP", Searches: 1, CacheMisses: 1},
+		{PartnerCode: "CHEK", Orders: 1, Booked: 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LookToBook() mismatch (-want +got):\n%s", diff)
+	}
+}
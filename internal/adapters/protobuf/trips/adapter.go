@@ -5,6 +5,7 @@ import (
 
 	sroAdapter "github.com/de4et/flight-booking/internal/adapters/protobuf/sro"
 
+	"github.com/de4et/flight-booking/internal/model/airport"
 	"github.com/de4et/flight-booking/internal/model/sro"
 	"github.com/de4et/flight-booking/internal/model/trip"
 
@@ -169,7 +170,8 @@ func protoToFlightPoint(fp *FlightPoint) trip.FlightPoint {
 	}
 	var t time.Time
 	if fp.GetTime() != nil {
-		t = fp.GetTime().AsTime()
+		// Timestamps are stored in UTC, restore the airport's local time.
+		t = airport.Default().In(fp.GetAirport(), fp.GetTime().AsTime())
 	}
 	return trip.FlightPoint{
 		Airport:  fp.GetAirport(),
@@ -203,6 +205,11 @@ func pricesToProto(p trip.TripPrices) *TripPrices {
 		passengersPriceDetails[k] = v
 	}
 
+	var original *TripPrices
+	if p.Original != nil {
+		original = pricesToProto(*p.Original)
+	}
+
 	return &TripPrices{
 		Price:                  p.Price,
 		SearchPrice:            p.SearchPrice,
@@ -216,6 +223,9 @@ func pricesToProto(p trip.TripPrices) *TripPrices {
 		PricerInfo:             pricerInfoToProto(p.PricerInfo),
 		BagsPrice:              p.BagsPrice,
 		PassengersPriceDetails: passengersPriceDetails,
+		Currency:               p.Currency,
+		ExchangeRate:           p.ExchangeRate,
+		Original:               original,
 	}
 }
 
@@ -229,6 +239,12 @@ func protoToPrices(p *TripPrices) trip.TripPrices {
 		passengersPriceDetails[k] = v
 	}
 
+	var original *trip.TripPrices
+	if p.GetOriginal() != nil {
+		o := protoToPrices(p.GetOriginal())
+		original = &o
+	}
+
 	return trip.TripPrices{
 		Price:                  p.GetPrice(),
 		SearchPrice:            p.GetSearchPrice(),
@@ -242,6 +258,9 @@ func protoToPrices(p *TripPrices) trip.TripPrices {
 		PricerInfo:             protoToPricerInfo(p.GetPricerInfo()),
 		BagsPrice:              p.GetBagsPrice(),
 		PassengersPriceDetails: passengersPriceDetails,
+		Currency:               p.GetCurrency(),
+		ExchangeRate:           p.GetExchangeRate(),
+		Original:               original,
 	}
 }
 
@@ -251,6 +270,9 @@ func pricerInfoToProto(pi trip.PricerInfo) *PricerInfo {
 		Commission:          pi.Commission,
 		PartnerAffiliateFee: pi.PartnerAffiliateFee,
 		CashbackRate:        pi.CashbackRate,
+		RuleId:              pi.RuleID,
+		RuleName:            pi.RuleName,
+		MinPriceApplied:     pi.MinPriceApplied,
 	}
 }
 
@@ -263,6 +285,9 @@ func protoToPricerInfo(pi *PricerInfo) trip.PricerInfo {
 		Commission:          pi.GetCommission(),
 		PartnerAffiliateFee: pi.GetPartnerAffiliateFee(),
 		CashbackRate:        pi.GetCashbackRate(),
+		RuleID:              pi.GetRuleId(),
+		RuleName:            pi.GetRuleName(),
+		MinPriceApplied:     pi.GetMinPriceApplied(),
 	}
 }
 
@@ -423,6 +423,9 @@ type TripPrices struct {
 	PricerInfo             *PricerInfo            `protobuf:"bytes,10,opt,name=pricer_info,json=pricerInfo,proto3" json:"pricer_info,omitempty"`
 	BagsPrice              float64                `protobuf:"fixed64,11,opt,name=bags_price,json=bagsPrice,proto3" json:"bags_price,omitempty"`
 	PassengersPriceDetails map[string]float64     `protobuf:"bytes,12,rep,name=passengers_price_details,json=passengersPriceDetails,proto3" json:"passengers_price_details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Currency               string                 `protobuf:"bytes,13,opt,name=currency,proto3" json:"currency,omitempty"`
+	ExchangeRate           float64                `protobuf:"fixed64,14,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	Original               *TripPrices            `protobuf:"bytes,15,opt,name=original,proto3" json:"original,omitempty"`
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
@@ -541,12 +544,36 @@ func (x *TripPrices) GetPassengersPriceDetails() map[string]float64 {
 	return nil
 }
 
+func (x *TripPrices) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *TripPrices) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+func (x *TripPrices) GetOriginal() *TripPrices {
+	if x != nil {
+		return x.Original
+	}
+	return nil
+}
+
 type PricerInfo struct {
 	state               protoimpl.MessageState `protogen:"open.v1"`
 	Markup              float64                `protobuf:"fixed64,1,opt,name=markup,proto3" json:"markup,omitempty"`
 	Commission          float64                `protobuf:"fixed64,2,opt,name=commission,proto3" json:"commission,omitempty"`
 	PartnerAffiliateFee float64                `protobuf:"fixed64,3,opt,name=partner_affiliate_fee,json=partnerAffiliateFee,proto3" json:"partner_affiliate_fee,omitempty"`
 	CashbackRate        float64                `protobuf:"fixed64,4,opt,name=cashback_rate,json=cashbackRate,proto3" json:"cashback_rate,omitempty"`
+	RuleId              int64                  `protobuf:"varint,5,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	RuleName            string                 `protobuf:"bytes,6,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
+	MinPriceApplied     bool                   `protobuf:"varint,7,opt,name=min_price_applied,json=minPriceApplied,proto3" json:"min_price_applied,omitempty"`
 	unknownFields       protoimpl.UnknownFields
 	sizeCache           protoimpl.SizeCache
 }
@@ -609,6 +636,27 @@ func (x *PricerInfo) GetCashbackRate() float64 {
 	return 0
 }
 
+func (x *PricerInfo) GetRuleId() int64 {
+	if x != nil {
+		return x.RuleId
+	}
+	return 0
+}
+
+func (x *PricerInfo) GetRuleName() string {
+	if x != nil {
+		return x.RuleName
+	}
+	return ""
+}
+
+func (x *PricerInfo) GetMinPriceApplied() bool {
+	if x != nil {
+		return x.MinPriceApplied
+	}
+	return false
+}
+
 type Provider struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -1130,7 +1178,7 @@ const file_internal_adapters_protobuf_trips_trips_proto_rawDesc = "" +
 	"\vBaggageInfo\x12\x16\n" +
 	"\x06pieces\x18\x01 \x01(\x05R\x06pieces\x12\x16\n" +
 	"\x06weight\x18\x02 \x01(\x05R\x06weight\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\tR\x04type\"\xe2\x04\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\"\xd2\x05\n" +
 	"\n" +
 	"TripPrices\x12\x14\n" +
 	"\x05price\x18\x01 \x01(\x01R\x05price\x12!\n" +
@@ -1148,10 +1196,13 @@ const file_internal_adapters_protobuf_trips_trips_proto_rawDesc = "" +
 	"pricerInfo\x12\x1d\n" +
 	"\n" +
 	"bags_price\x18\v \x01(\x01R\tbagsPrice\x12g\n" +
-	"\x18passengers_price_details\x18\f \x03(\v2-.trips.TripPrices.PassengersPriceDetailsEntryR\x16passengersPriceDetails\x1aI\n" +
+	"\x18passengers_price_details\x18\f \x03(\v2-.trips.TripPrices.PassengersPriceDetailsEntryR\x16passengersPriceDetails\x12\x1a\n" +
+	"\bcurrency\x18\r \x01(\tR\bcurrency\x12#\n" +
+	"\rexchange_rate\x18\x0e \x01(\x01R\fexchangeRate\x12-\n" +
+	"\boriginal\x18\x0f \x01(\v2\x11.trips.TripPricesR\boriginal\x1aI\n" +
 	"\x1bPassengersPriceDetailsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x9d\x01\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xff\x01\n" +
 	"\n" +
 	"PricerInfo\x12\x16\n" +
 	"\x06markup\x18\x01 \x01(\x01R\x06markup\x12\x1e\n" +
@@ -1159,7 +1210,10 @@ const file_internal_adapters_protobuf_trips_trips_proto_rawDesc = "" +
 	"commission\x18\x02 \x01(\x01R\n" +
 	"commission\x122\n" +
 	"\x15partner_affiliate_fee\x18\x03 \x01(\x01R\x13partnerAffiliateFee\x12#\n" +
-	"\rcashback_rate\x18\x04 \x01(\x01R\fcashbackRate\"\x9b\x01\n" +
+	"\rcashback_rate\x18\x04 \x01(\x01R\fcashbackRate\x12\x17\n" +
+	"\arule_id\x18\x05 \x01(\x03R\x06ruleId\x12\x1b\n" +
+	"\trule_name\x18\x06 \x01(\tR\bruleName\x12*\n" +
+	"\x11min_price_applied\x18\a \x01(\bR\x0fminPriceApplied\"\x9b\x01\n" +
 	"\bProvider\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
 	"\x03gds\x18\x02 \x01(\tR\x03gds\x12\x1d\n" +
@@ -1241,7 +1295,6 @@ var (
 		(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
 	}
 )
-
 var file_internal_adapters_protobuf_trips_trips_proto_depIdxs = []int32{
 	6,  // 0: trips.Trip.provider:type_name -> trips.Provider
 	1,  // 1: trips.Trip.segments:type_name -> trips.TripSegment
@@ -1256,17 +1309,18 @@ var file_internal_adapters_protobuf_trips_trips_proto_depIdxs = []int32{
 	14, // 10: trips.FlightPoint.time:type_name -> google.protobuf.Timestamp
 	5,  // 11: trips.TripPrices.pricer_info:type_name -> trips.PricerInfo
 	12, // 12: trips.TripPrices.passengers_price_details:type_name -> trips.TripPrices.PassengersPriceDetailsEntry
-	9,  // 13: trips.TripMetadata.fare_family:type_name -> trips.FareFamily
-	14, // 14: trips.TripBooking.expires_at:type_name -> google.protobuf.Timestamp
-	14, // 15: trips.TripBooking.ticketing_time_limit:type_name -> google.protobuf.Timestamp
-	14, // 16: trips.TripBooking.provider_recommendation_limit:type_name -> google.protobuf.Timestamp
-	14, // 17: trips.TripBooking.provider_recommendation_created:type_name -> google.protobuf.Timestamp
-	0,  // 18: trips.Trips.trips:type_name -> trips.Trip
-	19, // [19:19] is the sub-list for method output_type
-	19, // [19:19] is the sub-list for method input_type
-	19, // [19:19] is the sub-list for extension type_name
-	19, // [19:19] is the sub-list for extension extendee
-	0,  // [0:19] is the sub-list for field type_name
+	4,  // 13: trips.TripPrices.original:type_name -> trips.TripPrices
+	9,  // 14: trips.TripMetadata.fare_family:type_name -> trips.FareFamily
+	14, // 15: trips.TripBooking.expires_at:type_name -> google.protobuf.Timestamp
+	14, // 16: trips.TripBooking.ticketing_time_limit:type_name -> google.protobuf.Timestamp
+	14, // 17: trips.TripBooking.provider_recommendation_limit:type_name -> google.protobuf.Timestamp
+	14, // 18: trips.TripBooking.provider_recommendation_created:type_name -> google.protobuf.Timestamp
+	0,  // 19: trips.Trips.trips:type_name -> trips.Trip
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_internal_adapters_protobuf_trips_trips_proto_init() }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/de4et/flight-booking/internal/model/sro"
 	"github.com/de4et/flight-booking/internal/model/trip"
 	"github.com/de4et/flight-booking/internal/service"
 
@@ -16,6 +17,8 @@ import (
 const (
 	ttl     = time.Minute * 15
 	timeout = time.Second
+
+	keyPrefix = "cached_sro_"
 )
 
 type serializer interface {
@@ -28,10 +31,36 @@ type compressor interface {
 	Decompress(compressed []byte) ([]byte, error)
 }
 
+// TTLPolicy sets how long search results are cached. Searches departing
+// within NearDepartureWithin are kept for NearDeparture instead of Default,
+// as fares change faster close to departure. A zero NearDeparture disables it.
+type TTLPolicy struct {
+	Default             time.Duration
+	NearDeparture       time.Duration
+	NearDepartureWithin time.Duration
+}
+
+// TTL returns the TTL of the results of the SRO token at now.
+func (p TTLPolicy) TTL(token string, now time.Time) time.Duration {
+	if p.NearDeparture == 0 {
+		return p.Default
+	}
+	s, err := sro.FromToken(token)
+	if err != nil || len(s.Segments) == 0 {
+		return p.Default
+	}
+	if s.Segments[0].Date.Sub(now) < p.NearDepartureWithin {
+		return p.NearDeparture
+	}
+	return p.Default
+}
+
 type RedisSROCache struct {
 	client     *redis.Client
 	serializer serializer
 	compressor compressor
+	prefix     string
+	ttl        TTLPolicy
 }
 
 func NewRedisSROCache(addr, password string, serializer serializer, compressor compressor) (*RedisSROCache, error) {
@@ -51,11 +80,32 @@ func NewRedisSROCache(addr, password string, serializer serializer, compressor c
 		client:     rdb,
 		serializer: serializer,
 		compressor: compressor,
+		prefix:     keyPrefix,
+		ttl:        TTLPolicy{Default: ttl},
 	}, nil
 }
 
+// Namespace returns a cache on the same connection whose keys never collide
+// with the keys of c.
+func (c *RedisSROCache) Namespace(name string) *RedisSROCache {
+	ns := *c
+	ns.prefix = fmt.Sprintf("%s%s_", c.prefix, name)
+	return &ns
+}
+
+// SetTTLPolicy replaces the fixed 15 minutes TTL of cached results. The
+// namespaces created afterwards share it.
+func (c *RedisSROCache) SetTTLPolicy(p TTLPolicy) {
+	c.ttl = p
+}
+
+// Ping checks the connection to Redis.
+func (c *RedisSROCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
 func (c *RedisSROCache) Get(ctx context.Context, token string) (*trip.Trips, error) {
-	key := generateKey(token)
+	key := c.generateKey(token)
 	val, err := c.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -75,7 +125,7 @@ func (c *RedisSROCache) Get(ctx context.Context, token string) (*trip.Trips, err
 }
 
 func (c *RedisSROCache) Set(ctx context.Context, token string, ts *trip.Trips) error {
-	key := generateKey(token)
+	key := c.generateKey(token)
 	b, err := c.serializer.SerializeTrips(ts)
 	if err != nil {
 		return err
@@ -88,11 +138,11 @@ func (c *RedisSROCache) Set(ctx context.Context, token string, ts *trip.Trips) e
 		}
 	}
 
-	return c.client.Set(ctx, key, b, ttl).Err()
+	return c.client.Set(ctx, key, b, c.ttl.TTL(token, time.Now())).Err()
 }
 
-func generateKey(token string) string {
+func (c *RedisSROCache) generateKey(token string) string {
 	tokenHash := sha1.Sum([]byte(token))
 	key := string(tokenHash[:])
-	return fmt.Sprintf("cached_sro_%s", key)
+	return c.prefix + key
 }
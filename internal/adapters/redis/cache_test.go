@@ -0,0 +1,37 @@
+package redis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/adapters/redis"
+)
+
+func TestTTLPolicy_TTL(t *testing.T) {
+	now := time.Date(2024, 10, 13, 12, 0, 0, 0, time.UTC)
+	policy := redis.TTLPolicy{Default: 15 * time.Minute, NearDeparture: 2 * time.Minute, NearDepartureWithin: 72 * time.Hour}
+
+	tests := []struct {
+		name   string
+		policy redis.TTLPolicy
+		token  string
+		want   time.Duration
+	}{
+		{name: "near departure", policy: policy, token: "AKV40000OWE1000000091MOWLED20241015", want: 2 * time.Minute},
+		{name: "far departure", policy: policy, token: "AKV40000OWE1000000091MOWLED20241120", want: 15 * time.Minute},
+		{name: "invalid token", policy: policy, token: "AKV4", want: 15 * time.Minute},
+		{
+			name:   "disabled",
+			policy: redis.TTLPolicy{Default: 15 * time.Minute},
+			token:  "AKV40000OWE1000000091MOWLED20241015",
+			want:   15 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.TTL(tt.token, now); got != tt.want {
+				t.Errorf("TTL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
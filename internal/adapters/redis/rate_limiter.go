@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+)
+
+const rateLimitPrefix = "rate_limit_"
+
+// takeScript takes a token from the bucket at KEYS[1] refilled at ARGV[1]
+// tokens per second up to ARGV[2]. It returns 0 if the token was taken, or
+// the milliseconds until one is available. Time comes from Redis, so every
+// server instance shares one clock.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return wait
+`)
+
+// RedisRateLimiter keeps token buckets and daily counters in Redis, so limits
+// hold across server instances.
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(addr, password string) (*RedisRateLimiter, error) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       0,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &RedisRateLimiter{
+		client: rdb,
+	}, nil
+}
+
+// Take takes a token from the bucket of the key. It returns zero if it was
+// taken, or how long until one is available.
+func (l *RedisRateLimiter) Take(ctx context.Context, key string, b ratelimit.Bucket) (time.Duration, error) {
+	wait, err := takeScript.Run(ctx, l.client, []string{rateLimitPrefix + key}, b.Rate, b.Burst).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// Count adds one to the counter of the key for the UTC day of now and returns
+// the new value. Counters expire a day after their day ends.
+func (l *RedisRateLimiter) Count(ctx context.Context, key string, now time.Time) (int, error) {
+	end := ratelimit.EndOfDay(now)
+	k := fmt.Sprintf("%s%s_%s", rateLimitPrefix, key, now.UTC().Format("20060102"))
+
+	pipe := l.client.TxPipeline()
+	incr := pipe.Incr(ctx, k)
+	pipe.ExpireAt(ctx, k, end.Add(24*time.Hour))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/de4et/flight-booking/internal/model/supplier"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// ProviderTypeStub is the only provider type so far, a GDS stub answering
+// after a delay.
+const ProviderTypeStub = "stub"
+
+// Config of the API server. It is read from a YAML file, see
+// config.example.yaml, and environment variables override the file.
+type Config struct {
+	HTTP      HTTP      `yaml:"http"`
+	Admin     Admin     `yaml:"admin"`
+	Metrics   Metrics   `yaml:"metrics"`
+	Database  Database  `yaml:"database"`
+	Redis     Redis     `yaml:"redis"`
+	Cache     Cache     `yaml:"cache"`
+	Search    Search    `yaml:"search"`
+	Providers Providers `yaml:"providers"`
+	FX        FX        `yaml:"fx"`
+	RateLimit RateLimit `yaml:"rateLimit"`
+	SearchLog SearchLog `yaml:"searchLog"`
+	Orders    Orders    `yaml:"orders"`
+	Health    Health    `yaml:"health"`
+}
+
+type HTTP struct {
+	Port         int           `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout"`
+	// ShutdownTimeout is how long requests in progress may finish on
+	// shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// Admin guards the admin API, which manages partners, bans, pricing rules
+// and exchange rates of every partner.
+type Admin struct {
+	// Token is sent as a bearer token by admin API clients.
+	Token string `yaml:"token"`
+}
+
+type Metrics struct {
+	Addr string `yaml:"addr"`
+}
+
+type Database struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Name     string `yaml:"name"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Schema   string `yaml:"schema"`
+	// MigrateOnStart applies pending migrations at startup.
+	MigrateOnStart bool `yaml:"migrateOnStart"`
+	// MigrateTimeout bounds applying the migrations, they may rewrite large
+	// tables.
+	MigrateTimeout time.Duration `yaml:"migrateTimeout"`
+}
+
+type Redis struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password"`
+}
+
+// Addr returns the host:port address of Redis.
+func (r Redis) Addr() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+type Cache struct {
+	// GzipLevel compresses cached results, from 0 (none) to 9 (best).
+	GzipLevel int `yaml:"gzipLevel"`
+	TTL       TTL `yaml:"ttl"`
+}
+
+// TTL policy of cached search results. Fares change faster close to
+// departure, so searches departing within NearDepartureWithin are kept for
+// NearDeparture instead of Default. A zero NearDeparture disables it.
+type TTL struct {
+	Default             time.Duration `yaml:"default"`
+	NearDeparture       time.Duration `yaml:"nearDeparture"`
+	NearDepartureWithin time.Duration `yaml:"nearDepartureWithin"`
+}
+
+type Search struct {
+	// Timeout bounds a search of all providers.
+	Timeout time.Duration `yaml:"timeout"`
+	// RoundTripSplitPairs of OW results are combined into RT trips, zero
+	// disables it.
+	RoundTripSplitPairs int              `yaml:"roundTripSplitPairs"`
+	VirtualInterline    VirtualInterline `yaml:"virtualInterline"`
+}
+
+type VirtualInterline struct {
+	// Partners are the partner codes getting self-transfer itineraries,
+	// none when empty.
+	Partners []string `yaml:"partners"`
+	Hubs     []string `yaml:"hubs"`
+}
+
+type Providers struct {
+	Production []Provider `yaml:"production"`
+	// Sandbox providers serve test searches only.
+	Sandbox []Provider `yaml:"sandbox"`
+}
+
+type Provider struct {
+	// Name identifies the provider in trips, partner provider lists and
+	// health checks, it must be unique among the production or the sandbox
+	// providers.
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// GDS is the request format of the provider: amadeus, sabre or
+	// travelport.
+	GDS   supplier.GDS  `yaml:"gds"`
+	Delay time.Duration `yaml:"delay"`
+}
+
+type FX struct {
+	// RatesFile is a CSV of rates imported at startup.
+	RatesFile     string  `yaml:"ratesFile"`
+	MarginPercent float64 `yaml:"marginPercent"`
+	RoundingStep  float64 `yaml:"roundingStep"`
+}
+
+// RateLimit holds the default partner limits, zero values disable them.
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	SearchesPerMinute float64 `yaml:"searchesPerMinute"`
+	DailySearches     int     `yaml:"dailySearches"`
+}
+
+type SearchLog struct {
+	QueueSize int `yaml:"queueSize"`
+}
+
+type Orders struct {
+	// ExpiryLockKey is the Postgres advisory lock key electing the instance
+	// that expires orders.
+	ExpiryLockKey int64 `yaml:"expiryLockKey"`
+}
+
+type Health struct {
+	// Timeout bounds every dependency check.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Default returns the config used for values the file and the environment
+// don't set.
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Port:            8080,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     time.Minute,
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Metrics:  Metrics{Addr: ":8082"},
+		Database: Database{Port: 5432, Schema: "public", MigrateOnStart: true, MigrateTimeout: 5 * time.Minute},
+		Redis:    Redis{Port: 6379},
+		Cache: Cache{
+			GzipLevel: 6,
+			TTL:       TTL{Default: 15 * time.Minute},
+		},
+		Search: Search{Timeout: 30 * time.Second},
+		Providers: Providers{
+			Production: []Provider{
+				{Name: "stub-amadeus", Type: ProviderTypeStub, GDS: supplier.GDSAmadeus, Delay: 5 * time.Second},
+				{Name: "stub-sabre", Type: ProviderTypeStub, GDS: supplier.GDSSabre, Delay: time.Second},
+			},
+			Sandbox: []Provider{{Name: "sandbox", Type: ProviderTypeStub, GDS: supplier.GDSTravelport}},
+		},
+		FX:        FX{MarginPercent: 1, RoundingStep: 0.01},
+		SearchLog: SearchLog{QueueSize: 10000},
+		Orders:    Orders{ExpiryLockKey: 3901},
+		Health:    Health{Timeout: 2 * time.Second},
+	}
+}
+
+// Load returns the defaults overridden by the YAML file at path, if given,
+// and then by the environment looked up with env. Every invalid value is
+// reported in the error.
+func Load(path string, env func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		defer f.Close()
+
+		if err := decode(f, &cfg); err != nil {
+			return Config{}, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(env); err != nil {
+		return Config{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return cfg, nil
+}
+
+// decode reads YAML over cfg. Unknown keys are errors, so typos don't go
+// unnoticed.
+func decode(r io.Reader, cfg *Config) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	return dec.Decode(cfg)
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Port > 0 && c.HTTP.Port < 65536, "http.port %d is out of range", c.HTTP.Port)
+	check(c.HTTP.ReadTimeout > 0, "http.readTimeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http.writeTimeout must be positive")
+	check(c.HTTP.IdleTimeout > 0, "http.idleTimeout must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdownTimeout must be positive")
+	check(c.Admin.Token != "", "admin.token is required")
+	check(c.Metrics.Addr != "", "metrics.addr is required")
+
+	check(c.Database.Host != "", "database.host is required")
+	check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port %d is out of range", c.Database.Port)
+	check(c.Database.Name != "", "database.name is required")
+	check(c.Database.Username != "", "database.username is required")
+	check(c.Database.MigrateTimeout > 0, "database.migrateTimeout must be positive")
+
+	check(c.Redis.Host != "", "redis.host is required")
+	check(c.Redis.Port > 0 && c.Redis.Port < 65536, "redis.port %d is out of range", c.Redis.Port)
+
+	check(c.Cache.GzipLevel >= 0 && c.Cache.GzipLevel <= 9, "cache.gzipLevel %d must be from 0 to 9", c.Cache.GzipLevel)
+	check(c.Cache.TTL.Default > 0, "cache.ttl.default must be positive")
+	check(c.Cache.TTL.NearDeparture >= 0, "cache.ttl.nearDeparture must not be negative")
+	check(c.Cache.TTL.NearDeparture == 0 || c.Cache.TTL.NearDepartureWithin > 0,
+		"cache.ttl.nearDepartureWithin must be positive with cache.ttl.nearDeparture set")
+
+	check(c.Search.Timeout > 0, "search.timeout must be positive")
+	check(c.Search.RoundTripSplitPairs >= 0, "search.roundTripSplitPairs must not be negative")
+	check(len(c.Search.VirtualInterline.Partners) == 0 || len(c.Search.VirtualInterline.Hubs) > 0,
+		"search.virtualInterline.hubs are required with partners set")
+
+	check(len(c.Providers.Production) > 0, "providers.production must have at least one provider")
+	errs = append(errs, validateProviders("providers.production", c.Providers.Production)...)
+	errs = append(errs, validateProviders("providers.sandbox", c.Providers.Sandbox)...)
+
+	check(c.FX.MarginPercent >= 0, "fx.marginPercent must not be negative")
+	check(c.FX.RoundingStep >= 0, "fx.roundingStep must not be negative")
+	check(c.RateLimit.RequestsPerSecond >= 0 && c.RateLimit.SearchesPerMinute >= 0 && c.RateLimit.DailySearches >= 0,
+		"rateLimit values must not be negative")
+	check(c.SearchLog.QueueSize > 0, "searchLog.queueSize must be positive")
+	check(c.Health.Timeout > 0, "health.timeout must be positive")
+
+	return errors.Join(errs...)
+}
+
+func validateProviders(name string, providers []Provider) []error {
+	var errs []error
+	seen := make(map[string]bool, len(providers))
+	for i, p := range providers {
+		field := fmt.Sprintf("%s[%d]", name, i)
+		errs = append(errs, p.validate(field)...)
+		if p.Name != "" && seen[p.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %q is not unique", field, p.Name))
+		}
+		seen[p.Name] = true
+	}
+	return errs
+}
+
+func (p Provider) validate(name string) []error {
+	var errs []error
+	if p.Name == "" {
+		errs = append(errs, fmt.Errorf("%s.name is required", name))
+	}
+	if p.Type != ProviderTypeStub {
+		errs = append(errs, fmt.Errorf("%s.type %q is unknown", name, p.Type))
+	}
+	if !p.GDS.IsKnown() {
+		errs = append(errs, fmt.Errorf("%s.gds %q is unknown", name, p.GDS))
+	}
+	if p.Delay < 0 {
+		errs = append(errs, fmt.Errorf("%s.delay must not be negative", name))
+	}
+	return errs
+}
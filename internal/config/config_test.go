@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/de4et/flight-booking/internal/config"
+	"github.com/de4et/flight-booking/internal/model/supplier"
+)
+
+// requiredEnv sets the values without defaults.
+var requiredEnv = map[string]string{
+	"BLUEPRINT_DB_HOST":     "psql_bp",
+	"BLUEPRINT_DB_DATABASE": "blueprint",
+	"BLUEPRINT_DB_USERNAME": "melkey",
+	"REDIS_HOST":            "redis",
+	"ADMIN_TOKEN":           "s3cret",
+}
+
+func env(vars ...map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		for _, m := range vars {
+			if v, ok := m[name]; ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeFile(t, `
+http:
+  port: 9090
+cache:
+  ttl:
+    default: 10m
+    nearDeparture: 2m
+    nearDepartureWithin: 72h
+providers:
+  production:
+    - name: sabre
+      type: stub
+      gds: sabre
+      delay: 500ms
+search:
+  virtualInterline:
+    partners: [AKV4]
+    hubs: [IST]
+`)
+
+	cfg, err := config.Load(path, env(map[string]string{
+		"PORT":                   "8081",
+		"VIRTUAL_INTERLINE_HUBS": "IST, DXB",
+		"FX_RATES_FILE":          "",
+	}, requiredEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := config.Default()
+	want.HTTP.Port = 8081 // the environment overrides the file
+	want.Database.Host, want.Database.Name, want.Database.Username = "psql_bp", "blueprint", "melkey"
+	want.Redis.Host = "redis"
+	want.Admin.Token = "s3cret"
+	want.Cache.TTL = config.TTL{Default: 10 * time.Minute, NearDeparture: 2 * time.Minute, NearDepartureWithin: 72 * time.Hour}
+	want.Providers.Production = []config.Provider{{Name: "sabre", Type: config.ProviderTypeStub, GDS: supplier.GDSSabre, Delay: 500 * time.Millisecond}}
+	want.Search.VirtualInterline = config.VirtualInterline{Partners: []string{"AKV4"}, Hubs: []string{"IST", "DXB"}}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "unparsable environment",
+			env:  map[string]string{"PORT": "http", "SEARCH_TIMEOUT": "30", "DB_MIGRATE_ON_START": "sure"},
+			want: []string{`PORT: "http" is not a valid integer`, `SEARCH_TIMEOUT: "30" is not a valid duration`, `DB_MIGRATE_ON_START: "sure" is not a valid boolean`},
+		},
+		{
+			name: "unknown key",
+			file: "cache:\n  tll: 5m\n",
+			want: []string{"field tll not found"},
+		},
+		{
+			name: "invalid values",
+			file: "providers:\n  production:\n    - name: a\n      type: amadeus\n      gds: galileo\n    - name: a\n      type: stub\n      gds: sabre\n    - type: stub\n      gds: sabre\ncache:\n  gzipLevel: 11\ndatabase:\n  migrateTimeout: 0s\n",
+			want: []string{
+				`providers.production[0].type "amadeus" is unknown`, `providers.production[0].gds "galileo" is unknown`,
+				`providers.production[1].name "a" is not unique`, "providers.production[2].name is required",
+				"cache.gzipLevel 11 must be from 0 to 9", "database.migrateTimeout must be positive",
+			},
+		},
+		{
+			name: "missing required values",
+			env:  map[string]string{"BLUEPRINT_DB_HOST": "", "REDIS_HOST": "", "ADMIN_TOKEN": ""},
+			want: []string{"database.host is required", "redis.host is required", "admin.token is required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			if tt.file != "" {
+				path = writeFile(t, tt.file)
+			}
+
+			_, err := config.Load(path, env(tt.env, requiredEnv))
+			if !errors.Is(err, config.ErrInvalidConfig) {
+				t.Fatalf("Load() error = %v, want %v", err, config.ErrInvalidConfig)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := config.Load(filepath.Join(t.TempDir(), "missing.yaml"), env(requiredEnv))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+// The example file documents the defaults.
+func TestLoad_Example(t *testing.T) {
+	cfg, err := config.Load("../../config.example.yaml", env(requiredEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := config.Default()
+	want.Database.Host, want.Database.Name, want.Database.Username = "psql_bp", "blueprint", "melkey"
+	want.Redis.Host = "redis"
+	want.Admin.Token = "s3cret"
+	if diff := cmp.Diff(want, cfg, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("config.example.yaml differs from the defaults (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv overrides the config with the environment variables that are set.
+// The names are the ones used before the config file existed.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	e := envReader{lookup: lookup}
+
+	e.int("PORT", &c.HTTP.Port)
+	e.duration("HTTP_READ_TIMEOUT", &c.HTTP.ReadTimeout)
+	e.duration("HTTP_WRITE_TIMEOUT", &c.HTTP.WriteTimeout)
+	e.duration("HTTP_IDLE_TIMEOUT", &c.HTTP.IdleTimeout)
+	e.duration("HTTP_SHUTDOWN_TIMEOUT", &c.HTTP.ShutdownTimeout)
+	e.string("ADMIN_TOKEN", &c.Admin.Token)
+	e.string("METRICS_ADDR", &c.Metrics.Addr)
+
+	e.string("BLUEPRINT_DB_HOST", &c.Database.Host)
+	e.int("BLUEPRINT_DB_PORT", &c.Database.Port)
+	e.string("BLUEPRINT_DB_DATABASE", &c.Database.Name)
+	e.string("BLUEPRINT_DB_USERNAME", &c.Database.Username)
+	e.string("BLUEPRINT_DB_PASSWORD", &c.Database.Password)
+	e.string("BLUEPRINT_DB_SCHEMA", &c.Database.Schema)
+	e.bool("DB_MIGRATE_ON_START", &c.Database.MigrateOnStart)
+	e.duration("DB_MIGRATE_TIMEOUT", &c.Database.MigrateTimeout)
+
+	e.string("REDIS_HOST", &c.Redis.Host)
+	e.int("REDIS_PORT", &c.Redis.Port)
+	e.string("REDIS_PASSWORD", &c.Redis.Password)
+
+	e.int("GZIP_LEVEL", &c.Cache.GzipLevel)
+	e.duration("CACHE_TTL", &c.Cache.TTL.Default)
+	e.duration("CACHE_TTL_NEAR_DEPARTURE", &c.Cache.TTL.NearDeparture)
+	e.duration("CACHE_TTL_NEAR_DEPARTURE_WITHIN", &c.Cache.TTL.NearDepartureWithin)
+
+	e.duration("SEARCH_TIMEOUT", &c.Search.Timeout)
+	e.int("ROUND_TRIP_SPLIT_PAIRS", &c.Search.RoundTripSplitPairs)
+	e.list("VIRTUAL_INTERLINE_PARTNERS", &c.Search.VirtualInterline.Partners)
+	e.list("VIRTUAL_INTERLINE_HUBS", &c.Search.VirtualInterline.Hubs)
+
+	e.string("FX_RATES_FILE", &c.FX.RatesFile)
+	e.float("FX_MARGIN_PERCENT", &c.FX.MarginPercent)
+	e.float("FX_ROUNDING_STEP", &c.FX.RoundingStep)
+
+	e.float("RATE_LIMIT_REQUESTS_PER_SECOND", &c.RateLimit.RequestsPerSecond)
+	e.float("RATE_LIMIT_SEARCHES_PER_MINUTE", &c.RateLimit.SearchesPerMinute)
+	e.int("RATE_LIMIT_DAILY_SEARCHES", &c.RateLimit.DailySearches)
+
+	e.int("SEARCH_LOG_QUEUE_SIZE", &c.SearchLog.QueueSize)
+	e.int64("ORDER_EXPIRY_LOCK_KEY", &c.Orders.ExpiryLockKey)
+	e.duration("HEALTH_TIMEOUT", &c.Health.Timeout)
+
+	return errors.Join(e.errs...)
+}
+
+// envReader sets values from the variables that are set and not empty,
+// collecting parse errors.
+type envReader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (e *envReader) get(name string) (string, bool) {
+	v, ok := e.lookup(name)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+func (e *envReader) fail(name, v, kind string) {
+	e.errs = append(e.errs, fmt.Errorf("%s: %q is not a valid %s", name, v, kind))
+}
+
+func (e *envReader) string(name string, dst *string) {
+	if v, ok := e.get(name); ok {
+		*dst = v
+	}
+}
+
+func (e *envReader) int(name string, dst *int) {
+	v, ok := e.get(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.fail(name, v, "integer")
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) int64(name string, dst *int64) {
+	v, ok := e.get(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		e.fail(name, v, "integer")
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) float(name string, dst *float64) {
+	v, ok := e.get(name)
+	if !ok {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		e.fail(name, v, "number")
+		return
+	}
+	*dst = f
+}
+
+func (e *envReader) bool(name string, dst *bool) {
+	v, ok := e.get(name)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.fail(name, v, "boolean")
+		return
+	}
+	*dst = b
+}
+
+func (e *envReader) duration(name string, dst *time.Duration) {
+	v, ok := e.get(name)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.fail(name, v, "duration (e.g. 30s)")
+		return
+	}
+	*dst = d
+}
+
+// list reads comma-separated values.
+func (e *envReader) list(name string, dst *[]string) {
+	v, ok := e.get(name)
+	if !ok {
+		return
+	}
+	res := make([]string, 0)
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+	*dst = res
+}
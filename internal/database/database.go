@@ -5,75 +5,72 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
+	"net"
+	"net/url"
 	"strconv"
-	"time"
+
+	"github.com/de4et/flight-booking/internal/config"
+	"github.com/de4et/flight-booking/internal/health"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
-	_ "github.com/joho/godotenv/autoload"
 )
 
 // Service represents a service that interacts with a database.
 type Service interface {
-	// Health returns a map of health status information.
-	// The keys and values in the map are service-specific.
-	Health() map[string]string
+	// Health pings the database. Connection pool statistics are returned in
+	// the details, a pool under pressure reports degraded.
+	Health(ctx context.Context) health.Result
 
 	// Close terminates the database connection.
 	// It returns an error if the connection cannot be closed.
 	Close() error
+
+	// DB returns the underlying connection pool for repositories.
+	DB() *sql.DB
 }
 
 type service struct {
-	db *sql.DB
+	db   *sql.DB
+	name string
 }
 
-var (
-	database   = os.Getenv("BLUEPRINT_DB_DATABASE")
-	password   = os.Getenv("BLUEPRINT_DB_PASSWORD")
-	username   = os.Getenv("BLUEPRINT_DB_USERNAME")
-	port       = os.Getenv("BLUEPRINT_DB_PORT")
-	host       = os.Getenv("BLUEPRINT_DB_HOST")
-	schema     = os.Getenv("BLUEPRINT_DB_SCHEMA")
-	dbInstance *service
-)
+var dbInstance *service
 
-func New() Service {
+func New(cfg config.Database) Service {
 	// Reuse Connection
 	if dbInstance != nil {
 		return dbInstance
 	}
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s", username, password, host, port, database, schema)
+	connStr := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     cfg.Name,
+		RawQuery: url.Values{"sslmode": {"disable"}, "search_path": {cfg.Schema}}.Encode(),
+	}).String()
 	db, err := sql.Open("pgx", connStr)
 	if err != nil {
 		log.Fatal(err)
 	}
 	dbInstance = &service{
-		db: db,
+		db:   db,
+		name: cfg.Name,
 	}
 	return dbInstance
 }
 
 // Health checks the health of the database connection by pinging the database.
-// It returns a map with keys indicating various health statistics.
-func (s *service) Health() map[string]string {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-
-	stats := make(map[string]string)
-
+// It never exits the process: a failed ping only reports the database down.
+func (s *service) Health(ctx context.Context) health.Result {
 	// Ping the database
 	err := s.db.PingContext(ctx)
 	if err != nil {
-		stats["status"] = "down"
-		stats["error"] = fmt.Sprintf("db down: %v", err)
-		log.Fatalf("db down: %v", err) // Log the error and terminate the program
-		return stats
+		return health.Down(fmt.Errorf("db down: %w", err))
 	}
 
 	// Database is up, add more statistics
-	stats["status"] = "up"
-	stats["message"] = "It's healthy"
+	res := health.Up()
+	stats := map[string]string{"message": "It's healthy"}
 
 	// Get database stats (like open connections, in use, idle, etc.)
 	dbStats := s.db.Stats()
@@ -88,6 +85,7 @@ func (s *service) Health() map[string]string {
 	// Evaluate stats to provide a health message
 	if dbStats.OpenConnections > 40 { // Assuming 50 is the max for this example
 		stats["message"] = "The database is experiencing heavy load."
+		res.Status = health.StatusDegraded
 	}
 
 	if dbStats.WaitCount > 1000 {
@@ -102,7 +100,13 @@ func (s *service) Health() map[string]string {
 		stats["message"] = "Many connections are being closed due to max lifetime, consider increasing max lifetime or revising the connection usage pattern."
 	}
 
-	return stats
+	res.Details = stats
+	return res
+}
+
+// DB returns the underlying connection pool.
+func (s *service) DB() *sql.DB {
+	return s.db
 }
 
 // Close closes the database connection.
@@ -110,6 +114,6 @@ func (s *service) Health() map[string]string {
 // If the connection is successfully closed, it returns nil.
 // If an error occurs while closing the connection, it returns the error.
 func (s *service) Close() error {
-	log.Printf("Disconnected from database: %s", database)
+	log.Printf("Disconnected from database: %s", s.name)
 	return s.db.Close()
 }
@@ -0,0 +1,75 @@
+//go:build integration
+
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/de4et/flight-booking/internal/database"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctr, err := tcpostgres.Run(ctx, "postgres:16-alpine",
+		tcpostgres.WithDatabase("flights"),
+		tcpostgres.WithUsername("flights"),
+		tcpostgres.WithPassword("flights"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrations, err := database.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := database.Pending(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(migrations) {
+		t.Errorf("Pending() on an empty database = %d migrations, want %d", len(pending), len(migrations))
+	}
+
+	applied, err := database.Migrate(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Migrate() applied %d migrations, want %d", len(applied), len(migrations))
+	}
+
+	// Applied migrations are not run again.
+	applied, err = database.Migrate(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Migrate() applied %d migrations, want 0", len(applied))
+	}
+	if pending, err = database.Pending(ctx, db); err != nil || len(pending) != 0 {
+		t.Errorf("Pending() after Migrate() = %v, %v, want none", pending, err)
+	}
+}
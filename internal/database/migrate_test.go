@@ -0,0 +1,25 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/database"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := database.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %s has version %d, want %d: versions must have no gaps", m.Name, m.Version, i+1)
+		}
+		if m.SQL == "" {
+			t.Errorf("migration %d %s is empty", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS bans (
+	id           BIGSERIAL PRIMARY KEY,
+	type         TEXT        NOT NULL,
+	value        TEXT        NOT NULL,
+	partner_code TEXT        NOT NULL DEFAULT '',
+	reason       TEXT        NOT NULL,
+	expires_at   TIMESTAMPTZ,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS pricing_rules (
+	id                    BIGSERIAL PRIMARY KEY,
+	name                  TEXT             NOT NULL,
+	priority              INTEGER          NOT NULL DEFAULT 0,
+	partner_code          TEXT             NOT NULL DEFAULT '',
+	source_code           TEXT             NOT NULL DEFAULT '',
+	route                 TEXT             NOT NULL DEFAULT '',
+	carrier               TEXT             NOT NULL DEFAULT '',
+	class                 TEXT             NOT NULL DEFAULT '',
+	provider              TEXT             NOT NULL DEFAULT '',
+	departure_from        DATE,
+	departure_to          DATE,
+	valid_from            TIMESTAMPTZ,
+	valid_to              TIMESTAMPTZ,
+	markup_percent        DOUBLE PRECISION NOT NULL DEFAULT 0,
+	markup_amount         DOUBLE PRECISION NOT NULL DEFAULT 0,
+	commission_percent    DOUBLE PRECISION NOT NULL DEFAULT 0,
+	affiliate_fee_percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+	cashback_rate         DOUBLE PRECISION NOT NULL DEFAULT 0,
+	created_at            TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+	id             BIGSERIAL PRIMARY KEY,
+	from_currency  TEXT             NOT NULL,
+	to_currency    TEXT             NOT NULL,
+	rate           DOUBLE PRECISION NOT NULL,
+	effective_from TIMESTAMPTZ      NOT NULL,
+	created_at     TIMESTAMPTZ      NOT NULL DEFAULT now(),
+	UNIQUE (from_currency, to_currency, effective_from)
+);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS orders (
+	id                BIGSERIAL PRIMARY KEY,
+	status            TEXT        NOT NULL,
+	partner_code      TEXT        NOT NULL DEFAULT '',
+	token             TEXT        NOT NULL,
+	provider          TEXT        NOT NULL DEFAULT '',
+	booking_reference TEXT        NOT NULL DEFAULT '',
+	trip              JSONB       NOT NULL,
+	passengers        JSONB       NOT NULL,
+	contacts          JSONB       NOT NULL,
+	hold_until        TIMESTAMPTZ,
+	is_test           BOOLEAN     NOT NULL DEFAULT false,
+	created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS orders_status_hold_until_idx ON orders (status, hold_until);
+
+CREATE TABLE IF NOT EXISTS order_events (
+	id          BIGSERIAL PRIMARY KEY,
+	order_id    BIGINT      NOT NULL REFERENCES orders (id),
+	from_status TEXT        NOT NULL,
+	to_status   TEXT        NOT NULL,
+	reason      TEXT        NOT NULL DEFAULT '',
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
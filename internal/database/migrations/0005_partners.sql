@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS partners (
+	id                  BIGSERIAL PRIMARY KEY,
+	code                TEXT             NOT NULL UNIQUE,
+	name                TEXT             NOT NULL,
+	secret              TEXT             NOT NULL,
+	sources             JSONB            NOT NULL DEFAULT '[]',
+	providers           JSONB            NOT NULL DEFAULT '[]',
+	max_passengers      INTEGER          NOT NULL DEFAULT 0,
+	booking_enabled     BOOLEAN          NOT NULL DEFAULT false,
+	requests_per_second DOUBLE PRECISION NOT NULL DEFAULT 0,
+	searches_per_minute DOUBLE PRECISION NOT NULL DEFAULT 0,
+	daily_searches      INTEGER          NOT NULL DEFAULT 0,
+	created_at          TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
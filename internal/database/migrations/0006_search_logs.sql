@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS search_logs (
+	id           BIGSERIAL PRIMARY KEY,
+	token        TEXT             NOT NULL,
+	partner_code TEXT             NOT NULL,
+	source_code  TEXT             NOT NULL,
+	cache_hit    BOOLEAN          NOT NULL,
+	providers    JSONB            NOT NULL DEFAULT '[]',
+	results      INTEGER          NOT NULL,
+	min_price    DOUBLE PRECISION NOT NULL,
+	currency     TEXT             NOT NULL DEFAULT '',
+	latency_ms   INTEGER          NOT NULL,
+	is_test      BOOLEAN          NOT NULL DEFAULT false,
+	created_at   TIMESTAMPTZ      NOT NULL
+);
+CREATE INDEX IF NOT EXISTS search_logs_created_at_partner_code_idx ON search_logs (created_at, partner_code);
+CREATE INDEX IF NOT EXISTS orders_created_at_partner_code_idx ON orders (created_at, partner_code);
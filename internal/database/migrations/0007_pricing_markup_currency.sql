@@ -0,0 +1 @@
+ALTER TABLE pricing_rules ADD COLUMN IF NOT EXISTS markup_currency TEXT NOT NULL DEFAULT '';
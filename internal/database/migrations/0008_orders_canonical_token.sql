@@ -0,0 +1,2 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS canonical_token TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS orders_canonical_token_idx ON orders (canonical_token);
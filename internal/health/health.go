@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds every check of a report.
+const DefaultTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusUp Status = "up"
+	// StatusDegraded means the API works with reduced functionality, e.g.
+	// without the search cache or with a provider missing.
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Result of a check. Details are check-specific.
+type Result struct {
+	Status  Status            `json:"status"`
+	Error   string            `json:"error,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func Up() Result { return Result{Status: StatusUp} }
+
+func Down(err error) Result { return Result{Status: StatusDown, Error: err.Error()} }
+
+type Checker interface {
+	Check(context.Context) Result
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(context.Context) Result
+
+func (f CheckerFunc) Check(ctx context.Context) Result { return f(ctx) }
+
+// Ping returns a checker that is up while ping succeeds.
+func Ping(ping func(context.Context) error) Checker {
+	return CheckerFunc(func(ctx context.Context) Result {
+		if err := ping(ctx); err != nil {
+			return Down(err)
+		}
+		return Up()
+	})
+}
+
+// Report is the aggregated status of every dependency.
+type Report struct {
+	Status    Status            `json:"status"`
+	Checks    map[string]Result `json:"checks"`
+	CheckedAt time.Time         `json:"checkedAt"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	checker  Checker
+}
+
+// Registry runs the registered checks and aggregates them. The API is down
+// when a critical dependency is down, and degraded when any other one is
+// not up.
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Registry{
+		timeout: timeout,
+	}
+}
+
+// Register adds a check of the named dependency. The API can't serve
+// without a critical one.
+func (r *Registry) Register(name string, critical bool, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, critical: critical, checker: c})
+}
+
+// Check runs every check concurrently and aggregates the results.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, c.checker)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		Checks:    make(map[string]Result, len(checks)),
+		CheckedAt: time.Now(),
+	}
+	for i, c := range checks {
+		res := results[i]
+		report.Checks[c.name] = res
+		switch {
+		case res.Status == StatusUp:
+		case c.critical && res.Status == StatusDown:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run returns the result of the check, down if it doesn't finish in time.
+func run(ctx context.Context, c Checker) Result {
+	done := make(chan Result, 1)
+	go func() {
+		done <- c.Check(ctx)
+	}()
+
+	select {
+	case res := <-done:
+		return res
+	case <-ctx.Done():
+		return Down(ctx.Err())
+	}
+}
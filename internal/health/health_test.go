@@ -0,0 +1,83 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/health"
+)
+
+func result(status health.Status) health.Checker {
+	return health.CheckerFunc(func(context.Context) health.Result {
+		return health.Result{Status: status}
+	})
+}
+
+func TestRegistry_Check(t *testing.T) {
+	type check struct {
+		critical bool
+		status   health.Status
+	}
+	tests := []struct {
+		name   string
+		checks []check
+		want   health.Status
+	}{
+		{name: "no checks", want: health.StatusUp},
+		{
+			name:   "all up",
+			checks: []check{{true, health.StatusUp}, {false, health.StatusUp}},
+			want:   health.StatusUp,
+		},
+		{
+			name:   "optional down",
+			checks: []check{{true, health.StatusUp}, {false, health.StatusDown}},
+			want:   health.StatusDegraded,
+		},
+		{
+			name:   "critical degraded",
+			checks: []check{{true, health.StatusDegraded}, {false, health.StatusUp}},
+			want:   health.StatusDegraded,
+		},
+		{
+			name:   "critical down",
+			checks: []check{{false, health.StatusDown}, {true, health.StatusDown}, {false, health.StatusUp}},
+			want:   health.StatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := health.NewRegistry(time.Second)
+			for i, c := range tt.checks {
+				r.Register(string(rune('a'+i)), c.critical, result(c.status))
+			}
+
+			report := r.Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Check() status = %s, want %s", report.Status, tt.want)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Errorf("Check() reported %d checks, want %d", len(report.Checks), len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestRegistry_Check_Timeout(t *testing.T) {
+	r := health.NewRegistry(50 * time.Millisecond)
+	r.Register("stuck", true, health.CheckerFunc(func(context.Context) health.Result {
+		time.Sleep(time.Second)
+		return health.Up()
+	}))
+	r.Register("ping", false, health.Ping(func(context.Context) error { return errors.New("refused") }))
+
+	report := r.Check(context.Background())
+	if report.Status != health.StatusDown {
+		t.Errorf("Check() status = %s, want %s", report.Status, health.StatusDown)
+	}
+	if res := report.Checks["ping"]; res.Status != health.StatusDown || res.Error != "refused" {
+		t.Errorf("ping check = %+v, want down with the error", res)
+	}
+}
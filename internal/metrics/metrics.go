@@ -1,8 +1,14 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
+	"sync"
+
+	"github.com/de4et/flight-booking/internal/health"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -25,12 +31,67 @@ var (
 			0.99: 0.001,
 		},
 	}, []string{"method", "path", "status code"})
+	PartnerRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Name:      "partner_requests_total",
+		Help:      "Total amount of authenticated requests per partner",
+	}, []string{"partner"})
+	ProviderSearchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Name:      "provider_searches_total",
+		Help:      "Total amount of searches sent to providers (cache misses) per partner",
+	}, []string{"partner"})
+	RateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Name:      "rate_limited_total",
+		Help:      "Total amount of calls rejected by rate limits per partner and limit",
+	}, []string{"partner", "limit"})
+	SearchLogDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "app",
+		Name:      "search_log_dropped_total",
+		Help:      "Total amount of searches not written to analytics because the queue was full or the write failed",
+	})
 )
 
+// serving is the state of the metrics server, for health checks.
+var serving struct {
+	sync.Mutex
+	up  bool
+	err error
+}
+
 func SetupMetrics(host string) error {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
 
 	slog.Debug("Starting metrics...", "host", host)
-	return http.ListenAndServe(host, mux)
+	ln, err := net.Listen("tcp", host)
+	if err != nil {
+		setServing(false, err)
+		return err
+	}
+	setServing(true, nil)
+
+	err = http.Serve(ln, mux)
+	setServing(false, err)
+	return err
+}
+
+func setServing(up bool, err error) {
+	serving.Lock()
+	defer serving.Unlock()
+	serving.up, serving.err = up, err
+}
+
+// Health reports whether the metrics server is serving.
+func Health(context.Context) health.Result {
+	serving.Lock()
+	defer serving.Unlock()
+	if serving.err != nil {
+		return health.Down(serving.err)
+	}
+	if !serving.up {
+		return health.Down(errors.New("metrics server is not started"))
+	}
+	return health.Up()
 }
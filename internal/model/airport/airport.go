@@ -0,0 +1,175 @@
+package airport
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	// Bundle the timezone database so lookups work without system tzdata.
+	_ "time/tzdata"
+)
+
+var ErrUnknownCode = errors.New("unknown IATA code")
+
+type Kind string
+
+const (
+	KindAirport Kind = "airport"
+	KindCity    Kind = "city"
+)
+
+type Location struct {
+	Code     string `json:"code"`
+	Kind     Kind   `json:"kind"`
+	CityCode string `json:"cityCode"`
+	Country  string `json:"country"`
+	Timezone string `json:"timezone"`
+	Name     string `json:"name"`
+}
+
+//go:embed data/airports.csv
+var bundled []byte
+
+var (
+	defaultOnce sync.Once
+	defaultDir  *Directory
+)
+
+// Default returns the directory built from the bundled dataset.
+func Default() *Directory {
+	defaultOnce.Do(func() {
+		d, err := Load(bytes.NewReader(bundled))
+		if err != nil {
+			panic(fmt.Sprintf("invalid bundled airport data: %s", err))
+		}
+		defaultDir = d
+	})
+	return defaultDir
+}
+
+// Directory resolves IATA airport and city codes. A code may be both a city
+// and an airport (IST), in which case the airport wins in Lookup.
+type Directory struct {
+	airports     map[string]Location
+	cities       map[string]Location
+	cityAirports map[string][]string
+	zones        map[string]*time.Location
+}
+
+// Load reads "code,kind,city,country,timezone,name" records with a header row.
+func Load(r io.Reader) (*Directory, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty airport data")
+	}
+
+	d := &Directory{
+		airports:     make(map[string]Location),
+		cities:       make(map[string]Location),
+		cityAirports: make(map[string][]string),
+		zones:        make(map[string]*time.Location),
+	}
+
+	for i, rec := range records[1:] {
+		if len(rec) != 6 {
+			return nil, fmt.Errorf("line %d: expected 6 fields, got %d", i+2, len(rec))
+		}
+		l := Location{
+			Code:     rec[0],
+			Kind:     Kind(rec[1]),
+			CityCode: rec[2],
+			Country:  rec[3],
+			Timezone: rec[4],
+			Name:     rec[5],
+		}
+
+		if _, ok := d.zones[l.Timezone]; !ok {
+			tz, err := time.LoadLocation(l.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+2, err)
+			}
+			d.zones[l.Timezone] = tz
+		}
+
+		switch l.Kind {
+		case KindAirport:
+			d.airports[l.Code] = l
+			d.cityAirports[l.CityCode] = append(d.cityAirports[l.CityCode], l.Code)
+		case KindCity:
+			d.cities[l.Code] = l
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", i+2, l.Kind)
+		}
+	}
+
+	return d, nil
+}
+
+func (d *Directory) Lookup(code string) (Location, bool) {
+	if l, ok := d.airports[code]; ok {
+		return l, true
+	}
+	l, ok := d.cities[code]
+	return l, ok
+}
+
+// Country returns the ISO 3166-1 alpha-2 country of an airport or city code.
+func (d *Directory) Country(code string) (string, bool) {
+	l, ok := d.Lookup(code)
+	return l.Country, ok
+}
+
+// City returns the city code an airport belongs to. City codes map to themselves.
+func (d *Directory) City(code string) (string, bool) {
+	if _, ok := d.cities[code]; ok {
+		return code, true
+	}
+	l, ok := d.airports[code]
+	return l.CityCode, ok
+}
+
+// Airports expands a city code into its airports (MOW -> SVO, DME, VKO, ZIA).
+// An airport code expands to itself.
+func (d *Directory) Airports(code string) []string {
+	if _, ok := d.cities[code]; ok {
+		return d.cityAirports[code]
+	}
+	if _, ok := d.airports[code]; ok {
+		return []string{code}
+	}
+	return nil
+}
+
+// TimeZone returns the local timezone of an airport or city code.
+func (d *Directory) TimeZone(code string) (*time.Location, error) {
+	l, ok := d.Lookup(code)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCode, code)
+	}
+	return d.zones[l.Timezone], nil
+}
+
+// SameCity reports whether two codes belong to the same city (SVO and DME).
+func (d *Directory) SameCity(a, b string) bool {
+	ca, okA := d.City(a)
+	cb, okB := d.City(b)
+	return okA && okB && ca == cb
+}
+
+// In converts an instant to the local time of the airport. Unknown codes
+// leave the time unchanged.
+func (d *Directory) In(code string, t time.Time) time.Time {
+	tz, err := d.TimeZone(code)
+	if err != nil {
+		return t
+	}
+	return t.In(tz)
+}
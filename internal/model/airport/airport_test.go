@@ -0,0 +1,96 @@
+package airport_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/airport"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDirectory_Airports(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{name: "city", code: "MOW", want: []string{"SVO", "DME", "VKO", "ZIA"}},
+		{name: "city and airport", code: "IST", want: []string{"IST", "SAW"}},
+		{name: "airport", code: "LED", want: []string{"LED"}},
+		{name: "airport of a city", code: "DME", want: []string{"DME"}},
+		{name: "unknown", code: "XXX", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := airport.Default().Airports(tt.code)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Airports() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDirectory_Lookup(t *testing.T) {
+	dir := airport.Default()
+
+	if c, _ := dir.Country("VKO"); c != "RU" {
+		t.Errorf("Country(VKO) = %q, want RU", c)
+	}
+	if c, _ := dir.City("SVO"); c != "MOW" {
+		t.Errorf("City(SVO) = %q, want MOW", c)
+	}
+	if !dir.SameCity("SVO", "DME") {
+		t.Errorf("SameCity(SVO, DME) = false, want true")
+	}
+
+	tz, err := dir.TimeZone("OVB")
+	if err != nil {
+		t.Fatalf("TimeZone(OVB) error = %v", err)
+	}
+	if tz.String() != "Asia/Novosibirsk" {
+		t.Errorf("TimeZone(OVB) = %s, want Asia/Novosibirsk", tz)
+	}
+
+	if _, err := dir.TimeZone("XXX"); err == nil {
+		t.Errorf("TimeZone(XXX) error = nil, want error")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			data: "code,kind,city,country,timezone,name\nLED,airport,LED,RU,Europe/Moscow,Pulkovo\n",
+		},
+		{
+			name:    "unknown timezone",
+			data:    "code,kind,city,country,timezone,name\nLED,airport,LED,RU,Mars/Olympus,Pulkovo\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			data:    "code,kind,city,country,timezone,name\nLED,port,LED,RU,Europe/Moscow,Pulkovo\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			data:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := airport.Load(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
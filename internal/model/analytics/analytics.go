@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// Search is a search answered by the API, recorded for look-to-book reports.
+type Search struct {
+	// Token is the canonical token, equivalent searches share it.
+	Token       string
+	PartnerCode string
+	SourceCode  string
+	CacheHit    bool
+	// Providers are the names of the providers of the results.
+	Providers []string
+	Results   int
+	// MinPrice is the cheapest result price in Currency, zero without results.
+	MinPrice float64
+	Currency string
+	Latency  time.Duration
+	IsTest   bool
+	At       time.Time
+}
+
+// Summarize fills the providers, result count and minimum price of the
+// search from its results.
+func (s *Search) Summarize(ts *trip.Trips) {
+	s.Providers = s.Providers[:0]
+	s.Results = 0
+	s.MinPrice = 0
+	s.Currency = ""
+	for _, t := range ts.ToArray() {
+		if t.Provider.Name != "" && !slices.Contains(s.Providers, t.Provider.Name) {
+			s.Providers = append(s.Providers, t.Provider.Name)
+		}
+		if s.Results == 0 || t.GetPrice() < s.MinPrice {
+			s.MinPrice = t.GetPrice()
+			s.Currency = t.Prices.Currency
+		}
+		s.Results++
+	}
+	slices.Sort(s.Providers)
+}
+
+// LookToBook is the number of searches per order of a partner in a period.
+type LookToBook struct {
+	PartnerCode string `json:"partnerCode"`
+	Searches    int    `json:"searches"`
+	// CacheMisses are the searches that reached providers.
+	CacheMisses int `json:"cacheMisses"`
+	// BookedSearches are the distinct searches, by canonical token, that
+	// led to a booked order.
+	BookedSearches int `json:"bookedSearches"`
+	Orders         int `json:"orders"`
+	// Booked are the orders that got a booking with the provider.
+	Booked int `json:"booked"`
+	// Ratio is Searches per Booked, zero without bookings.
+	Ratio float64 `json:"ratio"`
+}
+
+// ComputeRatio sets Ratio from the counts.
+func (l *LookToBook) ComputeRatio() {
+	l.Ratio = 0
+	if l.Booked > 0 {
+		l.Ratio = float64(l.Searches) / float64(l.Booked)
+	}
+}
+
+// Period is a half-open time range [From, To) of a report.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+func (p Period) Validate() error {
+	if p.From.IsZero() || p.To.IsZero() || !p.From.Before(p.To) {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
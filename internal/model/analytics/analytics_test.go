@@ -0,0 +1,62 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/analytics"
+	"github.com/de4et/flight-booking/internal/model/trip"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSearch_Summarize(t *testing.T) {
+	ts := trip.NewTrips()
+	ts.AddTrip(trip.Trip{CacheID: "1", Provider: trip.Provider{Name: "B"}, Prices: trip.TripPrices{Price: 300, Currency: "RUB"}})
+	ts.AddTrip(trip.Trip{CacheID: "2", Provider: trip.Provider{Name: "A"}, Prices: trip.TripPrices{Price: 120, Currency: "RUB"}})
+	ts.AddTrip(trip.Trip{CacheID: "3", Provider: trip.Provider{Name: "B"}, Prices: trip.TripPrices{Price: 150, Currency: "RUB"}})
+
+	tests := []struct {
+		name string
+		ts   *trip.Trips
+		want analytics.Search
+	}{
+		{
+			name: "results",
+			ts:   ts,
+			want: analytics.Search{Providers: []string{"A", "B"}, Results: 3, MinPrice: 120, Currency: "RUB"},
+		},
+		{
+			name: "no results",
+			ts:   trip.NewTrips(),
+			want: analytics.Search{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got analytics.Search
+			got.Summarize(tt.ts)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Summarize() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLookToBook_ComputeRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		l    analytics.LookToBook
+		want float64
+	}{
+		{name: "booked", l: analytics.LookToBook{Searches: 250, Booked: 2}, want: 125},
+		{name: "no bookings", l: analytics.LookToBook{Searches: 250, Orders: 3}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.l.ComputeRatio()
+			if tt.l.Ratio != tt.want {
+				t.Errorf("Ratio = %v, want %v", tt.l.Ratio, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package ban
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var (
+	ErrInvalidBan = errors.New("invalid ban")
+	ErrNotFound   = errors.New("ban not found")
+)
+
+type Type string
+
+const (
+	TypeCarrier  Type = "carrier"  // marketing, operating or validating carrier (S7)
+	TypeFlight   Type = "flight"   // carrier + flight number (S71234)
+	TypeRoute    Type = "route"    // origin + destination (MOWLED)
+	TypeProvider Type = "provider" // provider name or GDS
+	TypeOffice   Type = "office"   // provider office ID
+	TypeFareCode Type = "fare"     // segment fare code
+)
+
+type Ban struct {
+	ID          int64      `json:"id"`
+	Type        Type       `json:"type"`
+	Value       string     `json:"value"`
+	PartnerCode string     `json:"partnerCode,omitempty"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func (b *Ban) Validate() error {
+	if !in(b.Type, TypeCarrier, TypeFlight, TypeRoute, TypeProvider, TypeOffice, TypeFareCode) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidBan, b.Type)
+	}
+	if strings.TrimSpace(b.Value) == "" {
+		return fmt.Errorf("%w: empty value", ErrInvalidBan)
+	}
+	if b.Type == TypeRoute && len(b.Value) != 6 {
+		return fmt.Errorf("%w: route must be 6 letters (MOWLED)", ErrInvalidBan)
+	}
+	if b.Reason == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidBan)
+	}
+	return nil
+}
+
+// IsGlobal reports whether the ban applies to every partner.
+func (b *Ban) IsGlobal() bool {
+	return b.PartnerCode == ""
+}
+
+func (b *Ban) IsActive(now time.Time) bool {
+	return b.ExpiresAt == nil || now.Before(*b.ExpiresAt)
+}
+
+func (b *Ban) AppliesTo(partnerCode string) bool {
+	return b.IsGlobal() || b.PartnerCode == partnerCode
+}
+
+func (b *Ban) Matches(t *trip.Trip) bool {
+	value := strings.ToUpper(b.Value)
+
+	switch b.Type {
+	case TypeCarrier:
+		if strings.EqualFold(t.Provider.ValidatingCarrier, value) {
+			return true
+		}
+		for _, s := range t.Segments {
+			if strings.EqualFold(s.Carrier, value) || strings.EqualFold(s.OperatingCarrier, value) {
+				return true
+			}
+		}
+	case TypeFlight:
+		for _, s := range t.Segments {
+			if strings.EqualFold(s.FlightNumber, value) || strings.EqualFold(s.Carrier+s.FlightNumber, value) {
+				return true
+			}
+		}
+	case TypeRoute:
+		return t.FliesRoute(value[:3], value[3:])
+	case TypeProvider:
+		return strings.EqualFold(t.Provider.Name, value) || strings.EqualFold(t.Provider.GDS, value)
+	case TypeOffice:
+		return strings.EqualFold(t.Provider.OfficeID, value)
+	case TypeFareCode:
+		for _, s := range t.Segments {
+			if strings.EqualFold(s.FareCode, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// List is a snapshot of bans that are active for a single partner.
+type List struct {
+	bans []Ban
+}
+
+func NewList(bans []Ban, partnerCode string, now time.Time) *List {
+	l := &List{}
+	for _, b := range bans {
+		if b.IsActive(now) && b.AppliesTo(partnerCode) {
+			l.bans = append(l.bans, b)
+		}
+	}
+	return l
+}
+
+// IsBanned returns the reason of the first ban matching the trip.
+func (l *List) IsBanned(t *trip.Trip) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	for i := range l.bans {
+		if l.bans[i].Matches(t) {
+			return l.bans[i].Reason, true
+		}
+	}
+	return "", false
+}
+
+func (l *List) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.bans)
+}
+
+func in[T comparable](v T, s ...T) bool {
+	return slices.Contains(s, v)
+}
@@ -0,0 +1,130 @@
+package ban_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ban"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func testTrip() trip.Trip {
+	return trip.Trip{
+		CacheID: "1",
+		Provider: trip.Provider{
+			Name:              "Sabre",
+			GDS:               "1S",
+			OfficeID:          "MOW123",
+			ValidatingCarrier: "SU",
+		},
+		Segments: []trip.TripSegment{
+			{
+				FlightNumber:     "1234",
+				Carrier:          "S7",
+				OperatingCarrier: "S7",
+				FareCode:         "YLOW",
+				Departure:        trip.FlightPoint{Airport: "DME"},
+				Arrival:          trip.FlightPoint{Airport: "OVB"},
+			},
+			{
+				FlightNumber: "5678",
+				Carrier:      "S7",
+				FareCode:     "YLOW",
+				Departure:    trip.FlightPoint{Airport: "OVB"},
+				Arrival:      trip.FlightPoint{Airport: "LED"},
+			},
+		},
+		SRO: &sro.SRO{
+			Segments: []sro.Segment{{From: "MOW", To: "LED"}},
+		},
+	}
+}
+
+func TestBan_Matches(t *testing.T) {
+	tests := []struct {
+		name string
+		ban  ban.Ban
+		want bool
+	}{
+		{name: "marketing carrier", ban: ban.Ban{Type: ban.TypeCarrier, Value: "s7"}, want: true},
+		{name: "validating carrier", ban: ban.Ban{Type: ban.TypeCarrier, Value: "SU"}, want: true},
+		{name: "other carrier", ban: ban.Ban{Type: ban.TypeCarrier, Value: "UT"}, want: false},
+		{name: "flight with carrier", ban: ban.Ban{Type: ban.TypeFlight, Value: "S75678"}, want: true},
+		{name: "other flight", ban: ban.Ban{Type: ban.TypeFlight, Value: "S71111"}, want: false},
+		{name: "route by city", ban: ban.Ban{Type: ban.TypeRoute, Value: "MOWLED"}, want: true},
+		{name: "route by airports", ban: ban.Ban{Type: ban.TypeRoute, Value: "DMELED"}, want: true},
+		{name: "connection is not a route", ban: ban.Ban{Type: ban.TypeRoute, Value: "DMEOVB"}, want: false},
+		{name: "provider by gds", ban: ban.Ban{Type: ban.TypeProvider, Value: "1S"}, want: true},
+		{name: "office", ban: ban.Ban{Type: ban.TypeOffice, Value: "MOW123"}, want: true},
+		{name: "fare code", ban: ban.Ban{Type: ban.TypeFareCode, Value: "YLOW"}, want: true},
+		{name: "other fare code", ban: ban.Ban{Type: ban.TypeFareCode, Value: "BFLEX"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := testTrip()
+			if got := tt.ban.Matches(&tr); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList_IsBanned(t *testing.T) {
+	now := time.Date(2024, 10, 15, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	valid := now.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		bans       []ban.Ban
+		partner    string
+		wantBanned bool
+		wantReason string
+	}{
+		{
+			name:       "global ban",
+			bans:       []ban.Ban{{Type: ban.TypeCarrier, Value: "S7", Reason: "global"}},
+			partner:    "AKV4",
+			wantBanned: true,
+			wantReason: "global",
+		},
+		{
+			name:       "ban of another partner",
+			bans:       []ban.Ban{{Type: ban.TypeCarrier, Value: "S7", PartnerCode: "OTHR", Reason: "other"}},
+			partner:    "AKV4",
+			wantBanned: false,
+		},
+		{
+			name:       "partner ban",
+			bans:       []ban.Ban{{Type: ban.TypeCarrier, Value: "S7", PartnerCode: "AKV4", Reason: "partner"}},
+			partner:    "AKV4",
+			wantBanned: true,
+			wantReason: "partner",
+		},
+		{
+			name:       "expired ban",
+			bans:       []ban.Ban{{Type: ban.TypeCarrier, Value: "S7", Reason: "expired", ExpiresAt: &expired}},
+			partner:    "AKV4",
+			wantBanned: false,
+		},
+		{
+			name:       "not yet expired ban",
+			bans:       []ban.Ban{{Type: ban.TypeCarrier, Value: "S7", Reason: "valid", ExpiresAt: &valid}},
+			partner:    "AKV4",
+			wantBanned: true,
+			wantReason: "valid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := testTrip()
+			reason, banned := ban.NewList(tt.bans, tt.partner, now).IsBanned(&tr)
+			if banned != tt.wantBanned || reason != tt.wantReason {
+				t.Errorf("IsBanned() = (%q, %v), want (%q, %v)", reason, banned, tt.wantReason, tt.wantBanned)
+			}
+		})
+	}
+}
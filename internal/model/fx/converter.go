@@ -0,0 +1,106 @@
+package fx
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+// Settings control how converted amounts are calculated.
+type Settings struct {
+	// MarginPercent is added to the rate of every conversion.
+	MarginPercent float64
+	// RoundingStep is the smallest unit converted amounts are rounded up to,
+	// 0.01 when zero.
+	RoundingStep float64
+}
+
+// Converter converts trip prices into the requested currency.
+type Converter struct {
+	rates    *Rates
+	settings Settings
+}
+
+func NewConverter(rates *Rates, settings Settings) *Converter {
+	if settings.RoundingStep <= 0 {
+		settings.RoundingStep = 0.01
+	}
+	return &Converter{
+		rates:    rates,
+		settings: settings,
+	}
+}
+
+// Rates returns the rates the converter uses.
+func (c *Converter) Rates() *Rates {
+	return c.rates
+}
+
+// Convert converts every amount of the trip prices into currency. The
+// amounts before the conversion are kept in Prices.Original, so converting
+// again starts from them. Trips without a known currency can't be converted.
+func (c *Converter) Convert(t *trip.Trip, currency string) error {
+	Restore(t)
+
+	from := t.Prices.ProviderCurrency
+	t.Prices.Currency = from
+	if currency == "" || from == currency {
+		return nil
+	}
+	if from == "" {
+		return fmt.Errorf("%w: %s from the unknown trip currency", ErrNoRate, currency)
+	}
+
+	rate, err := c.rates.Get(from, currency)
+	if err != nil {
+		return err
+	}
+	rate *= 1 + c.settings.MarginPercent/100
+
+	original := t.Prices
+	p := &t.Prices
+	conv := func(v float64) float64 {
+		return c.round(v * rate)
+	}
+
+	p.Price = conv(p.Price)
+	p.SearchPrice = conv(p.SearchPrice)
+	p.PriceFare = conv(p.PriceFare)
+	p.ProviderServiceFee = conv(p.ProviderServiceFee)
+	p.ProviderTaxesAmount = conv(p.ProviderTaxesAmount)
+	p.MinAllowablePrice = conv(p.MinAllowablePrice)
+	p.TkpTax = conv(p.TkpTax)
+	p.SpecTax = conv(p.SpecTax)
+	p.BagsPrice = conv(p.BagsPrice)
+	p.PricerInfo.Markup = conv(p.PricerInfo.Markup)
+	p.PricerInfo.Commission = conv(p.PricerInfo.Commission)
+	p.PricerInfo.PartnerAffiliateFee = conv(p.PricerInfo.PartnerAffiliateFee)
+
+	if original.PassengersPriceDetails != nil {
+		p.PassengersPriceDetails = make(map[string]float64, len(original.PassengersPriceDetails))
+		for k, v := range original.PassengersPriceDetails {
+			p.PassengersPriceDetails[k] = conv(v)
+		}
+	}
+
+	p.Currency = currency
+	p.ExchangeRate = rate
+	p.Original = &original
+	return nil
+}
+
+// Restore returns the trip prices to the amounts before conversion.
+func Restore(t *trip.Trip) {
+	if t.Prices.Original != nil {
+		t.Prices = *t.Prices.Original
+	}
+}
+
+// round rounds up to the step, so conversion never sells below the rate.
+// Amounts are first rounded to a tenth of a cent to drop float noise.
+func (c *Converter) round(v float64) float64 {
+	step := c.settings.RoundingStep
+	v = math.Round(v*1000) / 1000
+	return math.Round(math.Ceil(v/step-1e-9)*step*1e6) / 1e6
+}
@@ -0,0 +1,108 @@
+package fx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func TestConverter_Convert(t *testing.T) {
+	rates := fx.NewRates([]fx.Rate{{From: "USD", To: "RUB", Rate: 90, EffectiveFrom: day(1)}}, day(2))
+	prices := trip.TripPrices{
+		Price:                  10,
+		SearchPrice:            9.5,
+		ProviderCurrency:       "RUB",
+		PricerInfo:             trip.PricerInfo{Markup: 0.5, CashbackRate: 1},
+		PassengersPriceDetails: map[string]float64{"ADT": 10},
+	}
+
+	tests := []struct {
+		name     string
+		settings fx.Settings
+		currency string
+		want     trip.TripPrices
+	}{
+		{
+			name:     "same currency",
+			currency: "RUB",
+			want: trip.TripPrices{
+				Price:                  10,
+				SearchPrice:            9.5,
+				ProviderCurrency:       "RUB",
+				PricerInfo:             trip.PricerInfo{Markup: 0.5, CashbackRate: 1},
+				PassengersPriceDetails: map[string]float64{"ADT": 10},
+				Currency:               "RUB",
+			},
+		},
+		{
+			name:     "rounded up to cents",
+			currency: "USD",
+			want: trip.TripPrices{
+				Price:                  0.12,
+				SearchPrice:            0.11,
+				ProviderCurrency:       "RUB",
+				PricerInfo:             trip.PricerInfo{Markup: 0.01, CashbackRate: 1},
+				PassengersPriceDetails: map[string]float64{"ADT": 0.12},
+				Currency:               "USD",
+				ExchangeRate:           1.0 / 90,
+			},
+		},
+		{
+			name:     "margin and whole units",
+			settings: fx.Settings{MarginPercent: 80, RoundingStep: 1},
+			currency: "USD",
+			want: trip.TripPrices{
+				Price:                  1,
+				SearchPrice:            1,
+				ProviderCurrency:       "RUB",
+				PricerInfo:             trip.PricerInfo{Markup: 1, CashbackRate: 1},
+				PassengersPriceDetails: map[string]float64{"ADT": 1},
+				Currency:               "USD",
+				ExchangeRate:           1.8 / 90,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := trip.Trip{Prices: prices}
+			c := fx.NewConverter(rates, tt.settings)
+			if err := c.Convert(&tr, tt.currency); err != nil {
+				t.Fatal(err)
+			}
+
+			// Converting again starts from the original amounts.
+			if err := c.Convert(&tr, tt.currency); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want.Currency != "RUB" {
+				original := prices
+				original.Currency = "RUB"
+				tt.want.Original = &original
+			}
+			if diff := cmp.Diff(tt.want, tr.Prices); diff != "" {
+				t.Errorf("Convert() mismatch (-want +got):\n%s", diff)
+			}
+
+			fx.Restore(&tr)
+			if tr.Prices.Price != prices.Price || tr.Prices.Original != nil {
+				t.Errorf("Restore() = %v, want original prices", tr.Prices)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_UnknownCurrency(t *testing.T) {
+	rates := fx.NewRates([]fx.Rate{{From: "USD", To: "RUB", Rate: 90, EffectiveFrom: day(1)}}, day(2))
+	tr := trip.Trip{Prices: trip.TripPrices{Price: 10}}
+
+	err := fx.NewConverter(rates, fx.Settings{}).Convert(&tr, "USD")
+	if !errors.Is(err, fx.ErrNoRate) {
+		t.Errorf("Convert() error = %v, want %v", err, fx.ErrNoRate)
+	}
+}
@@ -0,0 +1,171 @@
+package fx
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRate = errors.New("invalid exchange rate")
+	ErrNoRate      = errors.New("no exchange rate")
+)
+
+// Rate is the price of one unit of From in To, used from EffectiveFrom
+// until a later rate for the pair takes effect.
+type Rate struct {
+	ID            int64     `json:"id"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Rate          float64   `json:"rate"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+func (r *Rate) Validate() error {
+	if !IsCurrency(r.From) || !IsCurrency(r.To) {
+		return fmt.Errorf("%w: currencies must be 3-letter codes", ErrInvalidRate)
+	}
+	if r.From == r.To {
+		return fmt.Errorf("%w: same currency", ErrInvalidRate)
+	}
+	if r.Rate <= 0 {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidRate)
+	}
+	if r.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: effective date is required", ErrInvalidRate)
+	}
+	return nil
+}
+
+// IsCurrency reports whether code looks like an ISO 4217 code.
+func IsCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCSV reads rates from lines of from,to,rate,effective date (RFC 3339
+// or YYYY-MM-DD). Empty lines and lines starting with # are skipped.
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 4
+	cr.TrimLeadingSpace = true
+
+	rates := make([]Rate, 0)
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRate, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		rate, err := parseRecord(rec)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+}
+
+func parseRecord(rec []string) (Rate, error) {
+	value, err := strconv.ParseFloat(rec[2], 64)
+	if err != nil {
+		return Rate{}, fmt.Errorf("%w: %w", ErrInvalidRate, err)
+	}
+
+	effective, err := time.Parse(time.RFC3339, rec[3])
+	if err != nil {
+		if effective, err = time.Parse(time.DateOnly, rec[3]); err != nil {
+			return Rate{}, fmt.Errorf("%w: effective date %q", ErrInvalidRate, rec[3])
+		}
+	}
+
+	r := Rate{
+		From:          strings.ToUpper(rec[0]),
+		To:            strings.ToUpper(rec[1]),
+		Rate:          value,
+		EffectiveFrom: effective,
+	}
+	return r, r.Validate()
+}
+
+type pair struct {
+	from, to string
+}
+
+// Rates is a snapshot of the rates in effect at a moment.
+type Rates struct {
+	rates map[pair]float64
+}
+
+// NewRates picks the latest rate that took effect by now for every pair.
+func NewRates(rates []Rate, now time.Time) *Rates {
+	rates = slices.Clone(rates)
+	slices.SortStableFunc(rates, func(a, b Rate) int {
+		return a.EffectiveFrom.Compare(b.EffectiveFrom)
+	})
+
+	res := &Rates{rates: make(map[pair]float64)}
+	for _, r := range rates {
+		if !r.EffectiveFrom.After(now) {
+			res.rates[pair{r.From, r.To}] = r.Rate
+		}
+	}
+	return res
+}
+
+// Get returns the rate converting from into to. Missing pairs are derived
+// from the opposite rate or crossed through a currency both have rates with.
+func (rs *Rates) Get(from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if r, ok := rs.direct(from, to); ok {
+		return r, nil
+	}
+
+	// Cross through the currency giving the same result on every run.
+	crosses := make([]string, 0)
+	for p := range rs.rates {
+		crosses = append(crosses, p.from, p.to)
+	}
+	slices.SortFunc(crosses, cmp.Compare)
+	for _, via := range slices.Compact(crosses) {
+		a, okA := rs.direct(from, via)
+		b, okB := rs.direct(via, to)
+		if okA && okB {
+			return a * b, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s to %s", ErrNoRate, from, to)
+}
+
+func (rs *Rates) direct(from, to string) (float64, bool) {
+	if rs == nil {
+		return 0, false
+	}
+	if r, ok := rs.rates[pair{from, to}]; ok {
+		return r, true
+	}
+	if r, ok := rs.rates[pair{to, from}]; ok {
+		return 1 / r, true
+	}
+	return 0, false
+}
@@ -0,0 +1,90 @@
+package fx_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRates_Get(t *testing.T) {
+	rates := fx.NewRates([]fx.Rate{
+		{From: "USD", To: "RUB", Rate: 95, EffectiveFrom: day(10)},
+		{From: "USD", To: "RUB", Rate: 90, EffectiveFrom: day(1)},
+		{From: "USD", To: "RUB", Rate: 99, EffectiveFrom: day(20)},
+		{From: "EUR", To: "USD", Rate: 1.1, EffectiveFrom: day(1)},
+	}, day(15))
+
+	tests := []struct {
+		from, to string
+		want     float64
+		wantErr  error
+	}{
+		{from: "USD", to: "RUB", want: 95},
+		{from: "RUB", to: "USD", want: 1.0 / 95},
+		{from: "EUR", to: "RUB", want: 1.1 * 95},
+		{from: "RUB", to: "RUB", want: 1},
+		{from: "GBP", to: "RUB", wantErr: fx.ErrNoRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+tt.to, func(t *testing.T) {
+			got, err := rates.Get(tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []fx.Rate
+		wantErr bool
+	}{
+		{
+			name: "dates and timestamps",
+			in:   "# central bank\nusd,rub,92.5,2024-10-01\n\nEUR, RUB, 100.1, 2024-10-01T12:00:00Z\n",
+			want: []fx.Rate{
+				{From: "USD", To: "RUB", Rate: 92.5, EffectiveFrom: day(1)},
+				{From: "EUR", To: "RUB", Rate: 100.1, EffectiveFrom: day(1).Add(12 * time.Hour)},
+			},
+		},
+		{name: "negative rate", in: "USD,RUB,-1,2024-10-01", wantErr: true},
+		{name: "no date", in: "USD,RUB,92.5,", wantErr: true},
+		{name: "missing field", in: "USD,RUB,92.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fx.ParseCSV(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSV() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, fx.ErrInvalidRate) {
+					t.Errorf("ParseCSV() error = %v, want %v", err, fx.ErrInvalidRate)
+				}
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateApproxTime(0)); diff != "" {
+				t.Errorf("ParseCSV() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
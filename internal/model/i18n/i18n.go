@@ -0,0 +1,213 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/de4et/flight-booking/internal/model/airport"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+// DefaultLanguage is used for anything missing in the requested language.
+const DefaultLanguage = "en"
+
+//go:embed data/*.json
+var bundled embed.FS
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the catalog built from the bundled translations.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		c, err := Load(bundled, airport.Default())
+		if err != nil {
+			panic(fmt.Sprintf("invalid bundled translations: %s", err))
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
+
+// resources are the translations of one language. Codes are IATA codes,
+// fare families are keyed by upper-case name and errors by their English text.
+type resources struct {
+	Cities       map[string]string `json:"cities"`
+	Airports     map[string]string `json:"airports"`
+	Carriers     map[string]string `json:"carriers"`
+	Aircraft     map[string]string `json:"aircraft"`
+	FareFamilies map[string]string `json:"fareFamilies"`
+	Errors       map[string]string `json:"errors"`
+}
+
+// Catalog looks up translations, falling back to DefaultLanguage.
+type Catalog struct {
+	languages map[string]resources
+	airports  *airport.Directory
+}
+
+// Load reads one data/<language>.json file per language from fsys.
+// Names missing in every language are taken from the airport directory.
+func Load(fsys fs.FS, airports *airport.Directory) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "data/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{
+		languages: make(map[string]resources),
+		airports:  airports,
+	}
+	for _, f := range files {
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+
+		var res resources
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		c.languages[strings.TrimSuffix(path.Base(f), ".json")] = res
+	}
+
+	if _, ok := c.languages[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("no %s translations", DefaultLanguage)
+	}
+	return c, nil
+}
+
+// Languages returns the supported languages in alphabetical order.
+func (c *Catalog) Languages() []string {
+	langs := make([]string, 0, len(c.languages))
+	for l := range c.languages {
+		langs = append(langs, l)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// Normalize turns an SRO language, a language tag or an Accept-Language
+// header (RU, ru-RU, ru;q=0.9,en) into the catalog language, using its first
+// primary subtag. Unsupported languages become DefaultLanguage.
+func (c *Catalog) Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexFunc(lang, func(r rune) bool { return r < 'a' || r > 'z' }); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := c.languages[lang]; ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+func (c *Catalog) lookup(lang string, section func(resources) map[string]string, key string) (string, bool) {
+	if v, ok := section(c.languages[c.Normalize(lang)])[key]; ok {
+		return v, true
+	}
+	v, ok := section(c.languages[DefaultLanguage])[key]
+	return v, ok
+}
+
+// Airport returns the name of an airport, or "" if it is unknown.
+func (c *Catalog) Airport(lang, code string) string {
+	if v, ok := c.lookup(lang, func(r resources) map[string]string { return r.Airports }, code); ok {
+		return v
+	}
+	if l, ok := c.airports.Lookup(code); ok && l.Kind == airport.KindAirport {
+		return l.Name
+	}
+	return ""
+}
+
+// City returns the name of the city of an airport or city code.
+func (c *Catalog) City(lang, code string) string {
+	city, ok := c.airports.City(code)
+	if !ok {
+		city = code
+	}
+	if v, ok := c.lookup(lang, func(r resources) map[string]string { return r.Cities }, city); ok {
+		return v
+	}
+	if l, ok := c.airports.Lookup(city); ok && l.Kind == airport.KindCity {
+		return l.Name
+	}
+	return ""
+}
+
+func (c *Catalog) Carrier(lang, code string) string {
+	v, _ := c.lookup(lang, func(r resources) map[string]string { return r.Carriers }, code)
+	return v
+}
+
+func (c *Catalog) Aircraft(lang, code string) string {
+	v, _ := c.lookup(lang, func(r resources) map[string]string { return r.Aircraft }, code)
+	return v
+}
+
+// FareFamily returns the marketing name of a fare family by its name.
+func (c *Catalog) FareFamily(lang, name string) string {
+	v, _ := c.lookup(lang, func(r resources) map[string]string { return r.FareFamilies }, strings.ToUpper(name))
+	return v
+}
+
+// Error returns the translated message of the first error in the chain that
+// has one. Errors are matched by their own text, so wrapped sentinel errors
+// are found whatever was added around them.
+func (c *Catalog) Error(lang string, err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	if v, ok := c.lookup(lang, func(r resources) map[string]string { return r.Errors }, err.Error()); ok {
+		return v, true
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return c.Error(lang, e.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			if v, ok := c.Error(lang, err); ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Localize fills the display names of the trip in the language of its SRO.
+// Segments are copied, so trips sharing them with a cached result are not
+// changed.
+func (c *Catalog) Localize(t *trip.Trip) {
+	lang := DefaultLanguage
+	if t.SRO != nil {
+		lang = t.SRO.Metadata.Language
+	}
+
+	t.Segments = slices.Clone(t.Segments)
+	for i := range t.Segments {
+		s := &t.Segments[i]
+		c.localizePoint(lang, &s.Departure)
+		c.localizePoint(lang, &s.Arrival)
+		s.CarrierName = c.Carrier(lang, s.Carrier)
+		s.AircraftName = c.Aircraft(lang, s.Aircraft)
+	}
+
+	ff := &t.Metadata.FareFamily
+	if name := c.FareFamily(lang, ff.Name); name != "" {
+		ff.MarketingName = name
+	}
+}
+
+func (c *Catalog) localizePoint(lang string, p *trip.FlightPoint) {
+	p.AirportName = c.Airport(lang, p.Airport)
+	p.CityName = c.City(lang, p.Airport)
+}
@@ -0,0 +1,130 @@
+package i18n_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/i18n"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCatalog_Names(t *testing.T) {
+	c := i18n.Default()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "airport", got: c.Airport("RU", "SVO"), want: "Шереметьево"},
+		{name: "airport in English from directory", got: c.Airport("EN", "SVO"), want: "Sheremetyevo"},
+		{name: "unsupported language falls back to English", got: c.Airport("DE", "LED"), want: "Pulkovo"},
+		{name: "city of airport", got: c.City("RU", "SVO"), want: "Москва"},
+		{name: "city", got: c.City("ru-RU", "LED"), want: "Санкт-Петербург"},
+		{name: "city in English", got: c.City("", "LED"), want: "Saint Petersburg"},
+		{name: "unknown airport", got: c.Airport("RU", "XXX"), want: ""},
+		{name: "carrier", got: c.Carrier("RU", "SU"), want: "Аэрофлот"},
+		{name: "aircraft", got: c.Aircraft("RU", "SU9"), want: "Сухой Суперджет 100"},
+		{name: "fare family", got: c.FareFamily("RU", "Comfort"), want: "Комфорт"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalog_Normalize(t *testing.T) {
+	c := i18n.Default()
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{lang: "RU", want: "ru"},
+		{lang: "ru-RU", want: "ru"},
+		{lang: "ru;q=0.9,en;q=0.8", want: "ru"},
+		{lang: "de", want: "en"},
+		{lang: "", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if got := c.Normalize(tt.lang); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalog_Error(t *testing.T) {
+	notFound := errors.New("trip not found, search again")
+	invalid := errors.New("invalid SRO")
+
+	tests := []struct {
+		name   string
+		lang   string
+		err    error
+		want   string
+		wantOK bool
+	}{
+		{name: "sentinel", lang: "RU", err: notFound, want: "Предложение не найдено, повторите поиск", wantOK: true},
+		{name: "wrapped", lang: "RU", err: fmt.Errorf("%w: %w", invalid, errors.New("no legs")), want: "Некорректный поисковый запрос", wantOK: true},
+		{name: "wrapped with context", lang: "RU", err: fmt.Errorf("loading: %w", notFound), want: "Предложение не найдено, повторите поиск", wantOK: true},
+		{name: "English is the original text", lang: "EN", err: notFound, wantOK: false},
+		{name: "unknown error", lang: "RU", err: errors.New("boom"), wantOK: false},
+		{name: "nil", lang: "RU", err: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := i18n.Default().Error(tt.lang, tt.err)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Error() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCatalog_Localize(t *testing.T) {
+	segments := []trip.TripSegment{{
+		Carrier:   "SU",
+		Aircraft:  "320",
+		Departure: trip.FlightPoint{Airport: "SVO"},
+		Arrival:   trip.FlightPoint{Airport: "LED"},
+	}}
+	tr := trip.Trip{
+		SRO:      &sro.SRO{Metadata: sro.Metadata{Language: "RU"}},
+		Segments: segments,
+		Metadata: trip.TripMetadata{
+			FareFamily: trip.FareFamily{Name: "basic", MarketingName: "Basic fare"},
+		},
+	}
+
+	i18n.Default().Localize(&tr)
+
+	want := []trip.TripSegment{{
+		Carrier:      "SU",
+		CarrierName:  "Аэрофлот",
+		Aircraft:     "320",
+		AircraftName: "Airbus A320",
+		Departure:    trip.FlightPoint{Airport: "SVO", AirportName: "Шереметьево", CityName: "Москва"},
+		Arrival:      trip.FlightPoint{Airport: "LED", AirportName: "Пулково", CityName: "Санкт-Петербург"},
+	}}
+	if diff := cmp.Diff(want, tr.Segments); diff != "" {
+		t.Errorf("Segments mismatch (-want +got):\n%s", diff)
+	}
+	if got := tr.Metadata.FareFamily.MarketingName; got != "Базовый" {
+		t.Errorf("MarketingName = %q, want Базовый", got)
+	}
+	if segments[0].CarrierName != "" {
+		t.Errorf("Localize changed the shared segments")
+	}
+}
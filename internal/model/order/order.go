@@ -0,0 +1,137 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/passenger"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var (
+	ErrInvalidOrder      = errors.New("invalid order")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrStatusChanged     = errors.New("order status changed concurrently")
+	ErrNotFound          = errors.New("order not found")
+)
+
+type Status string
+
+const (
+	StatusCreated   Status = "created"
+	StatusBooked    Status = "booked"
+	StatusTicketed  Status = "ticketed"
+	StatusCancelled Status = "cancelled"
+	// StatusExpiring marks orders claimed by the expiry worker while their
+	// booking is cancelled at the provider.
+	StatusExpiring Status = "expiring"
+	StatusExpired  Status = "expired"
+)
+
+// transitions lists the statuses an order may move to from each status.
+// Cancelled and expired orders are final.
+var transitions = map[Status][]Status{
+	StatusCreated:  {StatusBooked, StatusCancelled, StatusExpiring},
+	StatusBooked:   {StatusTicketed, StatusCancelled, StatusExpiring},
+	StatusTicketed: {StatusCancelled},
+	StatusExpiring: {StatusExpired},
+}
+
+type Order struct {
+	ID          int64  `json:"id"`
+	Status      Status `json:"status"`
+	PartnerCode string `json:"partnerCode"`
+	Token       string `json:"token"`
+	// CanonicalToken is the token of the search log entries of the order.
+	CanonicalToken   string                `json:"-"`
+	Trip             trip.Trip             `json:"trip"`
+	Passengers       []passenger.Passenger `json:"passengers"`
+	Contacts         Contacts              `json:"contacts"`
+	Provider         string                `json:"provider"`
+	BookingReference string                `json:"bookingReference,omitempty"`
+	// IsTest marks orders of test searches, booked at sandbox providers only.
+	IsTest    bool      `json:"isTest"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Event records a status change of an order.
+type Event struct {
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"orderId"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Contacts struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// New creates an order for the selected trip. The trip is copied as is, so
+// the order keeps the fare it was booked with. Passenger names are
+// transliterated to the form providers accept.
+func New(token string, t trip.Trip, passengers []passenger.Passenger, contacts Contacts) Order {
+	passengers = slices.Clone(passengers)
+	for i := range passengers {
+		passengers[i].Normalize()
+	}
+
+	o := Order{
+		Status:     StatusCreated,
+		Token:      token,
+		Trip:       t,
+		Passengers: passengers,
+		Contacts:   contacts,
+		Provider:   t.Provider.Name,
+	}
+	if t.SRO != nil {
+		o.PartnerCode = t.SRO.ChannelToken.PartnerCode
+		o.CanonicalToken = t.SRO.CanonicalToken()
+		o.IsTest = t.SRO.Metadata.IsTest
+	}
+	return o
+}
+
+func (o *Order) Validate() error {
+	if o.Contacts.Email == "" && o.Contacts.Phone == "" {
+		return fmt.Errorf("%w: email or phone is required", ErrInvalidOrder)
+	}
+	if err := passenger.ValidateForTrip(o.Passengers, &o.Trip); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOrder, err)
+	}
+	return nil
+}
+
+func (o *Order) CanTransition(to Status) bool {
+	return slices.Contains(transitions[o.Status], to)
+}
+
+// Transition moves the order to the status if the state machine allows it.
+func (o *Order) Transition(to Status) error {
+	if !o.CanTransition(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, to)
+	}
+	o.Status = to
+	return nil
+}
+
+// IsFinal reports whether the order can't change its status anymore.
+func (o *Order) IsFinal() bool {
+	return len(transitions[o.Status]) == 0
+}
+
+// HoldUntil returns the time the booking is held by the provider until, the
+// earliest of the trip ticketing time limit and expiry. Zero if neither is
+// known.
+func (o *Order) HoldUntil() time.Time {
+	limit := o.Trip.Booking.TicketingTimeLimit
+	if expires := o.Trip.Booking.ExpiresAt; !expires.IsZero() && (limit.IsZero() || expires.Before(limit)) {
+		limit = expires
+	}
+	return limit
+}
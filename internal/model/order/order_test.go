@@ -0,0 +1,138 @@
+package order_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/passenger"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func newPassenger(t sro.PassengerType, birth passenger.Date) passenger.Passenger {
+	return passenger.Passenger{
+		Type:      t,
+		FirstName: "Иван",
+		LastName:  "Иванов",
+		BirthDate: birth,
+		Gender:    passenger.GenderMale,
+		Document:  passenger.Document{Type: passenger.DocumentPassport, Number: "1234567890", Country: "RU", ExpiresAt: passenger.NewDate(2035, 1, 1)},
+	}
+}
+
+func TestOrder_Validate(t *testing.T) {
+	tr := trip.Trip{SRO: &sro.SRO{
+		Segments:   []sro.Segment{{From: "MOW", To: "LED", Date: time.Date(2030, 10, 15, 0, 0, 0, 0, time.UTC)}},
+		Passengers: sro.Passengers{ADT: 1, CHD: 1},
+	}}
+	contacts := order.Contacts{Email: "ivan@example.com"}
+
+	adult := newPassenger(sro.PassengerTypeADT, passenger.NewDate(1990, 1, 1))
+	child := newPassenger(sro.PassengerTypeCHD, passenger.NewDate(2025, 1, 1))
+
+	noName := adult
+	noName.LastName = " "
+
+	tests := []struct {
+		name       string
+		passengers []passenger.Passenger
+		contacts   order.Contacts
+		wantErr    bool
+	}{
+		{
+			name:       "valid",
+			passengers: []passenger.Passenger{adult, child},
+			contacts:   contacts,
+		},
+		{
+			name:       "no contacts",
+			passengers: []passenger.Passenger{adult, child},
+			wantErr:    true,
+		},
+		{
+			name:       "missing name",
+			passengers: []passenger.Passenger{noName, child},
+			contacts:   contacts,
+			wantErr:    true,
+		},
+		{
+			name:       "passengers don't match search",
+			passengers: []passenger.Passenger{adult, adult},
+			contacts:   contacts,
+			wantErr:    true,
+		},
+		{
+			name:       "fewer passengers than searched",
+			passengers: []passenger.Passenger{adult},
+			contacts:   contacts,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := order.New("token", tr, tt.passengers, tt.contacts)
+			err := o.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, order.ErrInvalidOrder) {
+				t.Errorf("Validate() error = %v, want %v", err, order.ErrInvalidOrder)
+			}
+		})
+	}
+}
+
+func TestNew_CanonicalToken(t *testing.T) {
+	s, err := sro.FromToken("AKV40000OWE1000000091MOWLED20301015_usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := order.New("AKV40000OWE1000000091MOWLED20301015_usd", trip.Trip{SRO: s}, nil, order.Contacts{})
+	if o.Token != "AKV40000OWE1000000091MOWLED20301015_usd" {
+		t.Errorf("Token = %s, want the client token", o.Token)
+	}
+	if want := s.Canonical().GetToken(); o.CanonicalToken != want {
+		t.Errorf("CanonicalToken = %s, want %s", o.CanonicalToken, want)
+	}
+}
+
+func TestOrder_Transition(t *testing.T) {
+	tests := []struct {
+		from, to order.Status
+		wantErr  bool
+	}{
+		{from: order.StatusCreated, to: order.StatusBooked},
+		{from: order.StatusCreated, to: order.StatusTicketed, wantErr: true},
+		{from: order.StatusBooked, to: order.StatusTicketed},
+		{from: order.StatusBooked, to: order.StatusExpiring},
+		{from: order.StatusBooked, to: order.StatusExpired, wantErr: true},
+		{from: order.StatusExpiring, to: order.StatusExpired},
+		{from: order.StatusExpiring, to: order.StatusTicketed, wantErr: true},
+		{from: order.StatusTicketed, to: order.StatusCancelled},
+		{from: order.StatusTicketed, to: order.StatusExpired, wantErr: true},
+		{from: order.StatusExpired, to: order.StatusCancelled, wantErr: true},
+		{from: order.StatusCancelled, to: order.StatusBooked, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			o := order.Order{Status: tt.from}
+			err := o.Transition(tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transition() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+
+			want := tt.to
+			if tt.wantErr {
+				want = tt.from
+			}
+			if o.Status != want {
+				t.Errorf("Status = %s, want %s", o.Status, want)
+			}
+		})
+	}
+}
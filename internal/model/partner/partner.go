@@ -0,0 +1,110 @@
+package partner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+var (
+	ErrInvalidPartner   = errors.New("invalid partner")
+	ErrNotFound         = errors.New("partner not found")
+	ErrUnauthenticated  = errors.New("partner authentication failed")
+	ErrForbidden        = errors.New("not allowed for the partner")
+	ErrBookingForbidden = errors.New("booking is not enabled for the partner")
+)
+
+// SecretLength is the number of random bytes of generated secrets.
+const SecretLength = 32
+
+// Partner is a client of the API. It authenticates with its code and secret,
+// either sending the secret as an API key or signing requests with it, and
+// may only use tokens with its own PartnerCode.
+type Partner struct {
+	ID   int64  `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Secret is shown only when the partner is created.
+	Secret string `json:"secret,omitempty"`
+	// Sources are the allowed SourceCode values, any when empty.
+	Sources []string `json:"sources,omitempty"`
+	// Providers are the names of the providers searched for the partner,
+	// all when empty.
+	Providers []string `json:"providers,omitempty"`
+	// MaxPassengers limits passengers per search, no limit when zero.
+	MaxPassengers  int  `json:"maxPassengers,omitempty"`
+	BookingEnabled bool `json:"bookingEnabled"`
+	// Limits override the server defaults when set.
+	Limits    ratelimit.Limits `json:"limits"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+func (p *Partner) Validate() error {
+	if len(p.Code) != 4 {
+		return fmt.Errorf("%w: code must be 4 characters", ErrInvalidPartner)
+	}
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPartner)
+	}
+	for _, s := range p.Sources {
+		if len(s) != 4 {
+			return fmt.Errorf("%w: source %q must be 4 characters", ErrInvalidPartner, s)
+		}
+	}
+	if p.MaxPassengers < 0 {
+		return fmt.Errorf("%w: negative passenger limit", ErrInvalidPartner)
+	}
+	if err := p.Limits.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPartner, err)
+	}
+	return nil
+}
+
+// GenerateSecret returns a new random hex-encoded secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, SecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Authorize checks that the partner may search with the SRO.
+func (p *Partner) Authorize(s *sro.SRO) error {
+	if s.ChannelToken.PartnerCode != p.Code {
+		return fmt.Errorf("%w: partner code %s", ErrForbidden, s.ChannelToken.PartnerCode)
+	}
+	if len(p.Sources) > 0 && !slices.Contains(p.Sources, s.ChannelToken.SourceCode) {
+		return fmt.Errorf("%w: source %s", ErrForbidden, s.ChannelToken.SourceCode)
+	}
+	if p.MaxPassengers > 0 && s.Passengers.Total() > p.MaxPassengers {
+		return fmt.Errorf("%w: more than %d passengers", ErrForbidden, p.MaxPassengers)
+	}
+	return nil
+}
+
+// AllowsProvider reports whether the provider is searched for the partner.
+func (p *Partner) AllowsProvider(name string) bool {
+	return len(p.Providers) == 0 || slices.Contains(p.Providers, name)
+}
+
+type contextKey struct{}
+
+// WithContext returns a context carrying the authenticated partner.
+func WithContext(ctx context.Context, p Partner) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the authenticated partner of the request, if any.
+// Requests without one are internal and not restricted.
+func FromContext(ctx context.Context) (Partner, bool) {
+	p, ok := ctx.Value(contextKey{}).(Partner)
+	return p, ok
+}
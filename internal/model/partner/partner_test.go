@@ -0,0 +1,82 @@
+package partner_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+func TestPartner_Authorize(t *testing.T) {
+	p := partner.Partner{Code: "AKV4", Sources: []string{"0000"}, MaxPassengers: 4}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "allowed", token: "AKV40000OWE1000000091MOWLED20241015"},
+		{name: "other partner", token: "BBBB0000OWE1000000091MOWLED20241015", wantErr: partner.ErrForbidden},
+		{name: "other source", token: "AKV40001OWE1000000091MOWLED20241015", wantErr: partner.ErrForbidden},
+		{name: "too many passengers", token: "AKV40000OWE5000000091MOWLED20241015", wantErr: partner.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := sro.FromToken(tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Authorize(s); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPartner_VerifySignature(t *testing.T) {
+	p := partner.Partner{Code: "AKV4", Secret: "s3cret"}
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"cacheId":"trip_0"}`)
+	sig := partner.Sign(p.Secret, "POST", "/api/v1/orders", now.Unix(), body)
+
+	tests := []struct {
+		name      string
+		signature string
+		uri       string
+		timestamp int64
+		body      []byte
+		wantErr   error
+	}{
+		{name: "valid", signature: sig, uri: "/api/v1/orders", timestamp: now.Unix(), body: body},
+		{name: "changed body", signature: sig, uri: "/api/v1/orders", timestamp: now.Unix(), body: []byte(`{}`), wantErr: partner.ErrUnauthenticated},
+		{name: "changed uri", signature: sig, uri: "/api/v1/orders?x=1", timestamp: now.Unix(), body: body, wantErr: partner.ErrUnauthenticated},
+		{name: "replayed later", signature: partner.Sign(p.Secret, "POST", "/api/v1/orders", now.Add(-time.Hour).Unix(), body), uri: "/api/v1/orders", timestamp: now.Add(-time.Hour).Unix(), body: body, wantErr: partner.ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.VerifySignature(tt.signature, "POST", tt.uri, strconv.FormatInt(tt.timestamp, 10), tt.body, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifySignature() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPartner_VerifyKey(t *testing.T) {
+	p := partner.Partner{Code: "AKV4", Secret: "s3cret"}
+
+	if err := p.VerifyKey("s3cret"); err != nil {
+		t.Errorf("VerifyKey() error = %v, want nil", err)
+	}
+	if err := p.VerifyKey("wrong"); !errors.Is(err, partner.ErrUnauthenticated) {
+		t.Errorf("VerifyKey() error = %v, want %v", err, partner.ErrUnauthenticated)
+	}
+	if err := (&partner.Partner{Code: "AKV4"}).VerifyKey(""); !errors.Is(err, partner.ErrUnauthenticated) {
+		t.Errorf("VerifyKey() without secret error = %v, want %v", err, partner.ErrUnauthenticated)
+	}
+}
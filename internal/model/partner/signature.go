@@ -0,0 +1,50 @@
+package partner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MaxClockSkew is how far the timestamp of a signed request may be from now.
+const MaxClockSkew = 5 * time.Minute
+
+// Sign returns the hex-encoded HMAC-SHA256 of the request with the secret.
+// The signed string is the method, the request URI with the query, the Unix
+// timestamp and the body, separated by newlines.
+func Sign(secret, method, uri string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n", method, uri, timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signed request. Requests signed too long ago are
+// rejected, so captured ones can't be replayed later.
+func (p *Partner) VerifySignature(signature, method, uri, timestamp string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrUnauthenticated)
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > MaxClockSkew || d < -MaxClockSkew {
+		return fmt.Errorf("%w: timestamp out of range", ErrUnauthenticated)
+	}
+
+	want := Sign(p.Secret, method, uri, ts, body)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return fmt.Errorf("%w: signature mismatch", ErrUnauthenticated)
+	}
+	return nil
+}
+
+// VerifyKey checks the secret sent as an API key.
+func (p *Partner) VerifyKey(key string) error {
+	if p.Secret == "" || subtle.ConstantTimeCompare([]byte(p.Secret), []byte(key)) != 1 {
+		return fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package passenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date is a calendar date such as a birth date, encoded as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t}, nil
+}
+
+// After reports whether the date is after the calendar day of t in its own
+// location.
+func (d Date) After(t time.Time) bool {
+	y, m, day := t.Date()
+	return d.Time.After(time.Date(y, m, day, 0, 0, 0, 0, time.UTC))
+}
+
+// Before reports whether the date is before the calendar day of t in its
+// own location.
+func (d Date) Before(t time.Time) bool {
+	y, m, day := t.Date()
+	return d.Time.Before(time.Date(y, m, day, 0, 0, 0, 0, time.UTC))
+}
+
+// AgeOn returns full years passed from the date to the calendar day of t.
+// People born on February 29 get a year older on March 1.
+func (d Date) AgeOn(t time.Time) int {
+	y, m, day := t.Date()
+	age := y - d.Year()
+	if m < d.Month() || m == d.Month() && day < d.Day() {
+		age--
+	}
+	return age
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return fmt.Errorf("date %q: expected YYYY-MM-DD", s)
+	}
+	*d = parsed
+	return nil
+}
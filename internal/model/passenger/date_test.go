@@ -0,0 +1,60 @@
+package passenger_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/passenger"
+)
+
+func TestDate_AgeOn(t *testing.T) {
+	tests := []struct {
+		name  string
+		birth passenger.Date
+		on    time.Time
+		want  int
+	}{
+		{name: "day before birthday", birth: passenger.NewDate(2020, 5, 10), on: time.Date(2022, 5, 9, 23, 0, 0, 0, time.UTC), want: 1},
+		{name: "on birthday", birth: passenger.NewDate(2020, 5, 10), on: time.Date(2022, 5, 10, 0, 0, 0, 0, time.UTC), want: 2},
+		{name: "leap day", birth: passenger.NewDate(2020, 2, 29), on: time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC), want: 0},
+		{name: "leap day birthday on march 1", birth: passenger.NewDate(2020, 2, 29), on: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), want: 1},
+		{
+			// 01:30 in Moscow is still the previous day in UTC.
+			name:  "local departure date",
+			birth: passenger.NewDate(2020, 5, 10),
+			on:    time.Date(2022, 5, 10, 1, 30, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			want:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.birth.AgeOn(tt.on); got != tt.want {
+				t.Errorf("AgeOn() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDate_JSON(t *testing.T) {
+	var d passenger.Date
+	if err := json.Unmarshal([]byte(`"1990-01-31"`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d != passenger.NewDate(1990, 1, 31) {
+		t.Errorf("Unmarshal() = %v", d)
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"1990-01-31"` {
+		t.Errorf("Marshal() = %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`"1990-01-31T00:00:00Z"`), &d); err == nil {
+		t.Error("Unmarshal() accepted a timestamp")
+	}
+}
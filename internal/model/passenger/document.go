@@ -0,0 +1,83 @@
+package passenger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+type DocumentType string
+
+const (
+	// DocumentPassport is a passport valid for international travel.
+	DocumentPassport DocumentType = "passport"
+	// DocumentNationalPassport is a passport valid only inside the country
+	// it was issued in.
+	DocumentNationalPassport DocumentType = "national_passport"
+	// DocumentBirthCertificate is accepted for children on domestic flights.
+	DocumentBirthCertificate DocumentType = "birth_certificate"
+)
+
+type Document struct {
+	Type    DocumentType `json:"type"`
+	Number  string       `json:"number"`
+	Country string       `json:"country"`
+	// ExpiresAt is empty for documents without an expiry date.
+	ExpiresAt Date `json:"expiresAt"`
+}
+
+// normalize drops separators from the number and transliterates the Cyrillic
+// series of birth certificates.
+func (d *Document) normalize() {
+	d.Number = strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, transliterate(d.Number))
+	d.Country = strings.ToUpper(strings.TrimSpace(d.Country))
+}
+
+// Validate checks the document can be used by a passenger of the type on a
+// trip ending at end.
+func (d *Document) Validate(t sro.PassengerType, end time.Time, domestic bool) error {
+	if d.Number == "" {
+		return fmt.Errorf("%w: document number is required", ErrInvalidPassenger)
+	}
+	for _, r := range d.Number {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return fmt.Errorf("%w: unexpected %q in document number", ErrInvalidPassenger, r)
+		}
+	}
+	if len(d.Country) != 2 {
+		return fmt.Errorf("%w: document country must be a two-letter code", ErrInvalidPassenger)
+	}
+
+	switch d.Type {
+	case DocumentPassport:
+		if d.ExpiresAt.IsZero() {
+			return fmt.Errorf("%w: passport expiry date is required", ErrInvalidPassenger)
+		}
+	case DocumentNationalPassport:
+		if !domestic {
+			return fmt.Errorf("%w: %s is only valid for domestic flights", ErrInvalidPassenger, d.Type)
+		}
+	case DocumentBirthCertificate:
+		if !domestic {
+			return fmt.Errorf("%w: %s is only valid for domestic flights", ErrInvalidPassenger, d.Type)
+		}
+		if t != sro.PassengerTypeCHD && t != sro.PassengerTypeINF {
+			return fmt.Errorf("%w: %s is only valid for children", ErrInvalidPassenger, d.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown document type %q", ErrInvalidPassenger, d.Type)
+	}
+
+	if !d.ExpiresAt.IsZero() && d.ExpiresAt.Before(end) {
+		return fmt.Errorf("%w: document expires before the end of the trip", ErrInvalidPassenger)
+	}
+	return nil
+}
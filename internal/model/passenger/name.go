@@ -0,0 +1,75 @@
+package passenger
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const MaxNameLength = 50
+
+// cyrillic maps Russian letters to Latin as in ICAO Doc 9303, the rules
+// Russian passports are issued with.
+var cyrillic = map[rune]string{
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "E",
+	'Ж': "ZH", 'З': "Z", 'И': "I", 'Й': "I", 'К': "K", 'Л': "L", 'М': "M",
+	'Н': "N", 'О': "O", 'П': "P", 'Р': "R", 'С': "S", 'Т': "T", 'У': "U",
+	'Ф': "F", 'Х': "KH", 'Ц': "TS", 'Ч': "CH", 'Ш': "SH", 'Щ': "SHCH",
+	'Ъ': "IE", 'Ы': "Y", 'Ь': "", 'Э': "E", 'Ю': "IU", 'Я': "IA",
+}
+
+// NormalizeName upper-cases the name, transliterates Cyrillic letters,
+// drops apostrophes and collapses whitespace. Other characters are kept
+// for ValidateName to reject.
+func NormalizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '\'' || r == '’' {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Join(strings.Fields(transliterate(name)), " ")
+}
+
+func transliterate(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if lat, ok := cyrillic[r]; ok {
+			sb.WriteString(lat)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// ValidateName accepts normalized names: Latin letters separated by single
+// spaces or hyphens.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPassenger)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidPassenger, MaxNameLength)
+	}
+
+	prev := ' '
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+		case r == ' ' || r == '-':
+			if prev == ' ' || prev == '-' {
+				return fmt.Errorf("%w: misplaced %q in %q", ErrInvalidPassenger, r, name)
+			}
+		case unicode.IsLetter(r):
+			return fmt.Errorf("%w: %q can't be transliterated", ErrInvalidPassenger, r)
+		default:
+			return fmt.Errorf("%w: unexpected %q in name", ErrInvalidPassenger, r)
+		}
+		prev = r
+	}
+	if prev == '-' {
+		return fmt.Errorf("%w: misplaced '-' in %q", ErrInvalidPassenger, name)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package passenger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/passenger"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Ivan", want: "IVAN"},
+		{name: "Щукин", want: "SHCHUKIN"},
+		{name: "Юлия", want: "IULIIA"},
+		{name: "Пётр", want: "PETR"},
+		{name: "Ольга", want: "OLGA"},
+		{name: "Подъячев", want: "PODIEIACHEV"},
+		{name: "Хабибуллин", want: "KHABIBULLIN"},
+		{name: "  анна   мария ", want: "ANNA MARIIA"},
+		{name: "O'Brien", want: "OBRIEN"},
+		{name: "Римский-Корсаков", want: "RIMSKII-KORSAKOV"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passenger.NormalizeName(tt.name); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "IVAN"},
+		{name: "ANNA MARIIA"},
+		{name: "RIMSKII-KORSAKOV"},
+		{name: "", wantErr: true},
+		{name: "IVAN2", wantErr: true},
+		{name: "MÜLLER", wantErr: true},
+		{name: "-IVAN", wantErr: true},
+		{name: "IVAN-", wantErr: true},
+		{name: "IVAN--IVANOV", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := passenger.ValidateName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateName(%q) error = %v, wantErr = %v", tt.name, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, passenger.ErrInvalidPassenger) {
+				t.Errorf("ValidateName(%q) error = %v, want %v", tt.name, err, passenger.ErrInvalidPassenger)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package passenger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var ErrInvalidPassenger = errors.New("invalid passenger")
+
+// Ages in full years on the travel date. Children become adults at
+// DefaultAdultAge unless the fare sets Trip.Metadata.AgeThreshold.
+const (
+	InfantMaxAge    = 1
+	DefaultAdultAge = 12
+	YouthMaxAge     = 25
+	SeniorMinAge    = 60
+)
+
+type Gender string
+
+const (
+	GenderMale   Gender = "M"
+	GenderFemale Gender = "F"
+)
+
+type Passenger struct {
+	Type       sro.PassengerType `json:"type"`
+	FirstName  string            `json:"firstName"`
+	LastName   string            `json:"lastName"`
+	MiddleName string            `json:"middleName,omitempty"`
+	BirthDate  Date              `json:"birthDate"`
+	Gender     Gender            `json:"gender"`
+	Document   Document          `json:"document"`
+	// AccompaniedBy is the index of the adult an infant flies with.
+	AccompaniedBy *int `json:"accompaniedBy,omitempty"`
+}
+
+// Normalize transliterates names to Latin and upper-cases document fields,
+// the form providers accept.
+func (p *Passenger) Normalize() {
+	p.FirstName = NormalizeName(p.FirstName)
+	p.LastName = NormalizeName(p.LastName)
+	p.MiddleName = NormalizeName(p.MiddleName)
+	p.Document.normalize()
+}
+
+// Validate checks the passenger for a trip departing at departure and
+// ending at end. adultAge is the age children become adults at.
+func (p *Passenger) Validate(departure, end time.Time, adultAge int, domestic bool) error {
+	if err := ValidateName(p.FirstName); err != nil {
+		return fmt.Errorf("first name: %w", err)
+	}
+	if err := ValidateName(p.LastName); err != nil {
+		return fmt.Errorf("last name: %w", err)
+	}
+	if p.MiddleName != "" {
+		if err := ValidateName(p.MiddleName); err != nil {
+			return fmt.Errorf("middle name: %w", err)
+		}
+	}
+	if p.Gender != GenderMale && p.Gender != GenderFemale {
+		return fmt.Errorf("%w: unknown gender %q", ErrInvalidPassenger, p.Gender)
+	}
+
+	if err := p.validateAge(departure, end, adultAge); err != nil {
+		return err
+	}
+	return p.Document.Validate(p.Type, end, domestic)
+}
+
+func (p *Passenger) validateAge(departure, end time.Time, adultAge int) error {
+	if p.BirthDate.IsZero() {
+		return fmt.Errorf("%w: birth date is required", ErrInvalidPassenger)
+	}
+	if p.BirthDate.After(departure) {
+		return fmt.Errorf("%w: born after departure", ErrInvalidPassenger)
+	}
+
+	age := p.BirthDate.AgeOn(departure)
+
+	var ok bool
+	switch p.Type {
+	case sro.PassengerTypeADT:
+		ok = age >= adultAge
+	case sro.PassengerTypeCHD:
+		ok = age > InfantMaxAge && age < adultAge
+	case sro.PassengerTypeINF:
+		// Infants must stay infants until the last flight.
+		ok = p.BirthDate.AgeOn(end) <= InfantMaxAge
+	case sro.PassengerTypeYTH:
+		ok = age >= adultAge && age <= YouthMaxAge
+	case sro.PassengerTypeSRC:
+		ok = age >= SeniorMinAge
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPassenger, p.Type)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %d years old can't travel as %s", ErrInvalidPassenger, age, p.Type)
+	}
+	return nil
+}
+
+// IsAdult reports whether the passenger can accompany an infant.
+func (p *Passenger) IsAdult() bool {
+	return p.Type == sro.PassengerTypeADT || p.Type == sro.PassengerTypeYTH || p.Type == sro.PassengerTypeSRC
+}
+
+// ValidateForTrip checks every passenger against the trip, links infants to
+// their adults and matches the passenger counts the trip was searched for.
+func ValidateForTrip(passengers []Passenger, t *trip.Trip) error {
+	if len(passengers) == 0 {
+		return fmt.Errorf("%w: no passengers", ErrInvalidPassenger)
+	}
+
+	departure, end := travelDates(t)
+	adultAge := DefaultAdultAge
+	if t.Metadata.AgeThreshold > 0 {
+		adultAge = t.Metadata.AgeThreshold
+	}
+
+	for i := range passengers {
+		if err := passengers[i].Validate(departure, end, adultAge, t.IsDomestic()); err != nil {
+			return fmt.Errorf("passenger %d: %w", i, err)
+		}
+	}
+
+	if err := validateInfants(passengers); err != nil {
+		return err
+	}
+
+	if t.SRO != nil {
+		return CheckCounts(passengers, t.SRO.Passengers)
+	}
+	return nil
+}
+
+func validateInfants(passengers []Passenger) error {
+	accompanied := make(map[int]bool)
+	for i, p := range passengers {
+		if p.Type != sro.PassengerTypeINF {
+			if p.AccompaniedBy != nil {
+				return fmt.Errorf("%w: passenger %d: only infants are accompanied", ErrInvalidPassenger, i)
+			}
+			continue
+		}
+
+		if p.AccompaniedBy == nil {
+			return fmt.Errorf("%w: passenger %d: infant must be accompanied by an adult", ErrInvalidPassenger, i)
+		}
+		adult := *p.AccompaniedBy
+		if adult < 0 || adult >= len(passengers) || !passengers[adult].IsAdult() {
+			return fmt.Errorf("%w: passenger %d: passenger %d is not an adult", ErrInvalidPassenger, i, adult)
+		}
+		if accompanied[adult] {
+			return fmt.Errorf("%w: passenger %d: adult %d already has an infant", ErrInvalidPassenger, i, adult)
+		}
+		accompanied[adult] = true
+	}
+	return nil
+}
+
+// CheckCounts matches passengers by type against the searched counts.
+func CheckCounts(passengers []Passenger, want sro.Passengers) error {
+	got := make(map[sro.PassengerType]int)
+	for _, p := range passengers {
+		got[p.Type]++
+	}
+
+	for _, c := range want.Counts() {
+		if got[c.Type] != c.Count {
+			return fmt.Errorf("%w: got %d %s passengers, searched for %d", ErrInvalidPassenger, got[c.Type], c.Type, c.Count)
+		}
+		delete(got, c.Type)
+	}
+	for t, n := range got {
+		return fmt.Errorf("%w: got %d %s passengers, searched for none", ErrInvalidPassenger, n, t)
+	}
+	return nil
+}
+
+// travelDates returns the first departure and the last departure of the
+// trip, falling back to the searched dates when segment times are unknown.
+func travelDates(t *trip.Trip) (departure, end time.Time) {
+	if n := len(t.Segments); n > 0 && !t.Segments[0].Departure.Time.IsZero() {
+		departure, end = t.Segments[0].Departure.Time, t.Segments[n-1].Departure.Time
+	} else if t.SRO != nil && len(t.SRO.Segments) > 0 {
+		departure, end = t.SRO.Segments[0].Date, t.SRO.Segments[len(t.SRO.Segments)-1].Date
+	}
+
+	if end.Before(departure) {
+		end = departure
+	}
+	return departure, end
+}
@@ -0,0 +1,173 @@
+package passenger_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/passenger"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func newPassenger(t sro.PassengerType, birth passenger.Date) passenger.Passenger {
+	return passenger.Passenger{
+		Type:      t,
+		FirstName: "IVAN",
+		LastName:  "IVANOV",
+		BirthDate: birth,
+		Gender:    passenger.GenderMale,
+		Document: passenger.Document{
+			Type:      passenger.DocumentPassport,
+			Number:    "751234567",
+			Country:   "RU",
+			ExpiresAt: passenger.NewDate(2032, 1, 1),
+		},
+	}
+}
+
+func accompaniedBy(p passenger.Passenger, adult int) passenger.Passenger {
+	p.AccompaniedBy = &adult
+	return p
+}
+
+func TestValidateForTrip(t *testing.T) {
+	departure := time.Date(2030, 10, 15, 10, 0, 0, 0, time.UTC)
+	back := time.Date(2030, 10, 25, 10, 0, 0, 0, time.UTC)
+
+	newTrip := func(p sro.Passengers) *trip.Trip {
+		return &trip.Trip{
+			Segments: []trip.TripSegment{
+				{Departure: trip.FlightPoint{Airport: "SVO", Time: departure}, Arrival: trip.FlightPoint{Airport: "IST"}},
+				{Departure: trip.FlightPoint{Airport: "IST", Time: back}, Arrival: trip.FlightPoint{Airport: "SVO"}, Direction: 1},
+			},
+			SRO: &sro.SRO{Passengers: p},
+		}
+	}
+
+	adult := newPassenger(sro.PassengerTypeADT, passenger.NewDate(1990, 1, 1))
+	child := newPassenger(sro.PassengerTypeCHD, passenger.NewDate(2020, 1, 1))
+	infant := accompaniedBy(newPassenger(sro.PassengerTypeINF, passenger.NewDate(2029, 1, 1)), 0)
+
+	// Turns two between departure and the flight back.
+	growingInfant := accompaniedBy(newPassenger(sro.PassengerTypeINF, passenger.NewDate(2028, 10, 20)), 0)
+	teen := newPassenger(sro.PassengerTypeADT, passenger.NewDate(2016, 1, 1))
+	teenChild := newPassenger(sro.PassengerTypeCHD, passenger.NewDate(2016, 1, 1))
+	birthCertificate := child
+	birthCertificate.Document = passenger.Document{Type: passenger.DocumentBirthCertificate, Number: "IVMIU123456", Country: "RU"}
+	expiring := adult
+	expiring.Document.ExpiresAt = passenger.NewDate(2030, 10, 20)
+	noGender := adult
+	noGender.Gender = ""
+
+	tests := []struct {
+		name       string
+		passengers []passenger.Passenger
+		counts     sro.Passengers
+		threshold  int
+		wantErr    bool
+	}{
+		{
+			name:       "family",
+			passengers: []passenger.Passenger{adult, child, infant},
+			counts:     sro.Passengers{ADT: 1, CHD: 1, INF: 1},
+		},
+		{
+			name:       "infant turns two during the trip",
+			passengers: []passenger.Passenger{adult, growingInfant},
+			counts:     sro.Passengers{ADT: 1, INF: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "fourteen years old adult",
+			passengers: []passenger.Passenger{teen},
+			counts:     sro.Passengers{ADT: 1},
+		},
+		{
+			name:       "fourteen years old adult below fare threshold",
+			passengers: []passenger.Passenger{teen},
+			counts:     sro.Passengers{ADT: 1},
+			threshold:  16,
+			wantErr:    true,
+		},
+		{
+			name:       "fourteen years old child below fare threshold",
+			passengers: []passenger.Passenger{adult, teenChild},
+			counts:     sro.Passengers{ADT: 1, CHD: 1},
+			threshold:  16,
+		},
+		{
+			name:       "adult as child",
+			passengers: []passenger.Passenger{adult, adult},
+			counts:     sro.Passengers{ADT: 1, CHD: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "birth certificate abroad",
+			passengers: []passenger.Passenger{adult, birthCertificate},
+			counts:     sro.Passengers{ADT: 1, CHD: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "document expires during the trip",
+			passengers: []passenger.Passenger{expiring},
+			counts:     sro.Passengers{ADT: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "no gender",
+			passengers: []passenger.Passenger{noGender},
+			counts:     sro.Passengers{ADT: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "infant without adult",
+			passengers: []passenger.Passenger{adult, newPassenger(sro.PassengerTypeINF, passenger.NewDate(2029, 1, 1))},
+			counts:     sro.Passengers{ADT: 1, INF: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "infant accompanied by child",
+			passengers: []passenger.Passenger{adult, child, accompaniedBy(infant, 1)},
+			counts:     sro.Passengers{ADT: 1, CHD: 1, INF: 1},
+			wantErr:    true,
+		},
+		{
+			name:       "two infants on one adult",
+			passengers: []passenger.Passenger{adult, adult, infant, infant},
+			counts:     sro.Passengers{ADT: 2, INF: 2},
+			wantErr:    true,
+		},
+		{
+			name:       "two infants on two adults",
+			passengers: []passenger.Passenger{adult, adult, infant, accompaniedBy(infant, 1)},
+			counts:     sro.Passengers{ADT: 2, INF: 2},
+		},
+		{
+			name:       "more passengers than searched",
+			passengers: []passenger.Passenger{adult, child},
+			counts:     sro.Passengers{ADT: 1},
+			wantErr:    true,
+		},
+		{
+			name:    "no passengers",
+			counts:  sro.Passengers{ADT: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTrip(tt.counts)
+			tr.Metadata.AgeThreshold = tt.threshold
+
+			err := passenger.ValidateForTrip(tt.passengers, tr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateForTrip() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, passenger.ErrInvalidPassenger) {
+				t.Errorf("ValidateForTrip() error = %v, want %v", err, passenger.ErrInvalidPassenger)
+			}
+		})
+	}
+}
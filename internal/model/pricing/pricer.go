@@ -0,0 +1,183 @@
+package pricing
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+// Pricer is a snapshot of rules that are active for a single channel.
+type Pricer struct {
+	rules []Rule
+	rates *fx.Rates
+}
+
+func NewPricer(rules []Rule, ct sro.ChannelToken, now time.Time) *Pricer {
+	p := &Pricer{}
+	for _, r := range rules {
+		if r.IsActive(now) && r.AppliesTo(ct) {
+			p.rules = append(p.rules, r)
+		}
+	}
+
+	slices.SortStableFunc(p.rules, func(a, b Rule) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return p
+}
+
+// SetRates sets the exchange rates markup amounts are converted with. Without
+// them only trips in the markup currency can be priced by such rules.
+func (p *Pricer) SetRates(rates *fx.Rates) {
+	p.rates = rates
+}
+
+// Match returns the rule pricing the trip, nil if none matches.
+func (p *Pricer) Match(t *trip.Trip) *Rule {
+	if p == nil {
+		return nil
+	}
+	for i := range p.rules {
+		if p.rules[i].Matches(t) {
+			return &p.rules[i]
+		}
+	}
+	return nil
+}
+
+func (p *Pricer) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.rules)
+}
+
+// Apply prices a trip as the provider sent it with the matching rule. The
+// provider price is kept in SearchPrice and the price never goes below
+// MinAllowablePrice. PricerInfo records the fees and the rule used. The trip
+// is left as is if the markup amount can't be converted into its currency.
+func (p *Pricer) Apply(t *trip.Trip) error {
+	r := p.Match(t)
+	var amount float64
+	if r != nil {
+		var err error
+		if amount, err = p.markupAmount(r, t); err != nil {
+			return err
+		}
+	}
+
+	prices := &t.Prices
+	if prices.SearchPrice == 0 {
+		prices.SearchPrice = prices.Price
+	}
+	base := prices.SearchPrice
+
+	var info trip.PricerInfo
+	price := base
+	if r != nil {
+		price += base*r.MarkupPercent/100 + amount
+
+		fare := prices.PriceFare
+		if fare == 0 {
+			fare = base
+		}
+		info.RuleID = r.ID
+		info.RuleName = r.Name
+		info.Commission = round(fare * r.CommissionPercent / 100)
+		info.CashbackRate = r.CashbackRate
+	}
+
+	if price < prices.MinAllowablePrice {
+		price = prices.MinAllowablePrice
+		info.MinPriceApplied = true
+	}
+
+	prices.Price = round(price)
+	info.Markup = round(prices.Price - base)
+	if r != nil {
+		info.PartnerAffiliateFee = round(prices.Price * r.AffiliateFeePercent / 100)
+	}
+	prices.PricerInfo = info
+
+	if base > 0 {
+		prices.PassengersPriceDetails = scale(prices.PassengersPriceDetails, prices.Price/base)
+	}
+	return nil
+}
+
+// markupAmount returns the markup amount of the rule for all passengers in
+// the provider currency of the trip.
+func (p *Pricer) markupAmount(r *Rule, t *trip.Trip) (float64, error) {
+	amount := r.MarkupAmount * float64(passengers(t))
+	from, to := r.MarkupCurrency, t.Prices.ProviderCurrency
+	if amount == 0 || from == "" || from == to {
+		return amount, nil
+	}
+	if to == "" {
+		return 0, fmt.Errorf("%w: %s to the unknown trip currency", fx.ErrNoRate, from)
+	}
+	if p.rates == nil {
+		return 0, fmt.Errorf("%w: %s to %s", fx.ErrNoRate, from, to)
+	}
+
+	rate, err := p.rates.Get(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// Reset returns the trip to the provider price, the form providers expect
+// it back in.
+func Reset(t *trip.Trip) {
+	prices := &t.Prices
+	if prices.SearchPrice == 0 {
+		return
+	}
+
+	if prices.Price > 0 {
+		prices.PassengersPriceDetails = scale(prices.PassengersPriceDetails, prices.SearchPrice/prices.Price)
+	}
+	prices.Price = prices.SearchPrice
+	prices.SearchPrice = 0
+	prices.PricerInfo = trip.PricerInfo{}
+}
+
+// scale returns a copy of per-passenger prices multiplied by k, trips copied
+// from the same result share the map.
+func scale(details map[string]float64, k float64) map[string]float64 {
+	if details == nil {
+		return nil
+	}
+
+	res := make(map[string]float64, len(details))
+	for typ, v := range details {
+		res[typ] = round(v * k)
+	}
+	return res
+}
+
+func passengers(t *trip.Trip) int {
+	if t.SRO == nil {
+		return 1
+	}
+
+	var n int
+	for _, c := range t.SRO.Passengers.Counts() {
+		n += c.Count
+	}
+	return max(n, 1)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
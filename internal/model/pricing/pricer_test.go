@@ -0,0 +1,239 @@
+package pricing_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var channel = sro.ChannelToken{PartnerCode: "AKV4", SourceCode: "0000"}
+
+func testTrip() trip.Trip {
+	return trip.Trip{
+		Provider: trip.Provider{Name: "Sabre", GDS: "1S", ValidatingCarrier: "S7"},
+		Segments: []trip.TripSegment{
+			{
+				Carrier:    "S7",
+				CabinClass: trip.TravelClassE,
+				Departure:  trip.FlightPoint{Airport: "DME", Time: time.Date(2030, 10, 15, 10, 0, 0, 0, time.UTC)},
+				Arrival:    trip.FlightPoint{Airport: "LED"},
+			},
+		},
+		Prices: trip.TripPrices{
+			Price:                  1000,
+			PriceFare:              800,
+			PassengersPriceDetails: map[string]float64{"ADT": 600, "CHD": 400},
+		},
+		SRO: &sro.SRO{
+			Segments:     []sro.Segment{{From: "MOW", To: "LED"}},
+			Passengers:   sro.Passengers{ADT: 1, CHD: 1},
+			ChannelToken: channel,
+		},
+	}
+}
+
+func date(y int, m time.Month, d int) *time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestRule_Matches(t *testing.T) {
+	tests := []struct {
+		name string
+		rule pricing.Rule
+		want bool
+	}{
+		{name: "any", rule: pricing.Rule{}, want: true},
+		{name: "route by city", rule: pricing.Rule{Route: "MOWLED"}, want: true},
+		{name: "route by airports", rule: pricing.Rule{Route: "DMELED"}, want: true},
+		{name: "other route", rule: pricing.Rule{Route: "MOWAER"}, want: false},
+		{name: "carrier", rule: pricing.Rule{Carrier: "s7"}, want: true},
+		{name: "other carrier", rule: pricing.Rule{Carrier: "SU"}, want: false},
+		{name: "class", rule: pricing.Rule{Class: trip.TravelClassE}, want: true},
+		{name: "other class", rule: pricing.Rule{Class: trip.TravelClassB}, want: false},
+		{name: "provider by gds", rule: pricing.Rule{Provider: "1S"}, want: true},
+		{name: "departure in range", rule: pricing.Rule{DepartureFrom: date(2030, 10, 1), DepartureTo: date(2030, 10, 15)}, want: true},
+		{name: "departure after range", rule: pricing.Rule{DepartureTo: date(2030, 10, 14)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := testTrip()
+			if got := tt.rule.Matches(&tr); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_Matches_MultiSegment(t *testing.T) {
+	tr := testTrip()
+	tr.SRO.Type = sro.RouteTypeCX
+	tr.SRO.Segments = append(tr.SRO.Segments, sro.Segment{From: "LED", To: "AER"})
+
+	tests := []struct {
+		route string
+		want  bool
+	}{
+		{route: "MOWLED", want: true},
+		{route: "LEDAER", want: true},
+		{route: "MOWAER", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			rule := pricing.Rule{Route: tt.route}
+			if got := rule.Matches(&tr); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPricer(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rules := []pricing.Rule{
+		{ID: 1, Name: "default"},
+		{ID: 2, Name: "other partner", PartnerCode: "ZZZZ", Priority: 10},
+		{ID: 3, Name: "expired", Priority: 10, ValidTo: date(2029, 12, 31)},
+		{ID: 4, Name: "partner", PartnerCode: "AKV4", Priority: 5},
+		{ID: 5, Name: "source", PartnerCode: "AKV4", SourceCode: "0000", Priority: 5},
+		{ID: 6, Name: "other carrier", Carrier: "SU", Priority: 100},
+	}
+
+	tr := testTrip()
+	p := pricing.NewPricer(rules, channel, now)
+	if p.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", p.Len())
+	}
+	if got := p.Match(&tr); got == nil || got.ID != 4 {
+		t.Errorf("Match() = %v, want rule 4", got)
+	}
+}
+
+func TestPricer_Apply(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := fx.NewRates([]fx.Rate{{From: "USD", To: "RUB", Rate: 90, EffectiveFrom: now}}, now)
+
+	tests := []struct {
+		name     string
+		rules    []pricing.Rule
+		minPrice float64
+		currency string
+		want     trip.TripPrices
+		wantErr  error
+	}{
+		{
+			name: "no rules",
+			want: trip.TripPrices{
+				Price:                  1000,
+				SearchPrice:            1000,
+				PriceFare:              800,
+				PassengersPriceDetails: map[string]float64{"ADT": 600, "CHD": 400},
+			},
+		},
+		{
+			name: "markup and fees",
+			rules: []pricing.Rule{{
+				ID:                  7,
+				Name:                "S7",
+				Carrier:             "S7",
+				MarkupPercent:       3,
+				MarkupAmount:        10,
+				CommissionPercent:   1,
+				AffiliateFeePercent: 2,
+				CashbackRate:        1.5,
+			}},
+			want: trip.TripPrices{
+				Price:       1050,
+				SearchPrice: 1000,
+				PriceFare:   800,
+				PricerInfo: trip.PricerInfo{
+					Markup:              50,
+					Commission:          8,
+					PartnerAffiliateFee: 21,
+					CashbackRate:        1.5,
+					RuleID:              7,
+					RuleName:            "S7",
+				},
+				PassengersPriceDetails: map[string]float64{"ADT": 630, "CHD": 420},
+			},
+		},
+		{
+			name:     "discount below min allowable price",
+			rules:    []pricing.Rule{{ID: 8, Name: "sale", MarkupPercent: -10}},
+			minPrice: 950,
+			want: trip.TripPrices{
+				Price:             950,
+				SearchPrice:       1000,
+				PriceFare:         800,
+				MinAllowablePrice: 950,
+				PricerInfo: trip.PricerInfo{
+					Markup:          -50,
+					RuleID:          8,
+					RuleName:        "sale",
+					MinPriceApplied: true,
+				},
+				PassengersPriceDetails: map[string]float64{"ADT": 570, "CHD": 380},
+			},
+		},
+		{
+			name:     "markup amount converted",
+			rules:    []pricing.Rule{{ID: 9, Name: "fixed", MarkupAmount: 1, MarkupCurrency: "USD"}},
+			currency: "RUB",
+			want: trip.TripPrices{
+				Price:                  1180,
+				SearchPrice:            1000,
+				PriceFare:              800,
+				ProviderCurrency:       "RUB",
+				PricerInfo:             trip.PricerInfo{Markup: 180, RuleID: 9, RuleName: "fixed"},
+				PassengersPriceDetails: map[string]float64{"ADT": 708, "CHD": 472},
+			},
+		},
+		{
+			name:     "markup amount without rate",
+			rules:    []pricing.Rule{{ID: 10, Name: "fixed", MarkupAmount: 1, MarkupCurrency: "EUR"}},
+			currency: "RUB",
+			want: trip.TripPrices{
+				Price:                  1000,
+				PriceFare:              800,
+				ProviderCurrency:       "RUB",
+				PassengersPriceDetails: map[string]float64{"ADT": 600, "CHD": 400},
+			},
+			wantErr: fx.ErrNoRate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := testTrip()
+			tr.Prices.MinAllowablePrice = tt.minPrice
+			tr.Prices.ProviderCurrency = tt.currency
+			original := tr.Prices.PassengersPriceDetails
+
+			p := pricing.NewPricer(tt.rules, channel, now)
+			p.SetRates(rates)
+			if err := p.Apply(&tr); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, tr.Prices); diff != "" {
+				t.Errorf("Apply() mismatch (-want +got):\n%s", diff)
+			}
+			if original["ADT"] != 600 {
+				t.Error("Apply() changed the shared per-passenger prices")
+			}
+
+			pricing.Reset(&tr)
+			if diff := cmp.Diff(testTrip().Prices.PassengersPriceDetails, tr.Prices.PassengersPriceDetails); diff != "" || tr.Prices.Price != 1000 {
+				t.Errorf("Reset() = %v %v, want provider price", tr.Prices.Price, tr.Prices.PassengersPriceDetails)
+			}
+		})
+	}
+}
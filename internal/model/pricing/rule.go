@@ -0,0 +1,156 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid pricing rule")
+	ErrNotFound    = errors.New("pricing rule not found")
+)
+
+// Rule sets the fees of trips it matches. Empty conditions match anything.
+// When several rules match, the one with the highest Priority wins, the
+// oldest one on ties.
+type Rule struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+
+	PartnerCode string           `json:"partnerCode,omitempty"`
+	SourceCode  string           `json:"sourceCode,omitempty"`
+	Route       string           `json:"route,omitempty"`    // origin + destination (MOWLED)
+	Carrier     string           `json:"carrier,omitempty"`  // validating or first marketing carrier
+	Class       trip.TravelClass `json:"class,omitempty"`    // cabin class of every segment
+	Provider    string           `json:"provider,omitempty"` // provider name or GDS
+
+	// DepartureFrom and DepartureTo limit the departure dates, inclusive.
+	DepartureFrom *time.Time `json:"departureFrom,omitempty"`
+	DepartureTo   *time.Time `json:"departureTo,omitempty"`
+	// ValidFrom and ValidTo limit when the rule is used for pricing.
+	ValidFrom *time.Time `json:"validFrom,omitempty"`
+	ValidTo   *time.Time `json:"validTo,omitempty"`
+
+	// MarkupPercent of the provider price plus MarkupAmount per passenger is
+	// added to the price. Negative values give a discount. MarkupAmount is in
+	// MarkupCurrency and converted into the provider currency of the trip,
+	// rules without one add it as is.
+	MarkupPercent  float64 `json:"markupPercent"`
+	MarkupAmount   float64 `json:"markupAmount"`
+	MarkupCurrency string  `json:"markupCurrency,omitempty"`
+	// CommissionPercent of the fare is paid to us by the carrier.
+	CommissionPercent float64 `json:"commissionPercent"`
+	// AffiliateFeePercent of the final price is paid to the partner.
+	AffiliateFeePercent float64 `json:"affiliateFeePercent"`
+	// CashbackRate is the percent of the final price returned to the customer.
+	CashbackRate float64 `json:"cashbackRate"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (r *Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	if r.Route != "" && len(r.Route) != 6 {
+		return fmt.Errorf("%w: route must be 6 letters (MOWLED)", ErrInvalidRule)
+	}
+	if r.MarkupPercent <= -100 {
+		return fmt.Errorf("%w: markup must be above -100%%", ErrInvalidRule)
+	}
+	if r.MarkupAmount != 0 && !fx.IsCurrency(r.MarkupCurrency) {
+		return fmt.Errorf("%w: markup amount needs a markup currency (USD)", ErrInvalidRule)
+	}
+	if !isPercent(r.CommissionPercent) || !isPercent(r.AffiliateFeePercent) || !isPercent(r.CashbackRate) {
+		return fmt.Errorf("%w: commission, affiliate fee and cashback must be between 0 and 100%%", ErrInvalidRule)
+	}
+	if r.DepartureFrom != nil && r.DepartureTo != nil && r.DepartureTo.Before(*r.DepartureFrom) {
+		return fmt.Errorf("%w: departure range ends before it starts", ErrInvalidRule)
+	}
+	if r.ValidFrom != nil && r.ValidTo != nil && !r.ValidFrom.Before(*r.ValidTo) {
+		return fmt.Errorf("%w: validity ends before it starts", ErrInvalidRule)
+	}
+	return nil
+}
+
+func isPercent(v float64) bool {
+	return v >= 0 && v <= 100
+}
+
+func (r *Rule) IsActive(now time.Time) bool {
+	return (r.ValidFrom == nil || !now.Before(*r.ValidFrom)) && (r.ValidTo == nil || now.Before(*r.ValidTo))
+}
+
+func (r *Rule) AppliesTo(ct sro.ChannelToken) bool {
+	return (r.PartnerCode == "" || r.PartnerCode == ct.PartnerCode) &&
+		(r.SourceCode == "" || r.SourceCode == ct.SourceCode)
+}
+
+func (r *Rule) Matches(t *trip.Trip) bool {
+	if r.Route != "" && !t.FliesRoute(strings.ToUpper(r.Route[:3]), strings.ToUpper(r.Route[3:])) {
+		return false
+	}
+	if r.Carrier != "" && !strings.EqualFold(carrierOf(t), r.Carrier) {
+		return false
+	}
+	if r.Class != "" && !hasClass(t, r.Class) {
+		return false
+	}
+	if r.Provider != "" && !strings.EqualFold(t.Provider.Name, r.Provider) && !strings.EqualFold(t.Provider.GDS, r.Provider) {
+		return false
+	}
+	if r.DepartureFrom != nil || r.DepartureTo != nil {
+		return r.departsWithin(t)
+	}
+	return true
+}
+
+func (r *Rule) departsWithin(t *trip.Trip) bool {
+	var departure time.Time
+	if len(t.Segments) > 0 {
+		departure = t.Segments[0].Departure.Time
+	} else if t.SRO != nil && len(t.SRO.Segments) > 0 {
+		departure = t.SRO.Segments[0].Date
+	}
+	if departure.IsZero() {
+		return false
+	}
+
+	day := dateOf(departure)
+	return (r.DepartureFrom == nil || !day.Before(dateOf(*r.DepartureFrom))) &&
+		(r.DepartureTo == nil || !day.After(dateOf(*r.DepartureTo)))
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func carrierOf(t *trip.Trip) string {
+	if t.Provider.ValidatingCarrier != "" {
+		return t.Provider.ValidatingCarrier
+	}
+	if len(t.Segments) > 0 {
+		return t.Segments[0].Carrier
+	}
+	return ""
+}
+
+func hasClass(t *trip.Trip, class trip.TravelClass) bool {
+	if len(t.Segments) == 0 {
+		return false
+	}
+	for _, s := range t.Segments {
+		if s.CabinClass != class {
+			return false
+		}
+	}
+	return true
+}
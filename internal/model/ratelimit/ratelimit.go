@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Kind names what a limit applies to, it is used in keys and metrics.
+type Kind string
+
+const (
+	KindRequests      Kind = "requests"       // API requests
+	KindSearches      Kind = "searches"       // provider fan-outs on cache misses
+	KindDailySearches Kind = "daily_searches" // provider fan-outs per UTC day
+)
+
+// Limits of a partner. Zero values mean no limit.
+type Limits struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	SearchesPerMinute float64 `json:"searchesPerMinute,omitempty"`
+	DailySearches     int     `json:"dailySearches,omitempty"`
+}
+
+// Or returns the limits with unset values taken from defaults.
+func (l Limits) Or(defaults Limits) Limits {
+	if l.RequestsPerSecond == 0 {
+		l.RequestsPerSecond = defaults.RequestsPerSecond
+	}
+	if l.SearchesPerMinute == 0 {
+		l.SearchesPerMinute = defaults.SearchesPerMinute
+	}
+	if l.DailySearches == 0 {
+		l.DailySearches = defaults.DailySearches
+	}
+	return l
+}
+
+func (l Limits) Validate() error {
+	if l.RequestsPerSecond < 0 || l.SearchesPerMinute < 0 || l.DailySearches < 0 {
+		return errors.New("negative rate limit")
+	}
+	return nil
+}
+
+// Bucket is a token bucket refilled at Rate tokens per second up to Burst.
+type Bucket struct {
+	Rate  float64
+	Burst int
+}
+
+// PerSecond returns a bucket allowing n calls per second, a second's worth
+// of them at once.
+func PerSecond(n float64) Bucket {
+	return Bucket{Rate: n, Burst: int(math.Max(1, math.Ceil(n)))}
+}
+
+// PerMinute returns a bucket allowing n calls per minute, a minute's worth
+// of them at once.
+func PerMinute(n float64) Bucket {
+	return Bucket{Rate: n / 60, Burst: int(math.Max(1, math.Ceil(n)))}
+}
+
+// Exceeded is returned when a limit is hit. It wraps ErrLimited.
+type Exceeded struct {
+	Kind       Kind
+	RetryAfter time.Duration
+}
+
+func (e *Exceeded) Error() string {
+	return fmt.Sprintf("%s: %s, retry after %s", ErrLimited, e.Kind, e.RetryAfter)
+}
+
+func (e *Exceeded) Unwrap() error {
+	return ErrLimited
+}
+
+// RetryAfterSeconds returns the Retry-After header value, at least 1.
+func (e *Exceeded) RetryAfterSeconds() int {
+	return int(math.Max(1, math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// EndOfDay returns the start of the next UTC day, when daily quotas reset.
+func EndOfDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,62 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLimits_Or(t *testing.T) {
+	defaults := ratelimit.Limits{RequestsPerSecond: 10, SearchesPerMinute: 60, DailySearches: 1000}
+
+	got := ratelimit.Limits{SearchesPerMinute: 5}.Or(defaults)
+	want := ratelimit.Limits{RequestsPerSecond: 10, SearchesPerMinute: 5, DailySearches: 1000}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Or() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	tests := []struct {
+		name string
+		got  ratelimit.Bucket
+		want ratelimit.Bucket
+	}{
+		{name: "per second", got: ratelimit.PerSecond(20), want: ratelimit.Bucket{Rate: 20, Burst: 20}},
+		{name: "per minute", got: ratelimit.PerMinute(120), want: ratelimit.Bucket{Rate: 2, Burst: 120}},
+		{name: "less than one", got: ratelimit.PerSecond(0.5), want: ratelimit.Bucket{Rate: 0.5, Burst: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.got); diff != "" {
+				t.Errorf("bucket mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExceeded(t *testing.T) {
+	err := fmt.Errorf("searching: %w", &ratelimit.Exceeded{Kind: ratelimit.KindSearches, RetryAfter: 1500 * time.Millisecond})
+
+	if !errors.Is(err, ratelimit.ErrLimited) {
+		t.Errorf("errors.Is(%v, ErrLimited) = false, want true", err)
+	}
+	var exceeded *ratelimit.Exceeded
+	if !errors.As(err, &exceeded) || exceeded.RetryAfterSeconds() != 2 {
+		t.Errorf("RetryAfterSeconds() of %v, want 2", err)
+	}
+}
+
+func TestEndOfDay(t *testing.T) {
+	now := time.Date(2024, 10, 15, 23, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+	want := time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if got := ratelimit.EndOfDay(now); !got.Equal(want) {
+		t.Errorf("EndOfDay() = %s, want %s", got, want)
+	}
+}
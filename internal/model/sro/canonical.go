@@ -0,0 +1,65 @@
+package sro
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Canonical returns a copy of the SRO in normal form, so that equivalent
+// searches produce the same token: codes are upper-cased, filter lists are
+// sorted and deduplicated, empty filters are dropped and MaxStops is zeroed
+// for direct-only searches.
+func (sro *SRO) Canonical() *SRO {
+	c := *sro
+
+	c.Segments = make([]Segment, 0, len(sro.Segments))
+	for _, seg := range sro.Segments {
+		y, m, d := seg.Date.Date()
+		c.Segments = append(c.Segments, Segment{
+			From: strings.ToUpper(seg.From),
+			To:   strings.ToUpper(seg.To),
+			Date: time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	c.Filters.Carriers, c.Filters.CarriersType = canonicalList(sro.Filters.Carriers, sro.Filters.CarriersType)
+	c.Filters.GDSList, c.Filters.GDSListType = canonicalList(sro.Filters.GDSList, sro.Filters.GDSListType)
+
+	if c.Filters.IsDirectOnly {
+		c.Filters.MaxStops = 0
+	}
+
+	c.Metadata.Currency = strings.ToUpper(sro.Metadata.Currency)
+	c.Metadata.Language = strings.ToUpper(sro.Metadata.Language)
+
+	return &c
+}
+
+// CanonicalToken returns the token of the canonical form of the SRO.
+func (sro *SRO) CanonicalToken() string {
+	return sro.Canonical().GetToken()
+}
+
+func canonicalList(list []string, listType ListType) ([]string, ListType) {
+	res := make([]string, 0, len(list))
+	for _, v := range list {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, ""
+	}
+
+	slices.Sort(res)
+	res = slices.Compact(res)
+
+	// Tokens encode everything that isn't an include list as exclude.
+	if listType != ListTypeInclude {
+		listType = ListTypeExclude
+	}
+	return res, listType
+}
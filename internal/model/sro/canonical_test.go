@@ -0,0 +1,74 @@
+package sro_test
+
+import (
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+func TestSRO_CanonicalToken(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{
+			name: "carriers order",
+			a:    "AKV40000OWE1000000091MOWLED20241015_I_SU.S7",
+			b:    "AKV40000OWE1000000091MOWLED20241015_I_S7.SU",
+		},
+		{
+			name: "duplicate carriers",
+			a:    "AKV40000OWE1000000091MOWLED20241015_E_S7.S7.SU",
+			b:    "AKV40000OWE1000000091MOWLED20241015_E_SU.S7",
+		},
+		{
+			name: "empty exclude list",
+			a:    "AKV40000OWE1000000091MOWLED20241015_E_",
+			b:    "AKV40000OWE1000000091MOWLED20241015",
+		},
+		{
+			name: "gds order",
+			a:    "AKV40000OWE1000000091MOWLED20241015_GI_2.1_RUB",
+			b:    "AKV40000OWE1000000091MOWLED20241015_GI_1.2_RUB",
+		},
+		{
+			name: "max stops of direct only search",
+			a:    "AKV40000OWE1000001091MOWLED20241015",
+			b:    "AKV40000OWE1000001031MOWLED20241015",
+		},
+		{
+			name: "v1 and v2",
+			a:    "AKV40000OWE1000000091MOWLED20241015_I_SU.S7",
+			b:    "v2_AKV4_0000_OW_E_MOWLED20241015_pax-a1_flags-i_stops-9_ci-S7.SU",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := sro.FromToken(tt.a)
+			if err != nil {
+				t.Fatalf("FromToken(%s) error = %v", tt.a, err)
+			}
+			b, err := sro.FromToken(tt.b)
+			if err != nil {
+				t.Fatalf("FromToken(%s) error = %v", tt.b, err)
+			}
+
+			if a.CanonicalToken() != b.CanonicalToken() {
+				t.Errorf("CanonicalToken() differ: %s != %s", a.CanonicalToken(), b.CanonicalToken())
+			}
+		})
+	}
+}
+
+func TestSRO_Canonical_DoesNotMutate(t *testing.T) {
+	s, err := sro.FromToken("AKV40000OWE1000000091MOWLED20241015_I_SU.S7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = s.Canonical()
+	if s.Filters.Carriers[0] != "SU" {
+		t.Errorf("Canonical() mutated the receiver: %v", s.Filters.Carriers)
+	}
+}
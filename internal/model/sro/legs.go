@@ -0,0 +1,59 @@
+package sro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxLegs is the maximum number of legs in a CX search.
+const MaxLegs = 6
+
+var ErrInvalidLegs = errors.New("invalid legs")
+
+// ValidateLegs checks that the segments make sense for the route type: OW
+// has one leg, RT has two legs going back and forth, CX has from 2 to MaxLegs
+// legs. Every leg must connect different places and legs must be ordered by
+// date.
+func (sro *SRO) ValidateLegs() error {
+	n := len(sro.Segments)
+	switch {
+	case sro.IsOW() && n != 1:
+		return fmt.Errorf("%w: OW search must have 1 leg, got %d", ErrInvalidLegs, n)
+	case sro.IsRT() && n != 2:
+		return fmt.Errorf("%w: RT search must have 2 legs, got %d", ErrInvalidLegs, n)
+	case sro.IsCX() && (n < 2 || n > MaxLegs):
+		return fmt.Errorf("%w: CX search must have from 2 to %d legs, got %d", ErrInvalidLegs, MaxLegs, n)
+	}
+
+	for i, seg := range sro.Segments {
+		if strings.EqualFold(seg.From, seg.To) {
+			return fmt.Errorf("%w: leg %d starts and ends in %s", ErrInvalidLegs, i, seg.From)
+		}
+		if i > 0 && seg.Date.Before(sro.Segments[i-1].Date) {
+			return fmt.Errorf("%w: leg %d departs before leg %d", ErrInvalidLegs, i, i-1)
+		}
+	}
+
+	if sro.IsRT() {
+		out, in := sro.Segments[0], sro.Segments[1]
+		if !strings.EqualFold(out.From, in.To) || !strings.EqualFold(out.To, in.From) {
+			return fmt.Errorf("%w: RT return %s-%s doesn't match %s-%s", ErrInvalidLegs, in.From, in.To, out.From, out.To)
+		}
+	}
+
+	return nil
+}
+
+// Legs splits the SRO into one OW search per segment, keeping passengers,
+// class, filters and metadata.
+func (sro *SRO) Legs() []SRO {
+	legs := make([]SRO, 0, len(sro.Segments))
+	for _, seg := range sro.Segments {
+		leg := *sro
+		leg.Type = RouteTypeOW
+		leg.Segments = []Segment{seg}
+		legs = append(legs, leg)
+	}
+	return legs
+}
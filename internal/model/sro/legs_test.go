@@ -0,0 +1,99 @@
+package sro_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+func TestSRO_ValidateLegs(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "OW",
+			token: "AKV40000OWE1000000091MOWLED20241015",
+		},
+		{
+			name:  "RT",
+			token: "AKV40000RTE1000000091MOWLED20241015LEDMOW20241020",
+		},
+		{
+			name:  "CX open jaw",
+			token: "AKV40000CXE1000000091MOWLED20241015KZNMOW20241020",
+		},
+		{
+			name:  "CX same day",
+			token: "AKV40000CXE1000000091MOWLED20241015LEDKZN20241015KZNMOW20241020",
+		},
+		{
+			name:    "OW with two legs",
+			token:   "AKV40000OWE1000000091MOWLED20241015LEDMOW20241020",
+			wantErr: true,
+		},
+		{
+			name:    "RT not returning",
+			token:   "AKV40000RTE1000000091MOWLED20241015LEDKZN20241020",
+			wantErr: true,
+		},
+		{
+			name:    "CX single leg",
+			token:   "AKV40000CXE1000000091MOWLED20241015",
+			wantErr: true,
+		},
+		{
+			name:    "CX legs out of order",
+			token:   "AKV40000CXE1000000091MOWLED20241020LEDKZN20241015",
+			wantErr: true,
+		},
+		{
+			name:    "leg to itself",
+			token:   "AKV40000CXE1000000091MOWLED20241015LEDLED20241020",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := sro.FromToken(tt.token)
+			if err != nil {
+				t.Fatalf("FromToken() error = %v", err)
+			}
+
+			err = s.ValidateLegs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateLegs() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, sro.ErrInvalidLegs) {
+				t.Errorf("ValidateLegs() error = %v, want %v", err, sro.ErrInvalidLegs)
+			}
+		})
+	}
+}
+
+func TestSRO_Legs(t *testing.T) {
+	s, err := sro.FromToken("AKV40000CXE2000000091MOWLED20241015KZNMOW20241020")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legs := s.Legs()
+	want := []string{
+		"AKV40000OWE2000000091MOWLED20241015",
+		"AKV40000OWE2000000091KZNMOW20241020",
+	}
+	if len(legs) != len(want) {
+		t.Fatalf("Legs() returned %d legs, want %d", len(legs), len(want))
+	}
+	for i := range legs {
+		if got := legs[i].GetToken(); got != want[i] {
+			t.Errorf("leg %d token = %s, want %s", i, got, want[i])
+		}
+	}
+	if len(s.Segments) != 2 || s.Type != sro.RouteTypeCX {
+		t.Errorf("Legs() modified the SRO: %+v", s)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/de4et/flight-booking/internal/model/airport"
 )
 
 var ErrInvalidSROToken = fmt.Errorf("invalid SRO token format")
@@ -43,6 +45,50 @@ type Passengers struct {
 	INS bool `json:"ins"`
 }
 
+type PassengerType string
+
+const (
+	PassengerTypeADT PassengerType = "ADT"
+	PassengerTypeCHD PassengerType = "CHD"
+	PassengerTypeINF PassengerType = "INF"
+	PassengerTypeSRC PassengerType = "SRC"
+	PassengerTypeYTH PassengerType = "YTH"
+)
+
+type PassengerCount struct {
+	Type  PassengerType `json:"type"`
+	Count int           `json:"count"`
+}
+
+// Counts returns non-empty passenger groups in a stable ADT, CHD, INF, SRC, YTH order.
+func (p Passengers) Counts() []PassengerCount {
+	all := []PassengerCount{
+		{PassengerTypeADT, p.ADT},
+		{PassengerTypeCHD, p.CHD},
+		{PassengerTypeINF, p.INF},
+		{PassengerTypeSRC, p.SRC},
+		{PassengerTypeYTH, p.YTH},
+	}
+
+	counts := make([]PassengerCount, 0, len(all))
+	for _, c := range all {
+		if c.Count > 0 {
+			counts = append(counts, c)
+		}
+	}
+	return counts
+}
+
+// Total returns the number of passengers including infants.
+func (p Passengers) Total() int {
+	return p.ADT + p.CHD + p.INF + p.SRC + p.YTH
+}
+
+// Seated returns the number of passengers occupying a seat (infants don't).
+func (p Passengers) Seated() int {
+	return p.Total() - p.INF
+}
+
 type ChannelToken struct {
 	PartnerCode string `json:"partnerCode"`
 	SourceCode  string `json:"sourceCode"`
@@ -94,7 +140,48 @@ func (sro *SRO) IsCX() bool {
 	return sro.Type == RouteTypeCX
 }
 
+// Countries resolves the origin and destination countries of the segment.
+// Unknown codes resolve to an empty string.
+func (seg *Segment) Countries() (from, to string) {
+	dir := airport.Default()
+	from, _ = dir.Country(seg.From)
+	to, _ = dir.Country(seg.To)
+	return from, to
+}
+
+// IsDomestic reports whether every segment starts and ends in the same
+// country. Searches with unknown codes are treated as international.
+func (sro *SRO) IsDomestic() bool {
+	if len(sro.Segments) == 0 {
+		return false
+	}
+
+	var country string
+	for i := range sro.Segments {
+		from, to := sro.Segments[i].Countries()
+		if from == "" || from != to {
+			return false
+		}
+		if country == "" {
+			country = from
+		}
+		if country != from {
+			return false
+		}
+	}
+	return true
+}
+
+// GetToken encodes the SRO as a v1 token when it fits the positional format
+// and falls back to v2 otherwise (counts above 9, timeout).
 func (sro *SRO) GetToken() string {
+	if sro.fitsV1() {
+		return sro.getTokenV1()
+	}
+	return sro.getTokenV2()
+}
+
+func (sro *SRO) getTokenV1() string {
 	var sb strings.Builder
 
 	// **Формат SRO Token**:
@@ -169,7 +256,15 @@ func (sro *SRO) GetToken() string {
 	return sb.String()
 }
 
+// FromToken parses both v1 and v2 tokens.
 func FromToken(token string) (*SRO, error) {
+	if TokenVersion(token) == TokenV2 {
+		return fromTokenV2(token)
+	}
+	return fromTokenV1(token)
+}
+
+func fromTokenV1(token string) (*SRO, error) {
 	// min length
 	if len(token) < 34 {
 		return nil, ErrInvalidSROToken
@@ -265,25 +360,34 @@ func FromToken(token string) (*SRO, error) {
 	var filters Filters
 	var metadata Metadata
 
-	for _, part := range strings.Split(tail, "_") {
+	// Filter markers (I, E, GI, GE) are separated from their values by "_"
+	// as well, so the value is the part following the marker.
+	parts := strings.Split(tail, "_")
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
 		if part == "" {
 			continue
 		}
+
+		var value []string
+		if in(part, "I", "E", "GI", "GE") && i+1 < len(parts) {
+			value = strings.Split(parts[i+1], ".")
+			i++
+		}
+
 		switch {
-		case strings.HasPrefix(part, "I_"):
+		case part == "I":
 			filters.CarriersType = ListTypeInclude
-			filters.Carriers = strings.Split(strings.TrimPrefix(part, "I_"), ".")
-		case strings.HasPrefix(part, "E_"):
+			filters.Carriers = value
+		case part == "E":
 			filters.CarriersType = ListTypeExclude
-			filters.Carriers = strings.Split(strings.TrimPrefix(part, "E_"), ".")
-		case strings.HasPrefix(part, "G"):
-			if strings.HasPrefix(part, "GE_") {
-				filters.GDSListType = ListTypeExclude
-				filters.GDSList = strings.Split(strings.TrimPrefix(part, "GE_"), ".")
-			} else if strings.HasPrefix(part, "GI_") {
-				filters.GDSListType = ListTypeInclude
-				filters.GDSList = strings.Split(strings.TrimPrefix(part, "GI_"), ".")
-			}
+			filters.Carriers = value
+		case part == "GI":
+			filters.GDSListType = ListTypeInclude
+			filters.GDSList = value
+		case part == "GE":
+			filters.GDSListType = ListTypeExclude
+			filters.GDSList = value
 		case len(part) == 3:
 			metadata.Currency = part
 		case len(part) == 2:
@@ -369,5 +473,50 @@ func toInt(b bool) int {
 	return 0
 }
 
-// TODO - `isInnerFlight()` - определение внутреннего/международного рейса
-// - `toArray()` - экспорт параметров для передачи провайдерам
+// ToArray exports the search parameters as a flat map for providers that take
+// plain key/value requests and for logging. Segments are keyed by index
+// (segment.0.from) and empty optional parameters are omitted.
+func (sro *SRO) ToArray() map[string]string {
+	params := map[string]string{
+		"partner":     sro.ChannelToken.PartnerCode,
+		"source":      sro.ChannelToken.SourceCode,
+		"type":        string(sro.Type),
+		"class":       string(sro.Class),
+		"directOnly":  strconv.FormatBool(sro.Filters.IsDirectOnly),
+		"baggageOnly": strconv.FormatBool(sro.Filters.WithBaggageOnly),
+		"maxStops":    strconv.Itoa(sro.Filters.MaxStops),
+		"insurance":   strconv.FormatBool(sro.Passengers.INS),
+		"test":        strconv.FormatBool(sro.Metadata.IsTest),
+	}
+
+	for _, c := range sro.Passengers.Counts() {
+		params["passengers."+strings.ToLower(string(c.Type))] = strconv.Itoa(c.Count)
+	}
+
+	for i, seg := range sro.Segments {
+		prefix := fmt.Sprintf("segment.%d.", i)
+		params[prefix+"from"] = seg.From
+		params[prefix+"to"] = seg.To
+		params[prefix+"date"] = seg.Date.Format("2006-01-02")
+	}
+
+	if len(sro.Filters.Carriers) > 0 {
+		params["carriers"] = strings.Join(sro.Filters.Carriers, ",")
+		params["carriersType"] = string(sro.Filters.CarriersType)
+	}
+	if len(sro.Filters.GDSList) > 0 {
+		params["gds"] = strings.Join(sro.Filters.GDSList, ",")
+		params["gdsType"] = string(sro.Filters.GDSListType)
+	}
+	if sro.Metadata.Currency != "" {
+		params["currency"] = sro.Metadata.Currency
+	}
+	if sro.Metadata.Language != "" {
+		params["language"] = sro.Metadata.Language
+	}
+	if sro.Metadata.Timeout > 0 {
+		params["timeout"] = strconv.Itoa(sro.Metadata.Timeout)
+	}
+
+	return params
+}
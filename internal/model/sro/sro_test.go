@@ -75,6 +75,31 @@ func TestFromToken(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "Carrier and GDS filters with currency and language",
+			token: "AKV40000OWE1000000091MOWIST20241015_E_TK_GI_1.2_USD_EN",
+			want: &sro.SRO{
+				Segments: []sro.Segment{
+					{From: "MOW", To: "IST", Date: time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)},
+				},
+				Passengers: sro.Passengers{ADT: 1, INS: true},
+				Class:      sro.TravelClassE,
+				Type:       sro.RouteTypeOW,
+				ChannelToken: sro.ChannelToken{
+					PartnerCode: "AKV4",
+					SourceCode:  "0000",
+				},
+				Filters: sro.Filters{
+					MaxStops:     9,
+					Carriers:     []string{"TK"},
+					CarriersType: sro.ListTypeExclude,
+					GDSList:      []string{"1", "2"},
+					GDSListType:  sro.ListTypeInclude,
+				},
+				Metadata: sro.Metadata{Currency: "USD", Language: "EN"},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "Invalid token (too short)",
 			token:   "AKV40000OWE1",
@@ -114,6 +139,16 @@ func TestSRO_GetToken(t *testing.T) {
 			token: "AKV40000OWE1000001110MOWLED20241015",
 			want:  "AKV40000OWE1000001110MOWLED20241015",
 		},
+		{
+			name:  "with filters",
+			token: "AKV40000RTE2110000020MOWAER20241015AERMOW20241025_I_SU.S7_GE_3_RUB_RU",
+			want:  "AKV40000RTE2110000020MOWAER20241015AERMOW20241025_I_SU.S7_GE_3_RUB_RU",
+		},
+		{
+			name:  "language spelled like a filter marker",
+			token: "v2_AKV4_0000_OW_E_MOWLED20241015_pax-a1.c0.i0.s0.y0_lang-GE",
+			want:  "v2_AKV4_0000_OW_E_MOWLED20241015_pax-a1.c0.i0.s0.y0_lang-GE",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -129,6 +164,32 @@ func TestSRO_GetToken(t *testing.T) {
 	}
 }
 
+func TestSRO_IsDomestic(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{name: "domestic OW", token: "AKV40000OWE1000000091MOWLED20241015", want: true},
+		{name: "domestic RT by airports", token: "AKV40000RTE1000000091SVOOVB20241015OVBDME20241020", want: true},
+		{name: "international", token: "AKV40000OWE1000000091MOWIST20241015", want: false},
+		{name: "international return", token: "AKV40000RTE1000000091LEDAYT20241015AYTLED20241020", want: false},
+		{name: "unknown airport", token: "AKV40000OWE1000000091MOWXXX20241015", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := sro.FromToken(tt.token)
+			if err != nil {
+				t.Fatalf("FromToken() error = %v", err)
+			}
+			if got := s.IsDomestic(); got != tt.want {
+				t.Errorf("IsDomestic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func assertEqualSRO(t *testing.T, want, got *sro.SRO) {
 	t.Helper()
 
@@ -0,0 +1,251 @@
+package sro_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// v1SRO and v2SRO generate random SROs limited to the counts each token
+// version can represent. v2 counts stay within sro.MaxPassengers in total.
+type (
+	v1SRO struct{ *sro.SRO }
+	v2SRO struct{ *sro.SRO }
+)
+
+func (v1SRO) Generate(r *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(v1SRO{randomSRO(r, 9, false)})
+}
+
+func (v2SRO) Generate(r *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(v2SRO{randomSRO(r, 19, true)})
+}
+
+func randomSRO(r *rand.Rand, maxCount int, withTimeout bool) *sro.SRO {
+	types := []sro.RouteType{sro.RouteTypeOW, sro.RouteTypeRT, sro.RouteTypeCX}
+	classes := []sro.TravelClass{sro.TravelClassE, sro.TravelClassB, sro.TravelClassF, sro.TravelClassW}
+
+	s := &sro.SRO{
+		Type:  types[r.Intn(len(types))],
+		Class: classes[r.Intn(len(classes))],
+		ChannelToken: sro.ChannelToken{
+			PartnerCode: randomString(r, letters+"0123456789", 4),
+			SourceCode:  randomString(r, "0123456789", 4),
+		},
+		Passengers: sro.Passengers{
+			ADT: r.Intn(maxCount + 1),
+			CHD: r.Intn(maxCount + 1),
+			INF: r.Intn(maxCount + 1),
+			SRC: r.Intn(maxCount + 1),
+			YTH: r.Intn(maxCount + 1),
+			INS: r.Intn(2) == 1,
+		},
+		Filters: sro.Filters{
+			IsDirectOnly:    r.Intn(2) == 1,
+			WithBaggageOnly: r.Intn(2) == 1,
+			MaxStops:        r.Intn(maxCount + 1),
+		},
+		Metadata: sro.Metadata{
+			IsTest: r.Intn(2) == 1,
+		},
+	}
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for range 1 + r.Intn(4) {
+		date = date.AddDate(0, 0, r.Intn(30))
+		s.Segments = append(s.Segments, sro.Segment{
+			From: randomString(r, letters, 3),
+			To:   randomString(r, letters, 3),
+			Date: date,
+		})
+	}
+
+	if r.Intn(2) == 1 {
+		s.Filters.CarriersType = randomListType(r)
+		for range 1 + r.Intn(3) {
+			s.Filters.Carriers = append(s.Filters.Carriers, randomString(r, letters+"0123456789", 2))
+		}
+	}
+	if r.Intn(2) == 1 {
+		s.Filters.GDSListType = randomListType(r)
+		for range 1 + r.Intn(3) {
+			s.Filters.GDSList = append(s.Filters.GDSList, randomString(r, "0123456789", 1))
+		}
+	}
+	if r.Intn(2) == 1 {
+		s.Metadata.Currency = randomString(r, letters, 3)
+	}
+	if r.Intn(2) == 1 {
+		s.Metadata.Language = randomString(r, letters, 2)
+	}
+	if withTimeout && r.Intn(2) == 1 {
+		s.Metadata.Timeout = 1 + r.Intn(120)
+	}
+
+	return s
+}
+
+func randomString(r *rand.Rand, alphabet string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+func randomListType(r *rand.Rand) sro.ListType {
+	if r.Intn(2) == 1 {
+		return sro.ListTypeInclude
+	}
+	return sro.ListTypeExclude
+}
+
+func TestToken_RoundTripV1(t *testing.T) {
+	roundTrip := func(s v1SRO) bool {
+		// v1 can't tell a language from filter markers, those go to v2.
+		want := sro.TokenV1
+		if s.Metadata.Language == "GI" || s.Metadata.Language == "GE" {
+			want = sro.TokenV2
+		}
+
+		token := s.GetToken()
+		if sro.TokenVersion(token) != want {
+			t.Logf("GetToken() = %s, want version %d", token, want)
+			return false
+		}
+
+		got, err := sro.FromToken(token)
+		if err != nil {
+			t.Logf("FromToken(%s) error = %v", token, err)
+			return false
+		}
+		if diff := cmp.Diff(s.SRO, got); diff != "" {
+			t.Logf("%s: SRO mismatch (-want +got):\n%s", token, diff)
+			return false
+		}
+		return true
+	}
+
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestToken_RoundTripV2(t *testing.T) {
+	roundTrip := func(s v2SRO) bool {
+		token, err := s.GetTokenVersion(sro.TokenV2)
+		if err != nil {
+			t.Logf("GetTokenVersion() error = %v", err)
+			return false
+		}
+
+		got, err := sro.FromToken(token)
+		if err != nil {
+			t.Logf("FromToken(%s) error = %v", token, err)
+			return false
+		}
+		if diff := cmp.Diff(s.SRO, got); diff != "" {
+			t.Logf("%s: SRO mismatch (-want +got):\n%s", token, diff)
+			return false
+		}
+
+		// Tokens that don't fit v1 must be emitted as v2.
+		if sro.TokenVersion(s.GetToken()) == sro.TokenV1 {
+			if _, err := s.GetTokenVersion(sro.TokenV1); err != nil {
+				t.Logf("GetToken() = %s emitted v1 for SRO that doesn't fit it", s.GetToken())
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFromToken_V2(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    *sro.SRO
+		wantErr bool
+	}{
+		{
+			name:  "counts above 9 and sections in any order",
+			token: "v2_AKV4_0000_RT_E_MOWLED20241015.LEDMOW20241020_lang-RU_stops-12_pax-a12.c3_flags-bi_ci-SU.S7_cur-RUB",
+			want: &sro.SRO{
+				Segments: []sro.Segment{
+					{From: "MOW", To: "LED", Date: time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)},
+					{From: "LED", To: "MOW", Date: time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC)},
+				},
+				Passengers: sro.Passengers{ADT: 12, CHD: 3, INS: true},
+				Class:      sro.TravelClassE,
+				Type:       sro.RouteTypeRT,
+				ChannelToken: sro.ChannelToken{
+					PartnerCode: "AKV4",
+					SourceCode:  "0000",
+				},
+				Filters: sro.Filters{
+					MaxStops:        12,
+					WithBaggageOnly: true,
+					Carriers:        []string{"SU", "S7"},
+					CarriersType:    sro.ListTypeInclude,
+				},
+				Metadata: sro.Metadata{Currency: "RUB", Language: "RU"},
+			},
+		},
+		{
+			name:    "unknown section",
+			token:   "v2_AKV4_0000_OW_E_MOWLED20241015_foo-1",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate section",
+			token:   "v2_AKV4_0000_OW_E_MOWLED20241015_cur-RUB_cur-USD",
+			wantErr: true,
+		},
+		{
+			name:    "include and exclude carriers",
+			token:   "v2_AKV4_0000_OW_E_MOWLED20241015_ci-SU_ce-S7",
+			wantErr: true,
+		},
+		{
+			name:    "ambiguous currency",
+			token:   "v2_AKV4_0000_OW_E_MOWLED20241015_cur-RU",
+			wantErr: true,
+		},
+		{
+			name:    "no segments",
+			token:   "v2_AKV4_0000_OW_E",
+			wantErr: true,
+		},
+		{
+			name:    "too many passengers",
+			token:   "v2_AKV4_0000_OW_E_MOWLED20241015_pax-a60.c40",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sro.FromToken(tt.token)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromToken() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr {
+				assertEqualSRO(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package sro
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TokenV1 = 1
+	TokenV2 = 2
+)
+
+const tokenV2Prefix = "v2_"
+
+// MaxPassengers limits the passengers of a search, counting every type.
+const MaxPassengers = 99
+
+var (
+	segmentV2Regexp = regexp.MustCompile(`^([A-Z]{3})([A-Z]{3})(\d{8})$`)
+	codeRegexp      = regexp.MustCompile(`^[A-Z0-9]+$`)
+	alphaRegexp     = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// fitsV1 reports whether the SRO can be encoded in the positional v1 format
+// without losing data. The optional v1 sections are told apart by length
+// only, so a language spelled like a filter marker (GI, GE) or codes
+// holding separators would be read back as something else.
+func (sro *SRO) fitsV1() bool {
+	p := sro.Passengers
+	for _, v := range []int{p.ADT, p.CHD, p.INF, p.SRC, p.YTH, sro.Filters.MaxStops} {
+		if v < 0 || v > 9 {
+			return false
+		}
+	}
+	if sro.Metadata.Timeout != 0 {
+		return false
+	}
+
+	m := sro.Metadata
+	if m.Currency != "" && (len(m.Currency) != 3 || !alphaRegexp.MatchString(m.Currency)) {
+		return false
+	}
+	if m.Language != "" && (len(m.Language) != 2 || !alphaRegexp.MatchString(m.Language) || in(m.Language, "GI", "GE")) {
+		return false
+	}
+	for _, code := range slices.Concat(sro.Filters.Carriers, sro.Filters.GDSList) {
+		if !codeRegexp.MatchString(code) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTokenVersion encodes the SRO in the requested token format version.
+func (sro *SRO) GetTokenVersion(version int) (string, error) {
+	switch version {
+	case TokenV1:
+		if !sro.fitsV1() {
+			return "", fmt.Errorf("%w: SRO doesn't fit v1", ErrInvalidSROToken)
+		}
+		return sro.getTokenV1(), nil
+	case TokenV2:
+		return sro.getTokenV2(), nil
+	default:
+		return "", fmt.Errorf("%w: unknown version %d", ErrInvalidSROToken, version)
+	}
+}
+
+// TokenVersion returns the format version of a token without parsing it.
+func TokenVersion(token string) int {
+	if strings.HasPrefix(token, tokenV2Prefix) {
+		return TokenV2
+	}
+	return TokenV1
+}
+
+func (sro *SRO) getTokenV2() string {
+	// **Формат SRO Token v2**:
+	// ```
+	// v2                          - Версия формата
+	// _AKV4                       - Партнёр (4 символа)
+	// _0000                       - Источник (4 символа)
+	// _RT                         - Тип (OW/RT/CX)
+	// _E                          - Класс (E/B/F/W)
+	// _MOWLED20241015.LEDMOW20241020 - Сегменты (From+To+Date через ".")
+	// _pax-a2.c1.i1.s0.y0         - Пассажиры, количество не ограничено одной цифрой (опционально)
+	// _flags-tdbi                 - Тест, только прямые, только с багажом, страхование (опционально)
+	// _stops-12                   - Макс. пересадок (опционально)
+	// _ci-S7.FS / _ce-S7.FS       - Включить/исключить авиакомпании (опционально)
+	// _gi-1.2 / _ge-1.2           - Включить/исключить GDS (опционально)
+	// _cur-RUB                    - Валюта (опционально)
+	// _lang-RU                    - Язык (опционально)
+	// _to-30                      - Таймаут в секундах (опционально)
+	// ```
+
+	// Optional sections are keyed, so their order doesn't matter when
+	// parsing, and sections holding zero values are omitted.
+	sections := []string{
+		"v2",
+		sro.ChannelToken.PartnerCode,
+		sro.ChannelToken.SourceCode,
+		string(sro.Type),
+		string(sro.Class),
+	}
+
+	segs := make([]string, 0, len(sro.Segments))
+	for _, seg := range sro.Segments {
+		segs = append(segs, seg.From+seg.To+seg.Date.Format("20060102"))
+	}
+	sections = append(sections, strings.Join(segs, "."))
+
+	p := sro.Passengers
+	sections = append(sections, fmt.Sprintf("pax-a%d.c%d.i%d.s%d.y%d", p.ADT, p.CHD, p.INF, p.SRC, p.YTH))
+
+	var flags strings.Builder
+	for _, f := range []struct {
+		set  bool
+		name byte
+	}{
+		{sro.Metadata.IsTest, 't'},
+		{sro.Filters.IsDirectOnly, 'd'},
+		{sro.Filters.WithBaggageOnly, 'b'},
+		{sro.Passengers.INS, 'i'},
+	} {
+		if f.set {
+			flags.WriteByte(f.name)
+		}
+	}
+	if flags.Len() > 0 {
+		sections = append(sections, "flags-"+flags.String())
+	}
+
+	if sro.Filters.MaxStops != 0 {
+		sections = append(sections, "stops-"+strconv.Itoa(sro.Filters.MaxStops))
+	}
+
+	if len(sro.Filters.Carriers) > 0 {
+		key := "ce-"
+		if sro.Filters.CarriersType == ListTypeInclude {
+			key = "ci-"
+		}
+		sections = append(sections, key+strings.Join(sro.Filters.Carriers, "."))
+	}
+
+	if len(sro.Filters.GDSList) > 0 {
+		key := "ge-"
+		if sro.Filters.GDSListType == ListTypeInclude {
+			key = "gi-"
+		}
+		sections = append(sections, key+strings.Join(sro.Filters.GDSList, "."))
+	}
+
+	if sro.Metadata.Currency != "" {
+		sections = append(sections, "cur-"+sro.Metadata.Currency)
+	}
+	if sro.Metadata.Language != "" {
+		sections = append(sections, "lang-"+sro.Metadata.Language)
+	}
+	if sro.Metadata.Timeout != 0 {
+		sections = append(sections, "to-"+strconv.Itoa(sro.Metadata.Timeout))
+	}
+
+	return strings.Join(sections, "_")
+}
+
+func fromTokenV2(token string) (*SRO, error) {
+	sections := strings.Split(token, "_")
+	if len(sections) < 6 || sections[0] != "v2" {
+		return nil, ErrInvalidSROToken
+	}
+
+	if !validatePartnerCode(sections[1]) || !validateSourceCode(sections[2]) ||
+		!validateRouteType(sections[3]) || !validateClass(sections[4]) {
+		return nil, ErrInvalidSROToken
+	}
+
+	sro := &SRO{
+		ChannelToken: ChannelToken{
+			PartnerCode: sections[1],
+			SourceCode:  sections[2],
+		},
+		Type:  RouteType(sections[3]),
+		Class: TravelClass(sections[4]),
+	}
+
+	for _, s := range strings.Split(sections[5], ".") {
+		m := segmentV2Regexp.FindStringSubmatch(s)
+		if m == nil {
+			return nil, ErrInvalidSROToken
+		}
+		date, err := time.Parse("20060102", m[3])
+		if err != nil {
+			return nil, ErrInvalidSROToken
+		}
+		sro.Segments = append(sro.Segments, Segment{From: m[1], To: m[2], Date: date})
+	}
+
+	seen := make(map[string]bool)
+	for _, section := range sections[6:] {
+		key, value, ok := strings.Cut(section, "-")
+		if !ok || value == "" || seen[key] {
+			return nil, ErrInvalidSROToken
+		}
+		seen[key] = true
+
+		if err := sro.applyV2Section(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if seen["ci"] && seen["ce"] || seen["gi"] && seen["ge"] {
+		return nil, ErrInvalidSROToken
+	}
+
+	return sro, nil
+}
+
+func (sro *SRO) applyV2Section(key, value string) error {
+	switch key {
+	case "pax":
+		return sro.Passengers.applyV2Counts(value)
+	case "flags":
+		for _, f := range value {
+			switch f {
+			case 't':
+				sro.Metadata.IsTest = true
+			case 'd':
+				sro.Filters.IsDirectOnly = true
+			case 'b':
+				sro.Filters.WithBaggageOnly = true
+			case 'i':
+				sro.Passengers.INS = true
+			default:
+				return ErrInvalidSROToken
+			}
+		}
+	case "stops":
+		n, err := parseCount(value)
+		if err != nil {
+			return err
+		}
+		sro.Filters.MaxStops = n
+	case "ci", "ce":
+		list, err := parseCodeList(value)
+		if err != nil {
+			return err
+		}
+		sro.Filters.Carriers = list
+		sro.Filters.CarriersType = listTypeFromKey(key)
+	case "gi", "ge":
+		list, err := parseCodeList(value)
+		if err != nil {
+			return err
+		}
+		sro.Filters.GDSList = list
+		sro.Filters.GDSListType = listTypeFromKey(key)
+	case "cur":
+		if len(value) != 3 || !alphaRegexp.MatchString(value) {
+			return ErrInvalidSROToken
+		}
+		sro.Metadata.Currency = value
+	case "lang":
+		if len(value) != 2 || !alphaRegexp.MatchString(value) {
+			return ErrInvalidSROToken
+		}
+		sro.Metadata.Language = value
+	case "to":
+		n, err := parseCount(value)
+		if err != nil {
+			return err
+		}
+		sro.Metadata.Timeout = n
+	default:
+		return ErrInvalidSROToken
+	}
+	return nil
+}
+
+func (p *Passengers) applyV2Counts(value string) error {
+	for _, part := range strings.Split(value, ".") {
+		if len(part) < 2 {
+			return ErrInvalidSROToken
+		}
+		n, err := parseCount(part[1:])
+		if err != nil {
+			return err
+		}
+
+		switch part[0] {
+		case 'a':
+			p.ADT = n
+		case 'c':
+			p.CHD = n
+		case 'i':
+			p.INF = n
+		case 's':
+			p.SRC = n
+		case 'y':
+			p.YTH = n
+		default:
+			return ErrInvalidSROToken
+		}
+	}
+	if p.Total() > MaxPassengers {
+		return fmt.Errorf("%w: more than %d passengers", ErrInvalidSROToken, MaxPassengers)
+	}
+	return nil
+}
+
+func listTypeFromKey(key string) ListType {
+	if strings.HasSuffix(key, "i") {
+		return ListTypeInclude
+	}
+	return ListTypeExclude
+}
+
+func parseCodeList(value string) ([]string, error) {
+	list := strings.Split(value, ".")
+	for _, c := range list {
+		if !codeRegexp.MatchString(c) {
+			return nil, ErrInvalidSROToken
+		}
+	}
+	return list, nil
+}
+
+func parseCount(s string) (int, error) {
+	if s == "" || len(s) > 4 {
+		return 0, ErrInvalidSROToken
+	}
+	for i := range s {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, ErrInvalidSROToken
+		}
+	}
+	return atoi(s), nil
+}
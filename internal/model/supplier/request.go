@@ -0,0 +1,116 @@
+package supplier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+var ErrUnsupportedRequest = errors.New("request is not supported by provider")
+
+// GDS names the request format of a supplier, every one has its mapper.
+type GDS string
+
+const (
+	GDSAmadeus    GDS = "amadeus"
+	GDSSabre      GDS = "sabre"
+	GDSTravelport GDS = "travelport"
+)
+
+// IsKnown reports whether the GDS has a mapper.
+func (g GDS) IsKnown() bool {
+	switch g {
+	case GDSAmadeus, GDSSabre, GDSTravelport:
+		return true
+	}
+	return false
+}
+
+type Cabin string
+
+const (
+	CabinEconomy        Cabin = "economy"
+	CabinPremiumEconomy Cabin = "premium_economy"
+	CabinBusiness       Cabin = "business"
+	CabinFirst          Cabin = "first"
+)
+
+var cabins = map[sro.TravelClass]Cabin{
+	sro.TravelClassE: CabinEconomy,
+	sro.TravelClassW: CabinPremiumEconomy,
+	sro.TravelClassB: CabinBusiness,
+	sro.TravelClassF: CabinFirst,
+}
+
+type Leg struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	Date        string `json:"date"`
+	Direction   int    `json:"direction"`
+}
+
+type CarrierFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Request is the provider-neutral form of an SRO. Provider mappers translate
+// it into the supplier's own vocabulary.
+type Request struct {
+	RouteType   sro.RouteType        `json:"routeType"`
+	Legs        []Leg                `json:"legs"`
+	Passengers  []sro.PassengerCount `json:"passengers"`
+	Cabin       Cabin                `json:"cabin"`
+	Carriers    CarrierFilter        `json:"carriers"`
+	MaxStops    int                  `json:"maxStops"`
+	DirectOnly  bool                 `json:"directOnly"`
+	BaggageOnly bool                 `json:"baggageOnly"`
+	Currency    string               `json:"currency,omitempty"`
+	Language    string               `json:"language,omitempty"`
+	IsTest      bool                 `json:"isTest"`
+}
+
+func FromSRO(s sro.SRO) (Request, error) {
+	cabin, ok := cabins[s.Class]
+	if !ok {
+		return Request{}, fmt.Errorf("%w: class %q", ErrUnsupportedRequest, s.Class)
+	}
+	if len(s.Segments) == 0 {
+		return Request{}, fmt.Errorf("%w: no segments", ErrUnsupportedRequest)
+	}
+
+	r := Request{
+		RouteType:   s.Type,
+		Passengers:  s.Passengers.Counts(),
+		Cabin:       cabin,
+		MaxStops:    s.Filters.MaxStops,
+		DirectOnly:  s.Filters.IsDirectOnly,
+		BaggageOnly: s.Filters.WithBaggageOnly,
+		Currency:    s.Metadata.Currency,
+		Language:    s.Metadata.Language,
+		IsTest:      s.Metadata.IsTest,
+	}
+	if r.DirectOnly {
+		r.MaxStops = 0
+	}
+
+	for i, seg := range s.Segments {
+		r.Legs = append(r.Legs, Leg{
+			Origin:      seg.From,
+			Destination: seg.To,
+			Date:        seg.Date.Format("2006-01-02"),
+			Direction:   i,
+		})
+	}
+
+	if len(s.Filters.Carriers) > 0 {
+		if s.Filters.CarriersType == sro.ListTypeInclude {
+			r.Carriers.Include = s.Filters.Carriers
+		} else {
+			r.Carriers.Exclude = s.Filters.Carriers
+		}
+	}
+
+	return r, nil
+}
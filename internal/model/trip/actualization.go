@@ -0,0 +1,26 @@
+package trip
+
+import "errors"
+
+// ErrUnavailable is returned by providers when the fare can't be sold anymore.
+var ErrUnavailable = errors.New("trip is no longer available")
+
+// Actualization is the result of re-pricing a found trip before booking.
+type Actualization struct {
+	Trip         Trip    `json:"trip"`
+	Available    bool    `json:"available"`
+	OldPrice     float64 `json:"oldPrice"`
+	PriceDelta   float64 `json:"priceDelta"`
+	RulesChanged bool    `json:"rulesChanged"`
+}
+
+// NewActualization compares the trip as it was found with the re-priced one.
+func NewActualization(found, actual Trip) Actualization {
+	return Actualization{
+		Trip:         actual,
+		Available:    true,
+		OldPrice:     found.GetPrice(),
+		PriceDelta:   actual.GetPrice() - found.GetPrice(),
+		RulesChanged: found.Rules != actual.Rules,
+	}
+}
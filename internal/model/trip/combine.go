@@ -0,0 +1,270 @@
+package trip
+
+import (
+	"container/heap"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Combine builds a single itinerary out of separately priced legs, e.g. the
+// per-leg results of a CX search. Segments of every leg get Direction set to
+// the leg index, prices are summed and the result is marked as a virtual trip.
+func Combine(parts []Trip) Trip {
+	res := combine(parts)
+	for i, p := range parts {
+		for _, s := range p.Segments {
+			s.Direction = i
+			res.Segments = append(res.Segments, s)
+		}
+	}
+
+	// Route duration covers the forward direction only.
+	res.Metadata.RouteDuration = parts[0].Metadata.RouteDuration
+	return res
+}
+
+// Connect builds a self-transfer itinerary out of trips flown one after
+// another in the same direction. The passenger changes tickets between the
+// parts, so the result is also marked as a virtual interline.
+func Connect(parts []Trip) Trip {
+	res := combine(parts)
+	for i, p := range parts {
+		segs := slices.Clone(p.Segments)
+		if n := len(segs); n > 0 && i+1 < len(parts) && len(parts[i+1].Segments) > 0 {
+			arrival, departure := segs[n-1].Arrival.Time, parts[i+1].Segments[0].Departure.Time
+			if !arrival.IsZero() && !departure.IsZero() {
+				segs[n-1].StopTimeMinutes = minutesBetween(arrival, departure)
+			}
+		}
+		res.Segments = append(res.Segments, segs...)
+	}
+
+	res.Metadata.IsVirtualInterline = true
+	res.Metadata.NumTransfers += len(parts) - 1
+	if n := len(res.Segments); n > 0 {
+		from, to := res.Segments[0].Departure.Time, res.Segments[n-1].Arrival.Time
+		if !from.IsZero() && !to.IsZero() {
+			res.Metadata.RouteDuration = minutesBetween(from, to)
+		}
+	}
+	return res
+}
+
+func combine(parts []Trip) Trip {
+	comboIDs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		comboIDs = append(comboIDs, p.CacheID)
+	}
+	comboID := strings.Join(comboIDs, "+")
+
+	res := Trip{
+		CacheID:  "vtrip_" + comboID,
+		Provider: parts[0].Provider,
+		SRO:      parts[0].SRO,
+		Rules: FareRules{
+			IsRefund:       true,
+			IsExchangeable: true,
+		},
+		Metadata: TripMetadata{
+			IsVtrip:      true,
+			VtripComboID: comboID,
+			HasBaggage:   true,
+			HasLuggage:   true,
+			FlightType:   FlightTypeDomestic,
+			TariffType:   parts[0].Metadata.TariffType,
+		},
+		Booking: TripBooking{
+			BookingWithPartialDataAllowed: true,
+			BookingActualizationAllowed:   true,
+		},
+		Prices: TripPrices{
+			ProviderCurrency:       parts[0].Prices.ProviderCurrency,
+			PassengersPriceDetails: make(map[string]float64),
+		},
+	}
+
+	for _, p := range parts {
+		res.Prices.add(p.Prices)
+
+		res.Rules.IsRefund = res.Rules.IsRefund && p.Rules.IsRefund
+		res.Rules.IsExchangeable = res.Rules.IsExchangeable && p.Rules.IsExchangeable
+		res.Rules.RefundAmount += p.Rules.RefundAmount
+		res.Rules.ExchangeFee += p.Rules.ExchangeFee
+		res.Rules.Penalty += p.Rules.Penalty
+
+		res.Metadata.NumTransfers += p.Metadata.NumTransfers
+		res.Metadata.HasBaggage = res.Metadata.HasBaggage && p.Metadata.HasBaggage
+		res.Metadata.HasLuggage = res.Metadata.HasLuggage && p.Metadata.HasLuggage
+		res.Metadata.AgeThreshold = max(res.Metadata.AgeThreshold, p.Metadata.AgeThreshold)
+		if !p.IsDomestic() {
+			res.Metadata.FlightType = FlightTypeInternational
+		}
+
+		res.Booking.ExpiresAt = earliest(res.Booking.ExpiresAt, p.Booking.ExpiresAt)
+		res.Booking.TicketingTimeLimit = earliest(res.Booking.TicketingTimeLimit, p.Booking.TicketingTimeLimit)
+		res.Booking.ProviderRecommendationLimit = earliest(res.Booking.ProviderRecommendationLimit, p.Booking.ProviderRecommendationLimit)
+		res.Booking.CountOfBlanks += p.Booking.CountOfBlanks
+		res.Booking.BookingWithPartialDataAllowed = res.Booking.BookingWithPartialDataAllowed && p.Booking.BookingWithPartialDataAllowed
+		res.Booking.BookingActualizationAllowed = res.Booking.BookingActualizationAllowed && p.Booking.BookingActualizationAllowed
+	}
+
+	return res
+}
+
+func (p *TripPrices) add(o TripPrices) {
+	p.Price += o.Price
+	p.SearchPrice += o.SearchPrice
+	p.PriceFare += o.PriceFare
+	p.ProviderServiceFee += o.ProviderServiceFee
+	p.ProviderTaxesAmount += o.ProviderTaxesAmount
+	p.MinAllowablePrice += o.MinAllowablePrice
+	p.TkpTax += o.TkpTax
+	p.SpecTax += o.SpecTax
+	p.BagsPrice += o.BagsPrice
+	p.PricerInfo.Markup += o.PricerInfo.Markup
+	p.PricerInfo.Commission += o.PricerInfo.Commission
+	p.PricerInfo.PartnerAffiliateFee += o.PricerInfo.PartnerAffiliateFee
+	p.PricerInfo.CashbackRate = max(p.PricerInfo.CashbackRate, o.PricerInfo.CashbackRate)
+	for k, v := range o.PassengersPriceDetails {
+		p.PassengersPriceDetails[k] += v
+	}
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// InSequence reports whether every part departs at least gap after the
+// previous one arrives. Parts with unknown times are assumed to fit.
+func InSequence(parts []Trip, gap time.Duration) bool {
+	for i := 1; i < len(parts); i++ {
+		prev, next := parts[i-1].Segments, parts[i].Segments
+		if len(prev) == 0 || len(next) == 0 {
+			continue
+		}
+
+		arrival, departure := prev[len(prev)-1].Arrival.Time, next[0].Departure.Time
+		if arrival.IsZero() || departure.IsZero() {
+			continue
+		}
+		if departure.Sub(arrival) < gap {
+			return false
+		}
+	}
+	return true
+}
+
+// CheapestCombinations picks up to n cheapest combinations taking one trip
+// from every group, in ascending order of total price. Combinations rejected
+// by valid are skipped; valid may be nil. The walk gives up after looking
+// at maxCandidates per requested combination, so mostly invalid inputs don't
+// enumerate the whole product.
+func CheapestCombinations(groups [][]Trip, n int, valid func([]Trip) bool) [][]Trip {
+	if len(groups) == 0 || n <= 0 {
+		return nil
+	}
+
+	sorted := make([][]Trip, len(groups))
+	for i, g := range groups {
+		if len(g) == 0 {
+			return nil
+		}
+		sorted[i] = slices.Clone(g)
+		slices.SortStableFunc(sorted[i], func(a, b Trip) int {
+			return compareFloat(a.GetPrice(), b.GetPrice())
+		})
+	}
+
+	// Best-first walk over index vectors: the cheapest combination is all
+	// zeros, and every next candidate increments one index.
+	h := &comboHeap{}
+	start := make([]int, len(sorted))
+	heap.Push(h, combo{idx: start, price: comboPrice(sorted, start)})
+	seen := map[string]bool{comboKey(start): true}
+
+	var res [][]Trip
+	for visited := 0; h.Len() > 0 && len(res) < n && visited < n*maxCandidates; visited++ {
+		c := heap.Pop(h).(combo)
+
+		parts := make([]Trip, len(sorted))
+		for g, i := range c.idx {
+			parts[g] = sorted[g][i]
+		}
+		if valid == nil || valid(parts) {
+			res = append(res, parts)
+		}
+
+		for g := range c.idx {
+			if c.idx[g]+1 >= len(sorted[g]) {
+				continue
+			}
+			next := slices.Clone(c.idx)
+			next[g]++
+			if key := comboKey(next); !seen[key] {
+				seen[key] = true
+				heap.Push(h, combo{idx: next, price: comboPrice(sorted, next)})
+			}
+		}
+	}
+
+	return res
+}
+
+const maxCandidates = 20
+
+type combo struct {
+	idx   []int
+	price float64
+}
+
+type comboHeap []combo
+
+func (h comboHeap) Len() int      { return len(h) }
+func (h comboHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *comboHeap) Push(x any)   { *h = append(*h, x.(combo)) }
+
+func (h comboHeap) Less(i, j int) bool {
+	if h[i].price != h[j].price {
+		return h[i].price < h[j].price
+	}
+	// Keep ties in a stable order.
+	return slices.Compare(h[i].idx, h[j].idx) < 0
+}
+
+func (h *comboHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func comboPrice(groups [][]Trip, idx []int) float64 {
+	var price float64
+	for g, i := range idx {
+		price += groups[g][i].GetPrice()
+	}
+	return price
+}
+
+func comboKey(idx []int) string {
+	var sb strings.Builder
+	for _, i := range idx {
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteByte('.')
+	}
+	return sb.String()
+}
+
+func compareFloat(a, b float64) int {
+	if a > b {
+		return 1
+	} else if a < b {
+		return -1
+	}
+	return 0
+}
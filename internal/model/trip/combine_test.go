@@ -0,0 +1,158 @@
+package trip_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/trip"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func legTrip(id string, price float64, from, to string, dep time.Time) trip.Trip {
+	return trip.Trip{
+		CacheID: id,
+		Prices: trip.TripPrices{
+			Price:                  price,
+			PriceFare:              price * 0.8,
+			ProviderTaxesAmount:    price * 0.2,
+			PassengersPriceDetails: map[string]float64{"ADT": price},
+		},
+		Rules: trip.FareRules{IsRefund: true, IsExchangeable: true},
+		Segments: []trip.TripSegment{{
+			Departure: trip.FlightPoint{Airport: from, Time: dep},
+			Arrival:   trip.FlightPoint{Airport: to, Time: dep.Add(2 * time.Hour)},
+		}},
+		Metadata: trip.TripMetadata{HasBaggage: true, FlightType: trip.FlightTypeDomestic},
+		Booking:  trip.TripBooking{ExpiresAt: dep.Add(-24 * time.Hour)},
+	}
+}
+
+func TestCombine(t *testing.T) {
+	dep := time.Date(2024, 10, 15, 7, 0, 0, 0, time.UTC)
+
+	first := legTrip("a", 100, "SVO", "LED", dep)
+	second := legTrip("b", 250, "LED", "IST", dep.Add(48*time.Hour))
+	second.Rules.IsRefund = false
+	second.Metadata.FlightType = trip.FlightTypeInternational
+
+	got := trip.Combine([]trip.Trip{first, second})
+
+	if got.CacheID != "vtrip_a+b" || got.Metadata.VtripComboID != "a+b" || !got.Metadata.IsVtrip {
+		t.Errorf("Combine() ids = %s, %s, IsVtrip = %v", got.CacheID, got.Metadata.VtripComboID, got.Metadata.IsVtrip)
+	}
+
+	wantPrices := trip.TripPrices{
+		Price:                  350,
+		PriceFare:              280,
+		ProviderTaxesAmount:    70,
+		PassengersPriceDetails: map[string]float64{"ADT": 350},
+	}
+	if diff := cmp.Diff(wantPrices, got.Prices); diff != "" {
+		t.Errorf("Combine() prices mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(got.Segments) != 2 || got.Segments[0].Direction != 0 || got.Segments[1].Direction != 1 {
+		t.Errorf("Combine() segments = %+v, want one per leg with Direction set", got.Segments)
+	}
+	if got.Rules.IsRefund || !got.Rules.IsExchangeable {
+		t.Errorf("Combine() rules = %+v, want non-refundable exchangeable", got.Rules)
+	}
+	if got.Metadata.FlightType != trip.FlightTypeInternational {
+		t.Errorf("Combine() flight type = %s, want %s", got.Metadata.FlightType, trip.FlightTypeInternational)
+	}
+	if !got.Booking.ExpiresAt.Equal(first.Booking.ExpiresAt) {
+		t.Errorf("Combine() expires at %v, want the earliest %v", got.Booking.ExpiresAt, first.Booking.ExpiresAt)
+	}
+
+	// Parts are not modified.
+	if first.Segments[0].Direction != 0 || second.Segments[0].Direction != 0 {
+		t.Error("Combine() modified its parts")
+	}
+}
+
+func TestCheapestCombinations(t *testing.T) {
+	dep := time.Date(2024, 10, 15, 7, 0, 0, 0, time.UTC)
+
+	groups := [][]trip.Trip{
+		{
+			legTrip("a3", 300, "SVO", "LED", dep),
+			legTrip("a1", 100, "SVO", "LED", dep),
+			legTrip("a2", 200, "SVO", "LED", dep.Add(24*time.Hour)),
+		},
+		{
+			legTrip("b2", 50, "LED", "KZN", dep.Add(12*time.Hour)),
+			legTrip("b1", 10, "LED", "KZN", dep.Add(time.Hour)),
+		},
+	}
+
+	ids := func(combos [][]trip.Trip) []string {
+		var res []string
+		for _, c := range combos {
+			res = append(res, trip.Combine(c).Metadata.VtripComboID)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name  string
+		n     int
+		valid func([]trip.Trip) bool
+		want  []string
+	}{
+		{
+			name: "by price",
+			n:    4,
+			want: []string{"a1+b1", "a1+b2", "a2+b1", "a2+b2"},
+		},
+		{
+			name: "all",
+			n:    10,
+			want: []string{"a1+b1", "a1+b2", "a2+b1", "a2+b2", "a3+b1", "a3+b2"},
+		},
+		{
+			name:  "in sequence",
+			n:     10,
+			valid: func(parts []trip.Trip) bool { return trip.InSequence(parts, time.Hour) },
+			want:  []string{"a1+b2", "a3+b2"},
+		},
+		{
+			name: "none",
+			n:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(trip.CheapestCombinations(groups, tt.n, tt.valid))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("CheapestCombinations() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	dep := time.Date(2024, 10, 15, 7, 0, 0, 0, time.UTC)
+
+	first := legTrip("a", 100, "SVO", "IST", dep)
+	second := legTrip("b", 150, "IST", "LED", dep.Add(6*time.Hour))
+
+	got := trip.Connect([]trip.Trip{first, second})
+
+	if !got.Metadata.IsVtrip || !got.Metadata.IsVirtualInterline || got.Metadata.VtripComboID != "a+b" {
+		t.Errorf("Connect() metadata = %+v, want virtual interline a+b", got.Metadata)
+	}
+	if got.GetPrice() != 250 {
+		t.Errorf("Connect() price = %v, want 250", got.GetPrice())
+	}
+	if got.Metadata.NumTransfers != 1 || got.Metadata.RouteDuration != 8*60 {
+		t.Errorf("Connect() transfers = %d, duration = %d, want 1 and 480", got.Metadata.NumTransfers, got.Metadata.RouteDuration)
+	}
+	if len(got.Segments) != 2 || got.Segments[0].StopTimeMinutes != 4*60 || got.Segments[1].Direction != 0 {
+		t.Errorf("Connect() segments = %+v, want a 240 minutes stop in the same direction", got.Segments)
+	}
+	if first.Segments[0].StopTimeMinutes != 0 {
+		t.Error("Connect() modified its parts")
+	}
+}
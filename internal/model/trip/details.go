@@ -0,0 +1,89 @@
+package trip
+
+import (
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+// Details is a trip with its price, baggage and time limits broken down for
+// the trip page. Fare rules come with the trip itself.
+type Details struct {
+	Trip       Trip             `json:"trip"`
+	Passengers []PassengerPrice `json:"passengers"`
+	Segments   []SegmentDetails `json:"segments"`
+	TimeLimits TimeLimits       `json:"timeLimits"`
+}
+
+// PassengerPrice is the price of a single passenger of the type and the
+// total for all of them.
+type PassengerPrice struct {
+	Type  sro.PassengerType `json:"type"`
+	Count int               `json:"count"`
+	Price float64           `json:"price"`
+	Total float64           `json:"total"`
+}
+
+type SegmentDetails struct {
+	Flight    string      `json:"flight"`
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	Direction int         `json:"direction"`
+	Baggage   BaggageInfo `json:"baggage"`
+	Meal      string      `json:"meal"`
+}
+
+// TimeLimits are the deadlines of the fare, ExpiresInSeconds is the time left
+// until the earliest of them, zero once it has passed.
+type TimeLimits struct {
+	ExpiresAt                   time.Time `json:"expiresAt"`
+	TicketingTimeLimit          time.Time `json:"ticketingTimeLimit"`
+	ProviderRecommendationLimit time.Time `json:"providerRecommendationLimit"`
+	ExpiresInSeconds            int64     `json:"expiresInSeconds"`
+}
+
+// Details builds the trip breakdown at the given time. PassengersPriceDetails
+// is keyed by passenger type and holds the price of a single passenger.
+func (t *Trip) Details(now time.Time) Details {
+	d := Details{
+		Trip: *t,
+		TimeLimits: TimeLimits{
+			ExpiresAt:                   t.Booking.ExpiresAt,
+			TicketingTimeLimit:          t.Booking.TicketingTimeLimit,
+			ProviderRecommendationLimit: t.Booking.ProviderRecommendationLimit,
+		},
+	}
+
+	if t.SRO != nil {
+		for _, c := range t.SRO.Passengers.Counts() {
+			price, ok := t.Prices.PassengersPriceDetails[string(c.Type)]
+			if !ok {
+				continue
+			}
+			d.Passengers = append(d.Passengers, PassengerPrice{
+				Type:  c.Type,
+				Count: c.Count,
+				Price: price,
+				Total: price * float64(c.Count),
+			})
+		}
+	}
+
+	for _, s := range t.Segments {
+		d.Segments = append(d.Segments, SegmentDetails{
+			Flight:    s.Carrier + s.FlightNumber,
+			From:      s.Departure.Airport,
+			To:        s.Arrival.Airport,
+			Direction: s.Direction,
+			Baggage:   s.Baggage,
+			Meal:      s.Meal,
+		})
+	}
+
+	limit := earliest(earliest(t.Booking.ExpiresAt, t.Booking.TicketingTimeLimit), t.Booking.ProviderRecommendationLimit)
+	if !limit.IsZero() && limit.After(now) {
+		d.TimeLimits.ExpiresInSeconds = int64(limit.Sub(now).Seconds())
+	}
+
+	return d
+}
@@ -0,0 +1,61 @@
+package trip_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTrip_Details(t *testing.T) {
+	now := time.Date(2024, 10, 15, 7, 0, 0, 0, time.UTC)
+
+	tr := trip.Trip{
+		Prices: trip.TripPrices{
+			Price:                  500,
+			PassengersPriceDetails: map[string]float64{"ADT": 200, "CHD": 100},
+		},
+		Segments: []trip.TripSegment{
+			{
+				Carrier:      "SU",
+				FlightNumber: "100",
+				Departure:    trip.FlightPoint{Airport: "SVO"},
+				Arrival:      trip.FlightPoint{Airport: "LED"},
+				Baggage:      trip.BaggageInfo{Pieces: 1, Weight: 23},
+				Meal:         "snack",
+			},
+		},
+		Booking: trip.TripBooking{
+			ExpiresAt:          now.Add(2 * time.Hour),
+			TicketingTimeLimit: now.Add(30 * time.Minute),
+		},
+		SRO: &sro.SRO{Passengers: sro.Passengers{ADT: 2, CHD: 1, INF: 1}},
+	}
+
+	got := tr.Details(now)
+
+	wantPassengers := []trip.PassengerPrice{
+		{Type: sro.PassengerTypeADT, Count: 2, Price: 200, Total: 400},
+		{Type: sro.PassengerTypeCHD, Count: 1, Price: 100, Total: 100},
+	}
+	if diff := cmp.Diff(wantPassengers, got.Passengers); diff != "" {
+		t.Errorf("Details() passengers mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSegments := []trip.SegmentDetails{
+		{Flight: "SU100", From: "SVO", To: "LED", Baggage: trip.BaggageInfo{Pieces: 1, Weight: 23}, Meal: "snack"},
+	}
+	if diff := cmp.Diff(wantSegments, got.Segments); diff != "" {
+		t.Errorf("Details() segments mismatch (-want +got):\n%s", diff)
+	}
+
+	if got.TimeLimits.ExpiresInSeconds != 30*60 {
+		t.Errorf("Details() expires in %d seconds, want %d", got.TimeLimits.ExpiresInSeconds, 30*60)
+	}
+	if expired := tr.Details(now.Add(time.Hour)); expired.TimeLimits.ExpiresInSeconds != 0 {
+		t.Errorf("Details() after the limit expires in %d seconds, want 0", expired.TimeLimits.ExpiresInSeconds)
+	}
+}
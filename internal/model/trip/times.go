@@ -0,0 +1,144 @@
+package trip
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/airport"
+)
+
+var (
+	ErrInvalidDuration   = errors.New("segment duration doesn't match its times")
+	ErrInvalidStopTime   = errors.New("stop time doesn't match connection times")
+	ErrInvalidTimeWindow = errors.New("invalid time window")
+)
+
+// NormalizeTimes converts departure and arrival times to the local time of
+// their airports and checks DurationMinutes and StopTimeMinutes against them.
+// Mismatching values are replaced with the computed ones and reported in the
+// returned error; zero values are filled silently.
+func (t *Trip) NormalizeTimes() error {
+	dir := airport.Default()
+	var errs []error
+
+	for i := range t.Segments {
+		s := &t.Segments[i]
+		s.Departure.Time = dir.In(s.Departure.Airport, s.Departure.Time)
+		s.Arrival.Time = dir.In(s.Arrival.Airport, s.Arrival.Time)
+
+		if s.Departure.Time.IsZero() || s.Arrival.Time.IsZero() {
+			continue
+		}
+
+		duration := minutesBetween(s.Departure.Time, s.Arrival.Time)
+		if s.DurationMinutes != 0 && s.DurationMinutes != duration {
+			errs = append(errs, fmt.Errorf("%w: %s%s got %d, want %d",
+				ErrInvalidDuration, s.Carrier, s.FlightNumber, s.DurationMinutes, duration))
+		}
+		s.DurationMinutes = duration
+	}
+
+	for i := 0; i+1 < len(t.Segments); i++ {
+		cur, next := &t.Segments[i], &t.Segments[i+1]
+		if cur.Direction != next.Direction || cur.Arrival.Time.IsZero() || next.Departure.Time.IsZero() {
+			continue
+		}
+
+		stop := minutesBetween(cur.Arrival.Time, next.Departure.Time)
+		if cur.StopTimeMinutes != 0 && cur.StopTimeMinutes != stop {
+			errs = append(errs, fmt.Errorf("%w: at %s got %d, want %d",
+				ErrInvalidStopTime, cur.Arrival.Airport, cur.StopTimeMinutes, stop))
+		}
+		cur.StopTimeMinutes = stop
+	}
+
+	if forward := t.GetForwardSegments(); t.Metadata.RouteDuration == 0 && len(forward) > 0 {
+		first, last := forward[0], forward[len(forward)-1]
+		if !first.Departure.Time.IsZero() && !last.Arrival.Time.IsZero() {
+			t.Metadata.RouteDuration = minutesBetween(first.Departure.Time, last.Arrival.Time)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func minutesBetween(from, to time.Time) int {
+	return int(to.Sub(from).Minutes())
+}
+
+// TimeWindow is a range of local wall-clock times, e.g. 06:00-12:00.
+// A window with From after To wraps around midnight (22:00-02:00).
+type TimeWindow struct {
+	From time.Duration
+	To   time.Duration
+}
+
+// ParseTimeWindow parses "HH:MM-HH:MM".
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return TimeWindow{}, ErrInvalidTimeWindow
+	}
+
+	f, err := parseClock(from)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	t, err := parseClock(to)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	return TimeWindow{From: f, To: t}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, ErrInvalidTimeWindow
+	}
+
+	hours, err := strconv.Atoi(h)
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, ErrInvalidTimeWindow
+	}
+	minutes, err := strconv.Atoi(m)
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes != 0) {
+		return 0, ErrInvalidTimeWindow
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// Contains checks the wall-clock part of t in its own location, so t must
+// already be in the airport's local time.
+func (w TimeWindow) Contains(t time.Time) bool {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.From <= w.To {
+		return clock >= w.From && clock <= w.To
+	}
+	return clock >= w.From || clock <= w.To
+}
+
+// DepartsWithin checks the local departure time of the first segment in the
+// given direction.
+func (t *Trip) DepartsWithin(direction int, w TimeWindow) bool {
+	for _, s := range t.Segments {
+		if s.Direction == direction {
+			return w.Contains(airport.Default().In(s.Departure.Airport, s.Departure.Time))
+		}
+	}
+	return false
+}
+
+// ArrivesWithin checks the local arrival time of the last segment in the
+// given direction.
+func (t *Trip) ArrivesWithin(direction int, w TimeWindow) bool {
+	for i := len(t.Segments) - 1; i >= 0; i-- {
+		if s := t.Segments[i]; s.Direction == direction {
+			return w.Contains(airport.Default().In(s.Arrival.Airport, s.Arrival.Time))
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package trip_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func TestTrip_NormalizeTimes(t *testing.T) {
+	// SVO 10:00 MSK -> OVB 18:00 NOVT (4h flight), OVB 20:30 NOVT -> KJA 21:30 KRAT (1h flight)
+	dep := time.Date(2024, 10, 15, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		duration     int
+		stop         int
+		wantErr      error
+		wantDuration int
+		wantStop     int
+	}{
+		{name: "empty values are filled", wantDuration: 240, wantStop: 150},
+		{name: "correct values", duration: 240, stop: 150, wantDuration: 240, wantStop: 150},
+		{name: "wrong duration", duration: 420, stop: 150, wantErr: trip.ErrInvalidDuration, wantDuration: 240, wantStop: 150},
+		{name: "wrong stop", duration: 240, stop: 30, wantErr: trip.ErrInvalidStopTime, wantDuration: 240, wantStop: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := trip.Trip{
+				Segments: []trip.TripSegment{
+					{
+						Departure:       trip.FlightPoint{Airport: "SVO", Time: dep},
+						Arrival:         trip.FlightPoint{Airport: "OVB", Time: dep.Add(4 * time.Hour)},
+						DurationMinutes: tt.duration,
+						StopTimeMinutes: tt.stop,
+					},
+					{
+						Departure: trip.FlightPoint{Airport: "OVB", Time: dep.Add(6*time.Hour + 30*time.Minute)},
+						Arrival:   trip.FlightPoint{Airport: "KJA", Time: dep.Add(7*time.Hour + 30*time.Minute)},
+					},
+				},
+			}
+
+			err := tr.NormalizeTimes()
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NormalizeTimes() error = %v, want %v", err, tt.wantErr)
+			}
+
+			first := tr.Segments[0]
+			if first.DurationMinutes != tt.wantDuration || first.StopTimeMinutes != tt.wantStop {
+				t.Errorf("got duration %d stop %d, want %d %d", first.DurationMinutes, first.StopTimeMinutes, tt.wantDuration, tt.wantStop)
+			}
+			if got := first.Departure.Time.Format("15:04 -0700"); got != "10:00 +0300" {
+				t.Errorf("departure = %s, want 10:00 +0300", got)
+			}
+			if got := first.Arrival.Time.Format("15:04 -0700"); got != "18:00 +0700" {
+				t.Errorf("arrival = %s, want 18:00 +0700", got)
+			}
+			if tr.Metadata.RouteDuration != 450 {
+				t.Errorf("RouteDuration = %d, want 450", tr.Metadata.RouteDuration)
+			}
+		})
+	}
+}
+
+func TestTimeWindow_Contains(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  string
+		clock   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "inside", window: "06:00-12:00", clock: "09:15", want: true},
+		{name: "bounds", window: "06:00-12:00", clock: "12:00", want: true},
+		{name: "outside", window: "06:00-12:00", clock: "12:01", want: false},
+		{name: "over midnight", window: "22:00-02:00", clock: "01:30", want: true},
+		{name: "over midnight outside", window: "22:00-02:00", clock: "03:00", want: false},
+		{name: "invalid", window: "6-12", wantErr: true},
+		{name: "invalid hours", window: "06:00-25:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := trip.ParseTimeWindow(tt.window)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeWindow() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			clock, _ := time.Parse("15:04", tt.clock)
+			if got := w.Contains(clock); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.clock, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrip_DepartsWithin(t *testing.T) {
+	// 05:30 UTC is 08:30 in Moscow, but 12:30 in Novosibirsk.
+	tr := trip.Trip{
+		Segments: []trip.TripSegment{
+			{
+				Departure: trip.FlightPoint{Airport: "SVO", Time: time.Date(2024, 10, 15, 5, 30, 0, 0, time.UTC)},
+				Arrival:   trip.FlightPoint{Airport: "OVB", Time: time.Date(2024, 10, 15, 9, 30, 0, 0, time.UTC)},
+			},
+		},
+	}
+	morning, _ := trip.ParseTimeWindow("06:00-12:00")
+
+	if !tr.DepartsWithin(0, morning) {
+		t.Errorf("DepartsWithin(06:00-12:00) = false, want true")
+	}
+	if tr.ArrivesWithin(0, morning) {
+		t.Errorf("ArrivesWithin(06:00-12:00) = true, want false")
+	}
+}
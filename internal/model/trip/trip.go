@@ -3,6 +3,7 @@ package trip
 import (
 	"time"
 
+	"github.com/de4et/flight-booking/internal/model/airport"
 	"github.com/de4et/flight-booking/internal/model/sro"
 )
 
@@ -34,6 +35,10 @@ type TripSegment struct {
 	Aircraft         string      `json:"aircraft"`
 	StopTimeMinutes  int         `json:"stopTimeMinutes"`
 	Direction        int         `json:"direction"`
+	// Display names in the SRO language, filled in on the way out and never
+	// cached.
+	CarrierName  string `json:"carrierName,omitempty"`
+	AircraftName string `json:"aircraftName,omitempty"`
 }
 
 type TravelClass string
@@ -49,6 +54,10 @@ type FlightPoint struct {
 	Airport  string    `json:"airport"`
 	Terminal string    `json:"terminal,omitempty"`
 	Time     time.Time `json:"time"`
+	// Display names in the SRO language, filled in on the way out and never
+	// cached.
+	AirportName string `json:"airportName,omitempty"`
+	CityName    string `json:"cityName,omitempty"`
 }
 
 type BaggageInfo struct {
@@ -59,7 +68,7 @@ type BaggageInfo struct {
 
 type TripPrices struct {
 	Price                  float64            `json:"price"`
-	SearchPrice            float64            `json:"searchPrice"`
+	SearchPrice            float64            `json:"searchPrice"` // provider price before pricing
 	PriceFare              float64            `json:"priceFare"`
 	ProviderServiceFee     float64            `json:"providerServiceFee"`
 	ProviderTaxesAmount    float64            `json:"providerTaxesAmount"`
@@ -70,13 +79,22 @@ type TripPrices struct {
 	PricerInfo             PricerInfo         `json:"pricerInfo"`
 	BagsPrice              float64            `json:"bagsPrice"`
 	PassengersPriceDetails map[string]float64 `json:"passengersPriceDetails"`
+	// Currency is the currency of the amounts above. When they are converted
+	// from ProviderCurrency, Original keeps the amounts before conversion.
+	Currency     string      `json:"currency,omitempty"`
+	ExchangeRate float64     `json:"exchangeRate,omitempty"`
+	Original     *TripPrices `json:"original,omitempty"`
 }
 
+// PricerInfo holds the fees set by pricing and the rule they came from.
 type PricerInfo struct {
 	Markup              float64 `json:"markup"`
 	Commission          float64 `json:"commission"`
 	PartnerAffiliateFee float64 `json:"partnerAffiliateFee"`
 	CashbackRate        float64 `json:"cashbackRate"`
+	RuleID              int64   `json:"ruleId,omitempty"`
+	RuleName            string  `json:"ruleName,omitempty"`
+	MinPriceApplied     bool    `json:"minPriceApplied,omitempty"`
 }
 
 type Provider struct {
@@ -109,6 +127,11 @@ type TripMetadata struct {
 	IsVirtualInterline bool       `json:"isVirtualInterline"`
 }
 
+const (
+	FlightTypeDomestic      = "domestic"
+	FlightTypeInternational = "international"
+)
+
 type FareFamily struct {
 	Type          string `json:"type"`
 	Name          string `json:"name"`
@@ -124,11 +147,20 @@ type TripBooking struct {
 	CountOfBlanks                 int       `json:"countOfBlanks"`
 	BookingWithPartialDataAllowed bool      `json:"bookingWithPartialDataAllowed"`
 	BookingActualizationAllowed   bool      `json:"bookingActualizationAllowed"`
+	// Bookable tells whether an order can be placed for the trip, see
+	// IsBookable. Filled in on the way out and never cached.
+	Bookable bool `json:"bookable"`
 }
 
 func (t *Trip) GetPrice() float64 { return t.Prices.Price }
 func (t *Trip) HasBaggage() bool  { return t.Metadata.HasBaggage }
 func (t *Trip) GetSRO() *sro.SRO  { return t.SRO }
+
+// IsBookable reports whether the trip can be booked as a single order.
+// Virtual trips are separate tickets, possibly of different providers, and
+// are not booked together.
+func (t *Trip) IsBookable() bool { return !t.Metadata.IsVtrip }
+
 func (t *Trip) GetForwardSegments() []TripSegment {
 	var segs []TripSegment
 	for _, s := range t.Segments {
@@ -138,3 +170,53 @@ func (t *Trip) GetForwardSegments() []TripSegment {
 	}
 	return segs
 }
+
+// FliesRoute checks both the requested SRO segments (which may hold city
+// codes) and the airports the trip actually flies through.
+func (t *Trip) FliesRoute(from, to string) bool {
+	if t.SRO != nil {
+		for _, s := range t.SRO.Segments {
+			if s.From == from && s.To == to {
+				return true
+			}
+		}
+	}
+
+	forward := t.GetForwardSegments()
+	if len(forward) == 0 {
+		return false
+	}
+	return forward[0].Departure.Airport == from && forward[len(forward)-1].Arrival.Airport == to
+}
+
+// DetectFlightType classifies the trip by the countries of the airports it
+// flies through, falling back to the SRO when segments are unknown.
+func (t *Trip) DetectFlightType() string {
+	if len(t.Segments) == 0 {
+		if t.SRO != nil && t.SRO.IsDomestic() {
+			return FlightTypeDomestic
+		}
+		return FlightTypeInternational
+	}
+
+	dir := airport.Default()
+	first, ok := dir.Country(t.Segments[0].Departure.Airport)
+	if !ok {
+		return FlightTypeInternational
+	}
+	for _, s := range t.Segments {
+		from, okFrom := dir.Country(s.Departure.Airport)
+		to, okTo := dir.Country(s.Arrival.Airport)
+		if !okFrom || !okTo || from != first || to != first {
+			return FlightTypeInternational
+		}
+	}
+	return FlightTypeDomestic
+}
+
+func (t *Trip) IsDomestic() bool {
+	if t.Metadata.FlightType != "" {
+		return t.Metadata.FlightType == FlightTypeDomestic
+	}
+	return t.DetectFlightType() == FlightTypeDomestic
+}
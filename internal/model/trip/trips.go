@@ -2,13 +2,13 @@ package trip
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 )
 
 type Trips struct {
 	storage    map[string]Trip
 	arrStorage []Trip
-	// ban-list
 }
 
 func NewTrips() *Trips {
@@ -17,15 +17,24 @@ func NewTrips() *Trips {
 	}
 }
 
-func (ts *Trips) AddTrip(t Trip) { // add ban service?
-	v, ok := ts.storage[t.CacheID]
-	if ok {
-		if v.GetPrice() > t.GetPrice() {
-			ts.Set(t.CacheID, t)
-		}
-	} else {
-		ts.Set(t.CacheID, t)
+func (ts *Trips) AddTrip(t Trip) {
+	if ts.add(t) {
+		ts.updateArr()
+	}
+}
+
+// add stores the trip unless a cheaper one with the same ID is stored and
+// reports whether it was stored.
+func (ts *Trips) add(t Trip) bool {
+	if t.Metadata.FlightType == "" {
+		t.Metadata.FlightType = t.DetectFlightType()
+	}
+
+	if v, ok := ts.storage[t.CacheID]; ok && v.GetPrice() <= t.GetPrice() {
+		return false
 	}
+	ts.storage[t.CacheID] = t
+	return true
 }
 
 func (ts *Trips) RemoveTrip(t *Trip) {
@@ -35,8 +44,30 @@ func (ts *Trips) RemoveTrip(t *Trip) {
 
 func (ts *Trips) Merge(tsm *Trips) {
 	for k := range tsm.storage {
-		ts.AddTrip(tsm.storage[k])
+		ts.add(tsm.storage[k])
+	}
+	ts.updateArr()
+}
+
+// Update calls fn for every trip and stores the changes it makes, trips fn
+// returns false for are removed. Unlike Set for each trip, the trips are
+// reordered only once.
+func (ts *Trips) Update(fn func(*Trip) bool) {
+	for k, t := range ts.storage {
+		if fn(&t) {
+			ts.storage[k] = t
+		} else {
+			delete(ts.storage, k)
+		}
 	}
+	ts.updateArr()
+}
+
+// Clone returns a copy of the trips that can be changed apart from them.
+func (ts *Trips) Clone() *Trips {
+	res := &Trips{storage: maps.Clone(ts.storage)}
+	res.updateArr()
+	return res
 }
 
 func (ts *Trips) Set(key string, t Trip) {
@@ -63,6 +94,17 @@ func (ts *Trips) ToArray() []Trip {
 	return ts.arrStorage
 }
 
+// Filter returns the trips keep returns true for, in the current order.
+func (ts *Trips) Filter(keep func(*Trip) bool) []Trip {
+	res := make([]Trip, 0, len(ts.arrStorage))
+	for i := range ts.arrStorage {
+		if keep(&ts.arrStorage[i]) {
+			res = append(res, ts.arrStorage[i])
+		}
+	}
+	return res
+}
+
 func (ts *Trips) Count() int {
 	return len(ts.arrStorage)
 }
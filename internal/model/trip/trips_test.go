@@ -0,0 +1,54 @@
+package trip_test
+
+import (
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+func TestTrips_Update(t *testing.T) {
+	ts := trip.NewTrips()
+	for _, id := range []string{"a", "b", "c"} {
+		ts.AddTrip(trip.Trip{CacheID: id, Prices: trip.TripPrices{Price: 100}})
+	}
+	clone := ts.Clone()
+
+	ts.Update(func(t *trip.Trip) bool {
+		t.Prices.Price += 10
+		return t.CacheID != "b"
+	})
+
+	if ts.Count() != 2 || ts.Contains("b") {
+		t.Errorf("Update() left %d trips, want a and c", ts.Count())
+	}
+	for _, tr := range ts.ToArray() {
+		if tr.Prices.Price != 110 {
+			t.Errorf("trip %s price = %v, want 110", tr.CacheID, tr.Prices.Price)
+		}
+	}
+
+	// The clone keeps the trips as they were.
+	if clone.Count() != 3 {
+		t.Errorf("clone has %d trips, want 3", clone.Count())
+	}
+	if tr, _ := clone.Get("a"); tr.Prices.Price != 100 {
+		t.Errorf("clone trip a price = %v, want 100", tr.Prices.Price)
+	}
+}
+
+func TestTrips_Merge(t *testing.T) {
+	ts := trip.NewTrips()
+	ts.AddTrip(trip.Trip{CacheID: "a", Prices: trip.TripPrices{Price: 100}})
+
+	other := trip.NewTrips()
+	other.AddTrip(trip.Trip{CacheID: "a", Prices: trip.TripPrices{Price: 90}})
+	other.AddTrip(trip.Trip{CacheID: "b", Prices: trip.TripPrices{Price: 120}})
+	ts.Merge(other)
+
+	if ts.Count() != 2 {
+		t.Fatalf("Merge() left %d trips, want 2", ts.Count())
+	}
+	if tr, _ := ts.Get("a"); tr.Prices.Price != 90 {
+		t.Errorf("trip a price = %v, want the cheaper 90", tr.Prices.Price)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/analytics"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReportDays is the length of a report period when from is not given.
+const defaultReportDays = 30
+
+type AnalyticsHandler struct {
+	analytics *service.SearchAnalytics
+}
+
+func NewAnalyticsHandler(analytics *service.SearchAnalytics) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analytics: analytics,
+	}
+}
+
+// LookToBook reports searches per order for every partner. The period is
+// given by the from and to query parameters, as dates (to excluded) or
+// RFC 3339 times, and defaults to the last 30 days including today.
+func (handler *AnalyticsHandler) LookToBook(c *gin.Context) {
+	p, err := reportPeriod(c, time.Now())
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := handler.analytics.LookToBook(c, p)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidPeriod) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func reportPeriod(c *gin.Context, now time.Time) (analytics.Period, error) {
+	y, m, d := now.UTC().Date()
+	p := analytics.Period{To: time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)}
+
+	if v := c.Query("to"); v != "" {
+		t, err := parseReportTime(v)
+		if err != nil {
+			return p, err
+		}
+		p.To = t
+	}
+	p.From = p.To.AddDate(0, 0, -defaultReportDays)
+	if v := c.Query("from"); v != "" {
+		t, err := parseReportTime(v)
+		if err != nil {
+			return p, err
+		}
+		p.From = t
+	}
+	return p, nil
+}
+
+func parseReportTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is neither a date nor an RFC 3339 time", analytics.ErrInvalidPeriod, v)
+	}
+	return t, nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/ban"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidBanID = errors.New("invalid ban id")
+
+type BanHandler struct {
+	banService *service.BanService
+}
+
+func NewBanHandler(banService *service.BanService) *BanHandler {
+	return &BanHandler{
+		banService: banService,
+	}
+}
+
+func (handler *BanHandler) List(c *gin.Context) {
+	bans, err := handler.banService.List(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bans)
+}
+
+func (handler *BanHandler) Create(c *gin.Context) {
+	var b ban.Ban
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	b, err := handler.banService.Create(c, b)
+	if err != nil {
+		if errors.Is(err, ban.ErrInvalidBan) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "ban", b)
+	slog.InfoContext(ctx, "Ban created")
+
+	c.JSON(http.StatusCreated, b)
+}
+
+func (handler *BanHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, ErrInvalidBanID)
+		return
+	}
+
+	err = handler.banService.Delete(c, id)
+	if err != nil {
+		if errors.Is(err, ban.ErrNotFound) {
+			c.AbortWithError(http.StatusNotFound, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "ban.id", id)
+	slog.InfoContext(ctx, "Ban deleted")
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidDays = errors.New("invalid days")
+
+const daysName = "days"
+
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+func NewCalendarHandler(calendarService *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+func (handler *CalendarHandler) Handle(c *gin.Context) {
+	token := c.Query(tokenName)
+	if len(token) == 0 {
+		c.AbortWithError(http.StatusBadRequest, ErrNoToken)
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery(daysName, strconv.Itoa(service.MaxCalendarDays)))
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, ErrInvalidDays)
+		return
+	}
+
+	ctx := logger.WithContext(c, "token", token)
+	ctx = logger.WithContext(ctx, "days", days)
+	cal, err := handler.calendarService.Search(ctx, token, days)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSRO) || errors.Is(err, service.ErrCalendarUnsupported) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		if errors.Is(err, partner.ErrForbidden) {
+			c.AbortWithError(http.StatusForbidden, err)
+			return
+		}
+		if errors.Is(err, ratelimit.ErrLimited) {
+			abortWithRateLimit(c, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Successfully built price calendar", "failedDates", len(cal.Errors))
+
+	c.Header(tokenHeader, token)
+	c.JSON(http.StatusOK, cal)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FXHandler struct {
+	fxService *service.FXService
+}
+
+func NewFXHandler(fxService *service.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+	}
+}
+
+func (handler *FXHandler) List(c *gin.Context) {
+	rates, err := handler.fxService.List(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+func (handler *FXHandler) Save(c *gin.Context) {
+	var r fx.Rate
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	r, err := handler.fxService.Save(c, r)
+	if err != nil {
+		if errors.Is(err, fx.ErrInvalidRate) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "rate", r)
+	slog.InfoContext(ctx, "Exchange rate saved")
+
+	c.JSON(http.StatusCreated, r)
+}
+
+// Import saves rates from a CSV body of from,to,rate,effective date lines.
+func (handler *FXHandler) Import(c *gin.Context) {
+	n, err := handler.fxService.Import(c, c.Request.Body)
+	if err != nil {
+		if errors.Is(err, fx.ErrInvalidRate) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "count", n)
+	slog.InfoContext(ctx, "Exchange rates imported")
+
+	c.JSON(http.StatusOK, map[string]int{"imported": n})
+}
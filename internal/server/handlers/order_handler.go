@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/passenger"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidOrderID = errors.New("invalid order id")
+
+type OrderHandler struct {
+	orderService *service.OrderService
+}
+
+func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+type createOrderRequest struct {
+	Token      string                `json:"token" binding:"required"`
+	CacheID    string                `json:"cacheId" binding:"required"`
+	Passengers []passenger.Passenger `json:"passengers" binding:"required"`
+	Contacts   order.Contacts        `json:"contacts"`
+}
+
+func (handler *OrderHandler) Create(c *gin.Context) {
+	var req createOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "token", req.Token)
+	ctx = logger.WithContext(ctx, "cacheId", req.CacheID)
+	o, err := handler.orderService.Create(ctx, req.Token, req.CacheID, req.Passengers, req.Contacts)
+	if err != nil {
+		switch {
+		case errors.Is(err, order.ErrInvalidOrder):
+			c.AbortWithError(http.StatusBadRequest, err)
+		case errors.Is(err, partner.ErrBookingForbidden):
+			c.AbortWithError(http.StatusForbidden, err)
+		case errors.Is(err, service.ErrBookingUnsupported):
+			c.AbortWithError(http.StatusUnprocessableEntity, err)
+		default:
+			abortWithTripError(c, err)
+		}
+		return
+	}
+
+	slog.InfoContext(ctx, "Successfully created order", "order.id", o.ID)
+
+	c.JSON(http.StatusCreated, o)
+}
+
+func (handler *OrderHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, ErrInvalidOrderID)
+		return
+	}
+
+	o, err := handler.orderService.Get(c, id)
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			c.AbortWithError(http.StatusNotFound, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, o)
+}
+
+func (handler *OrderHandler) Events(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, ErrInvalidOrderID)
+		return
+	}
+
+	events, err := handler.orderService.Events(c, id)
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			c.AbortWithError(http.StatusNotFound, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
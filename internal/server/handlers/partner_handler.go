@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidPartnerID = errors.New("invalid partner id")
+
+type PartnerHandler struct {
+	partnerService *service.PartnerService
+}
+
+func NewPartnerHandler(partnerService *service.PartnerService) *PartnerHandler {
+	return &PartnerHandler{
+		partnerService: partnerService,
+	}
+}
+
+func (handler *PartnerHandler) List(c *gin.Context) {
+	partners, err := handler.partnerService.List(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, partners)
+}
+
+func (handler *PartnerHandler) Create(c *gin.Context) {
+	var p partner.Partner
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := handler.partnerService.Create(c, p)
+	if err != nil {
+		if errors.Is(err, partner.ErrInvalidPartner) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "partner.code", p.Code)
+	slog.InfoContext(ctx, "Partner created")
+
+	c.JSON(http.StatusCreated, p)
+}
+
+func (handler *PartnerHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, ErrInvalidPartnerID)
+		return
+	}
+
+	err = handler.partnerService.Delete(c, id)
+	if err != nil {
+		if errors.Is(err, partner.ErrNotFound) {
+			c.AbortWithError(http.StatusNotFound, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "partner.id", id)
+	slog.InfoContext(ctx, "Partner deleted")
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidPricingRuleID = errors.New("invalid pricing rule id")
+
+type PricingHandler struct {
+	pricingService *service.PricingService
+}
+
+func NewPricingHandler(pricingService *service.PricingService) *PricingHandler {
+	return &PricingHandler{
+		pricingService: pricingService,
+	}
+}
+
+func (handler *PricingHandler) List(c *gin.Context) {
+	rules, err := handler.pricingService.List(c)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (handler *PricingHandler) Create(c *gin.Context) {
+	var r pricing.Rule
+	if err := c.ShouldBindJSON(&r); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	r, err := handler.pricingService.Create(c, r)
+	if err != nil {
+		if errors.Is(err, pricing.ErrInvalidRule) {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "pricingRule", r)
+	slog.InfoContext(ctx, "Pricing rule created")
+
+	c.JSON(http.StatusCreated, r)
+}
+
+func (handler *PricingHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, ErrInvalidPricingRuleID)
+		return
+	}
+
+	err = handler.pricingService.Delete(c, id)
+	if err != nil {
+		if errors.Is(err, pricing.ErrNotFound) {
+			c.AbortWithError(http.StatusNotFound, err)
+			return
+		}
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx := logger.WithContext(c, "pricingRule.id", id)
+	slog.InfoContext(ctx, "Pricing rule deleted")
+
+	c.Status(http.StatusNoContent)
+}
@@ -4,8 +4,13 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/i18n"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/model/trip"
 	"github.com/de4et/flight-booking/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +20,18 @@ var ErrNoToken = errors.New("no token provided")
 
 const tokenName = "token"
 
+// tokenHeader echoes the token the client sent, results may be shared with
+// equivalent tokens.
+const tokenHeader = "X-SRO-Token"
+
+// Time window filters in the local time of the airport, e.g. departure=06:00-12:00.
+const (
+	departureWindowName       = "departure"
+	arrivalWindowName         = "arrival"
+	returnDepartureWindowName = "returnDeparture"
+	returnArrivalWindowName   = "returnArrival"
+)
+
 type SearchResultHandler struct {
 	searchService *service.MultipleSearchService
 }
@@ -32,14 +49,93 @@ func (handler *SearchResultHandler) Handle(c *gin.Context) {
 		return
 	}
 
+	filters, err := parseTimeFilters(c)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
 	ctx := logger.WithContext(c, "token", token)
 	ts, err := handler.searchService.SearchByToken(ctx, token)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSRO):
+			c.AbortWithError(http.StatusBadRequest, err)
+		case errors.Is(err, partner.ErrForbidden):
+			c.AbortWithError(http.StatusForbidden, err)
+		case errors.Is(err, ratelimit.ErrLimited):
+			abortWithRateLimit(c, err)
+		default:
+			c.AbortWithError(http.StatusInternalServerError, err)
+		}
 		return
 	}
 
 	ctx = logger.WithContext(ctx, "trips", ts)
 	slog.InfoContext(ctx, "Successfully recieved trips")
 
-	c.JSON(http.StatusOK, ts.ToArray())
+	c.Header(tokenHeader, token)
+
+	trips := ts.Filter(func(t *trip.Trip) bool {
+		for _, f := range filters {
+			if !f(t) {
+				return false
+			}
+		}
+		return true
+	})
+	c.JSON(http.StatusOK, localize(trips))
+}
+
+// localize fills display names of the trips in the language of their SRO.
+func localize(trips []trip.Trip) []trip.Trip {
+	for i := range trips {
+		i18n.Default().Localize(&trips[i])
+	}
+	return trips
+}
+
+// abortWithRateLimit answers 429 with the time the client should wait.
+func abortWithRateLimit(c *gin.Context, err error) {
+	var exceeded *ratelimit.Exceeded
+	if errors.As(err, &exceeded) {
+		c.Header("Retry-After", strconv.Itoa(exceeded.RetryAfterSeconds()))
+	}
+	c.AbortWithError(http.StatusTooManyRequests, err)
+}
+
+type tripFilter func(*trip.Trip) bool
+
+func parseTimeFilters(c *gin.Context) ([]tripFilter, error) {
+	var filters []tripFilter
+
+	params := []struct {
+		name      string
+		direction int
+		departure bool
+	}{
+		{departureWindowName, 0, true},
+		{arrivalWindowName, 0, false},
+		{returnDepartureWindowName, 1, true},
+		{returnArrivalWindowName, 1, false},
+	}
+
+	for _, p := range params {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+
+		w, err := trip.ParseTimeWindow(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.departure {
+			filters = append(filters, func(t *trip.Trip) bool { return t.DepartsWithin(p.direction, w) })
+		} else {
+			filters = append(filters, func(t *trip.Trip) bool { return t.ArrivesWithin(p.direction, w) })
+		}
+	}
+	return filters, nil
 }
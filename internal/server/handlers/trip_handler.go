@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/i18n"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const cacheIDName = "cacheId"
+
+type TripHandler struct {
+	searchService *service.MultipleSearchService
+}
+
+func NewTripHandler(searchService *service.MultipleSearchService) *TripHandler {
+	return &TripHandler{
+		searchService: searchService,
+	}
+}
+
+func (handler *TripHandler) Get(c *gin.Context) {
+	token := c.Query(tokenName)
+	if len(token) == 0 {
+		c.AbortWithError(http.StatusBadRequest, ErrNoToken)
+		return
+	}
+	cacheID := c.Param(cacheIDName)
+
+	ctx := logger.WithContext(c, "token", token)
+	ctx = logger.WithContext(ctx, "cacheId", cacheID)
+	t, err := handler.searchService.GetTrip(ctx, token, cacheID)
+	if err != nil {
+		abortWithTripError(c, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Successfully recieved trip")
+
+	i18n.Default().Localize(&t)
+	c.JSON(http.StatusOK, t.Details(time.Now()))
+}
+
+func (handler *TripHandler) Actualize(c *gin.Context) {
+	token := c.Query(tokenName)
+	if len(token) == 0 {
+		c.AbortWithError(http.StatusBadRequest, ErrNoToken)
+		return
+	}
+	cacheID := c.Param(cacheIDName)
+
+	ctx := logger.WithContext(c, "token", token)
+	ctx = logger.WithContext(ctx, "cacheId", cacheID)
+	a, err := handler.searchService.Actualize(ctx, token, cacheID)
+	if err != nil {
+		abortWithTripError(c, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Successfully actualized trip", "available", a.Available)
+
+	i18n.Default().Localize(&a.Trip)
+	c.JSON(http.StatusOK, a)
+}
+
+func abortWithTripError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSRO):
+		c.AbortWithError(http.StatusBadRequest, err)
+	case errors.Is(err, partner.ErrForbidden):
+		c.AbortWithError(http.StatusForbidden, err)
+	case errors.Is(err, service.ErrTripNotFound):
+		c.AbortWithError(http.StatusNotFound, err)
+	case errors.Is(err, service.ErrActualizationUnsupported):
+		c.AbortWithError(http.StatusUnprocessableEntity, err)
+	case errors.Is(err, service.ErrProviderFailed):
+		c.AbortWithError(http.StatusBadGateway, err)
+	default:
+		c.AbortWithError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrAdminUnauthenticated = errors.New("admin token is missing or invalid")
+
+// AdminAuth lets through requests carrying the admin token as a bearer token
+// in the Authorization header. Without a token every request is refused.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithError(http.StatusUnauthorized, ErrAdminUnauthenticated)
+			return
+		}
+		c.Next()
+	}
+}
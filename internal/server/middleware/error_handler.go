@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/de4et/flight-booking/internal/model/i18n"
+	"github.com/de4et/flight-booking/internal/model/sro"
 )
 
 func ErrorHandler() gin.HandlerFunc {
@@ -13,6 +16,23 @@ func ErrorHandler() gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(-1, err)
+		msg, ok := i18n.Default().Error(language(c), err.Err)
+		if !ok {
+			c.JSON(-1, err)
+			return
+		}
+		c.JSON(-1, map[string]string{
+			"error":   msg,
+			"details": err.Error(),
+		})
+	}
+}
+
+// language is the language of the SRO in the token query parameter, or the
+// preferred one of the Accept-Language header when there is no valid token.
+func language(c *gin.Context) string {
+	if s, err := sro.FromToken(c.Query("token")); err == nil && s.Metadata.Language != "" {
+		return s.Metadata.Language
 	}
+	return c.GetHeader("Accept-Language")
 }
@@ -9,13 +9,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// unmatchedPath labels requests that matched no route.
+const unmatchedPath = "unmatched"
+
 func MetricsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
+		// The route pattern rather than the URL, IDs in paths would make a
+		// series per trip and order.
+		path := c.FullPath()
+		if path == "" {
+			path = unmatchedPath
+		}
 		method := c.Request.Method
 
 		defer func() {
+			// Partners run test traffic against production, it would skew the numbers.
+			if IsTest(c) {
+				return
+			}
+
 			status := fmt.Sprint(c.Writer.Status())
 			metrics.RequestsTotal.WithLabelValues(method, path, status).Inc()
 
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	sloggin "github.com/samber/slog-gin"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+)
+
+// Partner credentials. Requests carry the partner code and either the API key
+// or an HMAC signature of the request made with it (see partner.Sign).
+const (
+	PartnerCodeHeader = "X-Partner-Code"
+	APIKeyHeader      = "X-API-Key"
+	SignatureHeader   = "X-Signature"
+	TimestampHeader   = "X-Timestamp"
+)
+
+type partnerRegistry interface {
+	Get(context.Context, string) (partner.Partner, error)
+}
+
+// PartnerAuth authenticates the partner of the request and puts it into the
+// request context, where services check it against the SRO channel token and
+// the partner permissions.
+func PartnerAuth(partners partnerRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := authenticate(c, partners)
+		if err != nil {
+			if errors.Is(err, partner.ErrUnauthenticated) {
+				c.AbortWithError(http.StatusUnauthorized, err)
+				return
+			}
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		sloggin.AddCustomAttributes(c, slog.String("partner", p.Code))
+		c.Request = c.Request.WithContext(partner.WithContext(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+func authenticate(c *gin.Context, partners partnerRegistry) (partner.Partner, error) {
+	code := c.GetHeader(PartnerCodeHeader)
+	if code == "" {
+		return partner.Partner{}, fmt.Errorf("%w: no partner code", partner.ErrUnauthenticated)
+	}
+
+	p, err := partners.Get(c, code)
+	if errors.Is(err, partner.ErrNotFound) {
+		return partner.Partner{}, fmt.Errorf("%w: unknown partner %s", partner.ErrUnauthenticated, code)
+	}
+	if err != nil {
+		return partner.Partner{}, err
+	}
+
+	signature := c.GetHeader(SignatureHeader)
+	if signature == "" {
+		return p, p.VerifyKey(c.GetHeader(APIKeyHeader))
+	}
+
+	// The body is read for the signature and put back for the handler.
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return partner.Partner{}, fmt.Errorf("%w: %w", partner.ErrUnauthenticated, err)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return p, p.VerifySignature(signature, c.Request.Method, c.Request.URL.RequestURI(),
+		c.GetHeader(TimestampHeader), body, time.Now())
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+)
+
+type requestLimiter interface {
+	AllowRequest(context.Context, partner.Partner, bool) error
+}
+
+// RateLimit limits the requests of the partner authenticated by PartnerAuth,
+// answering 429 with Retry-After when the limit is hit.
+func RateLimit(limiter requestLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := partner.FromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		err := limiter.AllowRequest(c, p, IsTest(c))
+		var exceeded *ratelimit.Exceeded
+		if errors.As(err, &exceeded) {
+			c.Header("Retry-After", strconv.Itoa(exceeded.RetryAfterSeconds()))
+			c.AbortWithError(http.StatusTooManyRequests, err)
+			return
+		}
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	sloggin "github.com/samber/slog-gin"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+const testModeKey = "testMode"
+
+// TestMode marks requests with a test SRO token (IsTest), so they are tagged
+// in the request log and kept out of metrics. The token is taken from the
+// query or, for requests like order creation, from the JSON body.
+func TestMode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s, err := sro.FromToken(requestToken(c)); err == nil && s.Metadata.IsTest {
+			c.Set(testModeKey, true)
+			sloggin.AddCustomAttributes(c, slog.Bool("test", true))
+		}
+		c.Next()
+	}
+}
+
+// IsTest reports whether TestMode marked the request.
+func IsTest(c *gin.Context) bool {
+	return c.GetBool(testModeKey)
+}
+
+func requestToken(c *gin.Context) string {
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return ""
+	}
+
+	// The body is read for the token and put back for the handler.
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	return req.Token
+}
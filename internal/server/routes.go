@@ -6,32 +6,76 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"github.com/de4et/flight-booking/internal/health"
 	"github.com/de4et/flight-booking/internal/server/handlers"
 	"github.com/de4et/flight-booking/internal/server/middleware"
 	"github.com/de4et/flight-booking/internal/service"
 )
 
-func (s *Server) RegisterRoutes(searchService *service.MultipleSearchService) http.Handler {
+func (s *Server) RegisterRoutes(searchService *service.MultipleSearchService, banService *service.BanService, pricingService *service.PricingService, fxService *service.FXService, partnerService *service.PartnerService, rateLimiter *service.RateLimiter, orderService *service.OrderService, searchAnalytics *service.SearchAnalytics) http.Handler {
 	r := gin.New()
+	// Services read the authenticated partner from the request context.
+	r.ContextWithFallback = true
 
 	r.Use(gin.Recovery())
 	r.Use(middleware.LogHandler())
+	r.Use(middleware.TestMode())
 	r.Use(middleware.MetricsHandler())
 	r.Use(middleware.ErrorHandler())
 	r.Use(cors.New(cors.Config{
 		AllowAllOrigins: true,
 		// AllowOrigins:     []string{"http://localhost:5173"}, // Add your frontend URL
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type"},
+		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowHeaders: []string{
+			"Accept", "Authorization", "Content-Type",
+			middleware.PartnerCodeHeader, middleware.APIKeyHeader, middleware.SignatureHeader, middleware.TimestampHeader,
+		},
 		AllowCredentials: true, // Enable cookies/auth
 	}))
 
 	apiGroup := r.Group("/api/v1")
-	apiGroup.GET("/search-result", handlers.NewSearchResultHandler(searchService).Handle)
+	partnerGroup := apiGroup.Group("", middleware.PartnerAuth(partnerService), middleware.RateLimit(rateLimiter))
+	partnerGroup.GET("/search-result", handlers.NewSearchResultHandler(searchService).Handle)
+	partnerGroup.GET("/calendar", handlers.NewCalendarHandler(service.NewCalendarService(searchService)).Handle)
+
+	tripHandler := handlers.NewTripHandler(searchService)
+	partnerGroup.GET("/trips/:cacheId", tripHandler.Get)
+	partnerGroup.POST("/trips/:cacheId/actualize", tripHandler.Actualize)
+
+	orderHandler := handlers.NewOrderHandler(orderService)
+	partnerGroup.POST("/orders", orderHandler.Create)
+	partnerGroup.GET("/orders/:id", orderHandler.Get)
+	partnerGroup.GET("/orders/:id/events", orderHandler.Events)
+
+	banHandler := handlers.NewBanHandler(banService)
+	adminGroup := apiGroup.Group("/admin", middleware.AdminAuth(s.adminToken))
+	adminGroup.GET("/bans", banHandler.List)
+	adminGroup.POST("/bans", banHandler.Create)
+	adminGroup.DELETE("/bans/:id", banHandler.Delete)
+
+	pricingHandler := handlers.NewPricingHandler(pricingService)
+	adminGroup.GET("/pricing-rules", pricingHandler.List)
+	adminGroup.POST("/pricing-rules", pricingHandler.Create)
+	adminGroup.DELETE("/pricing-rules/:id", pricingHandler.Delete)
+
+	fxHandler := handlers.NewFXHandler(fxService)
+	adminGroup.GET("/fx-rates", fxHandler.List)
+	adminGroup.POST("/fx-rates", fxHandler.Save)
+	adminGroup.POST("/fx-rates/import", fxHandler.Import)
+
+	partnerHandler := handlers.NewPartnerHandler(partnerService)
+	adminGroup.GET("/partners", partnerHandler.List)
+	adminGroup.POST("/partners", partnerHandler.Create)
+	adminGroup.DELETE("/partners/:id", partnerHandler.Delete)
+
+	analyticsHandler := handlers.NewAnalyticsHandler(searchAnalytics)
+	adminGroup.GET("/analytics/look-to-book", analyticsHandler.LookToBook)
 
 	r.GET("/", s.HelloWorldHandler)
 
 	r.GET("/health", s.healthHandler)
+	r.GET("/livez", s.livenessHandler)
+	r.GET("/readyz", s.readinessHandler)
 
 	return r
 }
@@ -43,6 +87,29 @@ func (s *Server) HelloWorldHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// healthHandler reports every dependency. It answers 503 only when the API
+// is down, a degraded API still serves.
 func (s *Server) healthHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, s.db.Health())
+	report := s.health.Check(c)
+	c.JSON(healthStatusCode(report.Status), report)
+}
+
+// livenessHandler answers as long as the process serves requests. It doesn't
+// check dependencies, so their outages don't get the process restarted.
+func (s *Server) livenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// readinessHandler answers 503 while a critical dependency is down, so no
+// traffic is routed to the instance.
+func (s *Server) readinessHandler(c *gin.Context) {
+	report := s.health.Check(c)
+	c.JSON(healthStatusCode(report.Status), gin.H{"status": report.Status})
+}
+
+func healthStatusCode(status health.Status) int {
+	if status == health.StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
 }
@@ -1,11 +1,16 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/de4et/flight-booking/internal/health"
 )
 
 func TestHelloWorldHandler(t *testing.T) {
@@ -31,3 +36,47 @@ func TestHelloWorldHandler(t *testing.T) {
 		t.Errorf("Handler returned unexpected body: got %v want %v", rr.Body.String(), expected)
 	}
 }
+
+func TestProbeHandlers(t *testing.T) {
+	checks := health.NewRegistry(time.Second)
+	checks.Register("postgres", true, health.Ping(func(context.Context) error { return errors.New("db down") }))
+	s := &Server{health: checks}
+
+	r := gin.New()
+	r.GET("/livez", s.livenessHandler)
+	r.GET("/readyz", s.readinessHandler)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		// A database outage doesn't get the process restarted, only taken out
+		// of traffic.
+		{path: "/livez", want: http.StatusOK},
+		{path: "/readyz", want: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rr.Code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rr.Code, tt.want)
+		}
+	}
+}
+
+func TestAdminRoutes_Unauthenticated(t *testing.T) {
+	s := &Server{adminToken: "s3cret"}
+	r := s.RegisterRoutes(nil, nil, nil, nil, nil, nil, nil, nil)
+
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/partners", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("GET /api/v1/admin/partners with %q status = %d, want %d", auth, rr.Code, http.StatusUnauthorized)
+		}
+	}
+}
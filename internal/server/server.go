@@ -1,61 +1,193 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/de4et/flight-booking/internal/adapters/gzip"
+	"github.com/de4et/flight-booking/internal/adapters/postgres"
 	"github.com/de4et/flight-booking/internal/adapters/protobuf"
 	"github.com/de4et/flight-booking/internal/adapters/redis"
+	"github.com/de4et/flight-booking/internal/config"
 	"github.com/de4et/flight-booking/internal/database"
+	"github.com/de4et/flight-booking/internal/health"
+	"github.com/de4et/flight-booking/internal/metrics"
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
 	"github.com/de4et/flight-booking/internal/service"
 	"github.com/de4et/flight-booking/internal/service/providers"
-
-	_ "github.com/joho/godotenv/autoload"
 )
 
 type Server struct {
-	port int
+	port       int
+	adminToken string
 
-	db database.Service
+	db     database.Service
+	health *health.Registry
 }
 
-func NewServer() *http.Server {
-	port, _ := strconv.Atoi(os.Getenv("PORT"))
+// NewServer returns the API server and a channel closed once the background
+// workers, stopped on server shutdown, have finished.
+func NewServer(cfg config.Config) (*http.Server, <-chan struct{}) {
 	NewServer := &Server{
-		port: port,
+		port:       cfg.HTTP.Port,
+		adminToken: cfg.Admin.Token,
 
-		db: database.New(),
+		db: database.New(cfg.Database),
 	}
 
-	redisAddr := fmt.Sprintf("%s:%s", os.Getenv("REDIS_HOST"), os.Getenv("REDIS_PORT"))
+	redisAddr := cfg.Redis.Addr()
 
 	slog.Debug("Connecting to redis", "addr", redisAddr)
-	gzLevel, _ := strconv.Atoi(os.Getenv("GZIP_LEVEL"))
-	c, err := redis.NewRedisSROCache(redisAddr, os.Getenv("REDIS_PASSWORD"),
+	c, err := redis.NewRedisSROCache(redisAddr, cfg.Redis.Password,
 		protobuf.NewTripsSerializer(),
-		gzip.NewGzipCompressor(gzLevel),
+		gzip.NewGzipCompressor(cfg.Cache.GzipLevel),
 	)
 	if err != nil {
 		panic("couldn't start redis")
 	}
+	c.SetTTLPolicy(redis.TTLPolicy{
+		Default:             cfg.Cache.TTL.Default,
+		NearDeparture:       cfg.Cache.TTL.NearDeparture,
+		NearDepartureWithin: cfg.Cache.TTL.NearDepartureWithin,
+	})
+
+	if cfg.Database.MigrateOnStart {
+		migrateCtx, cancel := context.WithTimeout(context.Background(), cfg.Database.MigrateTimeout)
+		_, err := database.Migrate(migrateCtx, NewServer.db.DB())
+		cancel()
+		if err != nil {
+			panic(fmt.Sprintf("couldn't migrate database: %s", err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	banRepo := postgres.NewBanRepository(NewServer.db.DB())
+	bans := service.NewBanService(banRepo)
+
+	pricingRepo := postgres.NewPricingRepository(NewServer.db.DB())
+	pricingRules := service.NewPricingService(pricingRepo)
+
+	fxRepo := postgres.NewFXRepository(NewServer.db.DB())
+	rates := service.NewFXService(fxRepo, fx.Settings{MarginPercent: cfg.FX.MarginPercent, RoundingStep: cfg.FX.RoundingStep})
+	if path := cfg.FX.RatesFile; path != "" {
+		importRates(ctx, rates, path)
+	}
+
+	partnerRepo := postgres.NewPartnerRepository(NewServer.db.DB())
+	partners := service.NewPartnerService(partnerRepo)
+
+	orderRepo := postgres.NewOrderRepository(NewServer.db.DB())
+
+	searchLogRepo := postgres.NewSearchLogRepository(NewServer.db.DB())
+	searchAnalytics := service.NewSearchAnalytics(searchLogRepo, cfg.SearchLog.QueueSize)
+
+	limiterStore, err := redis.NewRedisRateLimiter(redisAddr, cfg.Redis.Password)
+	if err != nil {
+		panic("couldn't start redis")
+	}
+	limiter := service.NewRateLimiter(limiterStore, ratelimit.Limits{
+		RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
+		SearchesPerMinute: cfg.RateLimit.SearchesPerMinute,
+		DailySearches:     cfg.RateLimit.DailySearches,
+	})
 
 	svc := service.NewMultipleSearchService(c)
-	svc.AddProviderService(providers.NewStubGDS(5))
-	svc.AddProviderService(providers.NewStubGDS(1))
+	for _, p := range cfg.Providers.Production {
+		svc.AddProviderService(newProvider(p))
+	}
+	for _, p := range cfg.Providers.Sandbox {
+		svc.AddSandboxProvider(newProvider(p))
+	}
+	svc.SetTestCache(c.Namespace("test"))
+	svc.SetBanList(bans)
+	svc.SetPricing(pricingRules)
+	svc.SetCurrencyConversion(rates)
+	svc.SetRateLimiter(limiter)
+	svc.SetSearchLog(searchAnalytics)
+	svc.SetSearchTimeout(cfg.Search.Timeout)
+	svc.SetRoundTripSplitting(cfg.Search.RoundTripSplitPairs)
+	if vi := cfg.Search.VirtualInterline; len(vi.Partners) > 0 {
+		svc.SetVirtualInterlining(service.VirtualInterlining{
+			Partners: vi.Partners,
+			Hubs:     vi.Hubs,
+		})
+	}
+
+	// The API can't serve without Postgres or without any provider: searches
+	// go on without the cache, with rate limits off and with the other
+	// providers.
+	checks := health.NewRegistry(cfg.Health.Timeout)
+	checks.Register("postgres", true, health.CheckerFunc(NewServer.db.Health))
+	checks.Register("redis", false, health.Ping(c.Ping))
+	checks.Register("metrics", false, health.CheckerFunc(metrics.Health))
+	svc.RegisterHealthChecks(checks)
+	NewServer.health = checks
 
 	// Declare Server config
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", NewServer.port),
-		Handler:      NewServer.RegisterRoutes(svc),
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		Handler:      NewServer.RegisterRoutes(svc, bans, pricingRules, rates, partners, limiter, service.NewOrderService(orderRepo, svc), searchAnalytics),
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+	}
+
+	// The workers stop together with the HTTP server.
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	expiry := service.NewExpiryWorker(postgres.NewAdvisoryLock(NewServer.db.DB(), cfg.Orders.ExpiryLockKey), orderRepo, svc)
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		expiry.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		searchAnalytics.Run(workerCtx)
+	}()
+	server.RegisterOnShutdown(stopWorker)
+
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+
+	return server, stopped
+}
+
+// newProvider returns the provider of the config, validated to have a unique
+// name and a known type and GDS.
+func newProvider(p config.Provider) *providers.StubGDS {
+	gds, err := providers.NewStubGDS(p.Name, p.GDS, p.Delay)
+	if err != nil {
+		panic(fmt.Sprintf("couldn't create provider: %s", err))
+	}
+	return gds
+}
+
+// importRates loads exchange rates from a CSV file. The server starts without
+// them if the file can't be read, rates can still be added through the API.
+func importRates(ctx context.Context, rates *service.FXService, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		slog.Error("Couldn't open exchange rates file", "path", path, "error", err)
+		return
 	}
+	defer f.Close()
 
-	return server
+	n, err := rates.Import(ctx, f)
+	if err != nil {
+		slog.Error("Couldn't import exchange rates", "path", path, "error", err)
+		return
+	}
+	slog.Info("Exchange rates imported", "path", path, "count", n)
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ban"
+)
+
+const banRefreshInterval = 30 * time.Second
+
+type banRepository interface {
+	List(context.Context) ([]ban.Ban, error)
+	ListActive(context.Context, time.Time) ([]ban.Ban, error)
+	Create(context.Context, ban.Ban) (ban.Ban, error)
+	Delete(context.Context, int64) error
+}
+
+// BanService keeps an in-memory copy of active bans, refreshed from the
+// repository at most every banRefreshInterval or right after a change. If a
+// refresh fails the bans loaded before keep being used.
+type BanService struct {
+	repo   banRepository
+	active *snapshot[[]ban.Ban]
+}
+
+func NewBanService(repo banRepository) *BanService {
+	return &BanService{
+		repo: repo,
+		active: newSnapshot("bans", banRefreshInterval, func(ctx context.Context) ([]ban.Ban, error) {
+			return repo.ListActive(ctx, time.Now())
+		}),
+	}
+}
+
+func (s *BanService) List(ctx context.Context) ([]ban.Ban, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *BanService) Create(ctx context.Context, b ban.Ban) (ban.Ban, error) {
+	if err := b.Validate(); err != nil {
+		return ban.Ban{}, err
+	}
+
+	b, err := s.repo.Create(ctx, b)
+	if err != nil {
+		return ban.Ban{}, err
+	}
+	s.active.invalidate()
+	return b, nil
+}
+
+func (s *BanService) Delete(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.active.invalidate()
+	return nil
+}
+
+// ForPartner returns the bans applying to partnerCode right now.
+func (s *BanService) ForPartner(ctx context.Context, partnerCode string) (*ban.List, error) {
+	active, err := s.active.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ban.NewList(active, partnerCode, time.Now()), nil
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ban"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+type fakeBanRepository struct {
+	bans  []ban.Ban
+	err   error
+	loads int
+}
+
+func (r *fakeBanRepository) List(context.Context) ([]ban.Ban, error) {
+	return r.bans, r.err
+}
+
+func (r *fakeBanRepository) ListActive(context.Context, time.Time) ([]ban.Ban, error) {
+	r.loads++
+	return r.bans, r.err
+}
+
+func (r *fakeBanRepository) Create(_ context.Context, b ban.Ban) (ban.Ban, error) {
+	b.ID = int64(len(r.bans) + 1)
+	r.bans = append(r.bans, b)
+	return b, nil
+}
+
+func (r *fakeBanRepository) Delete(context.Context, int64) error {
+	return nil
+}
+
+func TestBanService_ForPartner(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeBanRepository{}
+	svc := service.NewBanService(repo)
+
+	if l, err := svc.ForPartner(ctx, "AKV4"); err != nil || l.Len() != 0 {
+		t.Fatalf("ForPartner() = %v, %v, want no bans", l, err)
+	}
+
+	// New bans apply right away.
+	if _, err := svc.Create(ctx, ban.Ban{Type: ban.TypeCarrier, Value: "S7", Reason: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := svc.ForPartner(ctx, "AKV4"); err != nil || l.Len() != 1 {
+		t.Fatalf("ForPartner() = %v, %v, want the created ban", l, err)
+	}
+
+	// Loaded bans are kept while the repository fails.
+	repo.err = errors.New("connection refused")
+	svc.Delete(ctx, 1)
+	if l, err := svc.ForPartner(ctx, "AKV4"); err != nil || l.Len() != 1 {
+		t.Fatalf("ForPartner() = %v, %v, want the loaded ban", l, err)
+	}
+	loads := repo.loads
+	if _, err := svc.ForPartner(ctx, "AKV4"); err != nil || repo.loads != loads {
+		t.Errorf("ForPartner() reloaded %d times after a failure, want 0", repo.loads-loads)
+	}
+
+	// Nothing to fall back to.
+	if _, err := service.NewBanService(repo).ForPartner(ctx, "AKV4"); err == nil {
+		t.Error("ForPartner() error = nil, want the repository error")
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_Bans(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20241015"
+
+	repo := &fakeBanRepository{bans: []ban.Ban{
+		{ID: 1, Type: ban.TypeRoute, Value: "MOWLED", Reason: "test"},
+	}}
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{})
+	svc.SetBanList(service.NewBanService(repo))
+
+	ts, err := svc.SearchByToken(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Count() != 0 {
+		t.Errorf("SearchByToken() returned %d trips, want every trip banned", ts.Count())
+	}
+
+	// Unfiltered results are never served nor cached.
+	repo.err = errors.New("connection refused")
+	c := newFakeCache()
+	svc = service.NewMultipleSearchService(c)
+	svc.AddProviderService(&fakeProvider{})
+	svc.SetBanList(service.NewBanService(repo))
+	if _, err := svc.SearchByToken(context.Background(), token); err == nil {
+		t.Error("SearchByToken() error = nil, want ban-list error")
+	}
+	if keys := c.keys(); len(keys) != 0 {
+		t.Errorf("cached %v, want nothing", keys)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/de4et/flight-booking/internal/model/order"
+)
+
+var (
+	ErrBookingUnsupported = errors.New("trip can't be booked")
+	ErrCancelUnsupported  = errors.New("booking can't be cancelled at the provider")
+)
+
+// booker is implemented by providers that can book a trip they found. Book
+// returns the provider booking reference (PNR).
+type booker interface {
+	Book(context.Context, order.Order) (string, error)
+}
+
+// canceller is implemented by providers that can cancel a booking. Cancelling
+// an already cancelled booking must succeed, so retries are safe.
+type canceller interface {
+	Cancel(context.Context, order.Order) error
+}
+
+// Book books the order trip at the provider that found it.
+func (svc *MultipleSearchService) Book(ctx context.Context, o order.Order) (string, error) {
+	// Virtual trips consist of separate tickets and have no single provider.
+	if !o.Trip.IsBookable() {
+		return "", fmt.Errorf("%w: virtual trip", ErrBookingUnsupported)
+	}
+
+	b, ok := svc.providerOf(o.Provider, o.IsTest).(booker)
+	if !ok {
+		return "", fmt.Errorf("%w: not supported by %s", ErrBookingUnsupported, o.Trip.Provider.Name)
+	}
+
+	ref, err := b.Book(ctx, o)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrProviderFailed, o.Trip.Provider.Name, err)
+	}
+	return ref, nil
+}
+
+// Cancel cancels the order booking at the provider that holds it.
+func (svc *MultipleSearchService) Cancel(ctx context.Context, o order.Order) error {
+	c, ok := svc.providerOf(o.Provider, o.IsTest).(canceller)
+	if !ok {
+		return fmt.Errorf("%w: not supported by %s", ErrCancelUnsupported, o.Trip.Provider.Name)
+	}
+
+	if err := c.Cancel(ctx, o); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrProviderFailed, o.Trip.Provider.Name, err)
+	}
+	return nil
+}
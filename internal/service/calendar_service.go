@@ -0,0 +1,193 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+const (
+	MaxCalendarDays     = 3
+	calendarConcurrency = 4
+	calendarDateLayout  = "2006-01-02"
+)
+
+var ErrCalendarUnsupported = errors.New("price calendar supports only OW and RT searches")
+
+type tokenSearcher interface {
+	SearchByToken(context.Context, string) (*trip.Trips, error)
+}
+
+// PriceCalendar is a matrix of the minimum prices, Prices[i][j] is the price
+// for OutboundDates[i] and ReturnDates[j]. OW calendars have a single column
+// and no return dates. Dates without results are nil, the ones whose search
+// failed are listed in Errors.
+type PriceCalendar struct {
+	OutboundDates []string        `json:"outboundDates"`
+	ReturnDates   []string        `json:"returnDates"`
+	Prices        [][]*float64    `json:"prices"`
+	Errors        []CalendarError `json:"errors,omitempty"`
+}
+
+// CalendarError is a date combination that couldn't be searched.
+type CalendarError struct {
+	OutboundDate string `json:"outboundDate"`
+	ReturnDate   string `json:"returnDate,omitempty"`
+	Error        string `json:"error"`
+}
+
+type CalendarService struct {
+	searcher tokenSearcher
+	now      func() time.Time
+}
+
+func NewCalendarService(searcher tokenSearcher) *CalendarService {
+	return &CalendarService{
+		searcher: searcher,
+		now:      time.Now,
+	}
+}
+
+// SetClock sets the source of the current time, dates before it are not
+// searched.
+func (svc *CalendarService) SetClock(now func() time.Time) {
+	svc.now = now
+}
+
+// Search runs the SRO for every date combination within ±days of the
+// requested dates and collects the cheapest price of each. Each date is a
+// search of its own, so running out of the partner's searches or being
+// forbidden one fails the whole calendar instead of leaving gaps.
+func (svc *CalendarService) Search(ctx context.Context, token string, days int) (*PriceCalendar, error) {
+	s, err := sro.FromToken(token)
+	if err != nil {
+		return nil, ErrInvalidSRO
+	}
+	if err := authorize(ctx, s); err != nil {
+		return nil, err
+	}
+	if days < 0 || days > MaxCalendarDays {
+		return nil, fmt.Errorf("%w: days must be between 0 and %d", ErrInvalidSRO, MaxCalendarDays)
+	}
+	if !(s.IsOW() && len(s.Segments) == 1) && !(s.IsRT() && len(s.Segments) == 2) {
+		return nil, ErrCalendarUnsupported
+	}
+
+	outbound := dateRange(s.Segments[0].Date, days)
+	var inbound []time.Time
+	if s.IsRT() {
+		inbound = dateRange(s.Segments[1].Date, days)
+	}
+
+	cal := &PriceCalendar{
+		OutboundDates: formatDates(outbound),
+		ReturnDates:   formatDates(inbound),
+		Prices:        make([][]*float64, len(outbound)),
+	}
+
+	y, m, d := svc.now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	sem := make(chan struct{}, calendarConcurrency)
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+
+	for i, out := range outbound {
+		cal.Prices[i] = make([]*float64, max(len(inbound), 1))
+		if out.Before(today) {
+			continue
+		}
+
+		for j := range cal.Prices[i] {
+			failure := CalendarError{OutboundDate: cal.OutboundDates[i]}
+			dated := *s
+			dated.Segments = []sro.Segment{{From: s.Segments[0].From, To: s.Segments[0].To, Date: out}}
+			if s.IsRT() {
+				if inbound[j].Before(out) {
+					continue
+				}
+				dated.Segments = append(dated.Segments, sro.Segment{From: s.Segments[1].From, To: s.Segments[1].To, Date: inbound[j]})
+				failure.ReturnDate = cal.ReturnDates[j]
+			}
+
+			wg.Add(1)
+			go func(cell **float64, token string) {
+				defer wg.Done()
+
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					return
+				}
+
+				ts, err := svc.searcher.SearchByToken(ctx, token)
+				switch {
+				case err == nil:
+					*cell = minPrice(ts)
+				case errors.Is(err, ratelimit.ErrLimited) || errors.Is(err, partner.ErrForbidden):
+					cancel(err)
+				case ctx.Err() == nil:
+					slog.WarnContext(ctx, "Calendar date search failed", "token", token, "error", err)
+					failure.Error = err.Error()
+					mu.Lock()
+					cal.Errors = append(cal.Errors, failure)
+					mu.Unlock()
+				}
+			}(&cal.Prices[i][j], dated.GetToken())
+		}
+	}
+
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+
+	slices.SortFunc(cal.Errors, func(a, b CalendarError) int {
+		return cmp.Or(strings.Compare(a.OutboundDate, b.OutboundDate), strings.Compare(a.ReturnDate, b.ReturnDate))
+	})
+	return cal, nil
+}
+
+func dateRange(center time.Time, days int) []time.Time {
+	dates := make([]time.Time, 0, 2*days+1)
+	for d := -days; d <= days; d++ {
+		dates = append(dates, center.AddDate(0, 0, d))
+	}
+	return dates
+}
+
+func formatDates(dates []time.Time) []string {
+	res := make([]string, 0, len(dates))
+	for _, d := range dates {
+		res = append(res, d.Format(calendarDateLayout))
+	}
+	return res
+}
+
+func minPrice(ts *trip.Trips) *float64 {
+	if ts == nil || ts.IsEmpty() {
+		return nil
+	}
+
+	trips := ts.ToArray()
+	price := trips[0].GetPrice()
+	for i := range trips {
+		price = min(price, trips[i].GetPrice())
+	}
+	return &price
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// datePricedSearcher prices trips by outbound day of month plus return day.
+// Searches departing on a day of errs fail with its error.
+type datePricedSearcher struct {
+	errs map[int]error
+}
+
+func (ds datePricedSearcher) SearchByToken(_ context.Context, token string) (*trip.Trips, error) {
+	s, err := sro.FromToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.errs[s.Segments[0].Date.Day()]; err != nil {
+		return nil, err
+	}
+
+	price := float64(s.Segments[0].Date.Day())
+	if s.IsRT() {
+		price += float64(s.Segments[1].Date.Day()) / 100
+	}
+
+	ts := trip.NewTrips()
+	ts.AddTrip(trip.Trip{CacheID: "cheap", Prices: trip.TripPrices{Price: price}})
+	ts.AddTrip(trip.Trip{CacheID: "expensive", Prices: trip.TripPrices{Price: price + 1000}})
+	return ts, nil
+}
+
+func price(v float64) *float64 {
+	return &v
+}
+
+func TestCalendarService_Search(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		days    int
+		errs    map[int]error
+		want    *service.PriceCalendar
+		wantErr error
+	}{
+		{
+			name:  "OW",
+			token: "AKV40000OWE1000000091MOWLED20241015",
+			days:  1,
+			want: &service.PriceCalendar{
+				OutboundDates: []string{"2024-10-14", "2024-10-15", "2024-10-16"},
+				ReturnDates:   []string{},
+				Prices:        [][]*float64{{price(14)}, {price(15)}, {price(16)}},
+			},
+		},
+		{
+			name:  "RT skips returns before departure",
+			token: "AKV40000RTE1000000091MOWLED20241015LEDMOW20241016",
+			days:  1,
+			want: &service.PriceCalendar{
+				OutboundDates: []string{"2024-10-14", "2024-10-15", "2024-10-16"},
+				ReturnDates:   []string{"2024-10-15", "2024-10-16", "2024-10-17"},
+				Prices: [][]*float64{
+					{price(14.15), price(14.16), price(14.17)},
+					{price(15.15), price(15.16), price(15.17)},
+					{nil, price(16.16), price(16.17)},
+				},
+			},
+		},
+		{
+			name:  "skips past dates",
+			token: "AKV40000OWE1000000091MOWLED20241010",
+			days:  1,
+			want: &service.PriceCalendar{
+				OutboundDates: []string{"2024-10-09", "2024-10-10", "2024-10-11"},
+				ReturnDates:   []string{},
+				Prices:        [][]*float64{{nil}, {price(10)}, {price(11)}},
+			},
+		},
+		{
+			name:  "reports failed dates",
+			token: "AKV40000RTE1000000091MOWLED20241015LEDMOW20241016",
+			days:  1,
+			errs:  map[int]error{15: service.ErrProviderFailed},
+			want: &service.PriceCalendar{
+				OutboundDates: []string{"2024-10-14", "2024-10-15", "2024-10-16"},
+				ReturnDates:   []string{"2024-10-15", "2024-10-16", "2024-10-17"},
+				Prices: [][]*float64{
+					{price(14.15), price(14.16), price(14.17)},
+					{nil, nil, nil},
+					{nil, price(16.16), price(16.17)},
+				},
+				Errors: []service.CalendarError{
+					{OutboundDate: "2024-10-15", ReturnDate: "2024-10-15", Error: service.ErrProviderFailed.Error()},
+					{OutboundDate: "2024-10-15", ReturnDate: "2024-10-16", Error: service.ErrProviderFailed.Error()},
+					{OutboundDate: "2024-10-15", ReturnDate: "2024-10-17", Error: service.ErrProviderFailed.Error()},
+				},
+			},
+		},
+		{
+			name:    "rate limited",
+			token:   "AKV40000OWE1000000091MOWLED20241015",
+			days:    1,
+			errs:    map[int]error{16: &ratelimit.Exceeded{Kind: ratelimit.KindDailySearches, RetryAfter: time.Hour}},
+			wantErr: ratelimit.ErrLimited,
+		},
+		{
+			name:    "too many days",
+			token:   "AKV40000OWE1000000091MOWLED20241015",
+			days:    service.MaxCalendarDays + 1,
+			wantErr: service.ErrInvalidSRO,
+		},
+		{
+			name:    "CX",
+			token:   "AKV40000CXE1000000091MOWLED20241015LEDAER20241020",
+			days:    1,
+			wantErr: service.ErrCalendarUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewCalendarService(datePricedSearcher{errs: tt.errs})
+			svc.SetClock(func() time.Time { return time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC) })
+
+			got, err := svc.Search(context.Background(), tt.token, tt.days)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Search() error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/order"
+)
+
+const (
+	DefaultExpiryInterval = time.Minute
+	// DefaultExpiryMargin is how long before the time limit orders are
+	// expired, so they are cancelled before the provider tickets or drops them.
+	DefaultExpiryMargin = 5 * time.Minute
+	// DefaultExpiryGrace is how old created orders must be to be expired, so
+	// orders being booked right now are left to the order service.
+	DefaultExpiryGrace = 10 * time.Minute
+
+	expiryBatchSize = 100
+	expiryReason    = "time limit passed"
+)
+
+type leaderLock interface {
+	TryAcquire(context.Context) (bool, error)
+	Release(context.Context) error
+}
+
+type expiringOrders interface {
+	ListExpiring(ctx context.Context, before, createdBefore time.Time, limit int) ([]order.Order, error)
+	UpdateStatus(context.Context, *order.Order, order.Status, string) error
+}
+
+type bookingCanceller interface {
+	Cancel(context.Context, order.Order) error
+}
+
+// ExpiryWorker cancels unpaid bookings whose ticketing time limit passes and
+// moves them to expired. Only the instance holding the lock does the work.
+// Orders are claimed as expiring before the provider is called, so they
+// can't be ticketed meanwhile. Every step is safe to repeat, so orders left
+// half-way by a restart or a provider failure are picked up on the next scan.
+type ExpiryWorker struct {
+	lock     leaderLock
+	repo     expiringOrders
+	bookings bookingCanceller
+
+	interval time.Duration
+	margin   time.Duration
+	grace    time.Duration
+}
+
+func NewExpiryWorker(lock leaderLock, repo expiringOrders, bookings bookingCanceller) *ExpiryWorker {
+	return &ExpiryWorker{
+		lock:     lock,
+		repo:     repo,
+		bookings: bookings,
+		interval: DefaultExpiryInterval,
+		margin:   DefaultExpiryMargin,
+		grace:    DefaultExpiryGrace,
+	}
+}
+
+// Run scans orders every interval until ctx is done.
+func (w *ExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	defer func() {
+		if err := w.lock.Release(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Couldn't release expiry worker lock", "error", err)
+		}
+	}()
+
+	for {
+		w.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ExpiryWorker) tick(ctx context.Context) {
+	leader, err := w.lock.TryAcquire(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Couldn't take expiry worker lock", "error", err)
+		return
+	}
+	if !leader {
+		return
+	}
+
+	n, err := w.ExpireDue(ctx, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "Expiry scan failed", "error", err)
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "Expired orders", "count", n)
+	}
+}
+
+// ExpireDue expires orders held until before now plus the margin and
+// returns how many were expired. Created orders are only expired once older
+// than the grace period. Orders that fail are left for the next scan.
+func (w *ExpiryWorker) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	orders, err := w.repo.ListExpiring(ctx, now.Add(w.margin), now.Add(-w.grace), expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, o := range orders {
+		ctx := logger.WithContext(ctx, "order.id", o.ID)
+		expired, err := w.expire(ctx, o)
+		if err != nil {
+			slog.WarnContext(ctx, "Couldn't expire order", "error", err)
+			continue
+		}
+		if expired {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (w *ExpiryWorker) expire(ctx context.Context, o order.Order) (bool, error) {
+	if o.Status != order.StatusExpiring {
+		from := o.Status
+		if err := o.Transition(order.StatusExpiring); err != nil {
+			return false, err
+		}
+		err := w.repo.UpdateStatus(ctx, &o, from, expiryReason)
+		if errors.Is(err, order.ErrStatusChanged) {
+			slog.DebugContext(ctx, "Order changed while expiring, skipping")
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("claiming order: %w", err)
+		}
+	}
+
+	// Only orders booked at the provider have a reference to cancel.
+	if o.BookingReference != "" {
+		err := w.bookings.Cancel(ctx, o)
+		if errors.Is(err, ErrCancelUnsupported) {
+			slog.WarnContext(ctx, "Provider can't cancel booking, expiring it locally", "provider", o.Provider)
+		} else if err != nil {
+			return false, err
+		}
+	}
+
+	if err := o.Transition(order.StatusExpired); err != nil {
+		return false, err
+	}
+	if err := w.repo.UpdateStatus(ctx, &o, order.StatusExpiring, expiryReason); err != nil {
+		return false, fmt.Errorf("updating order: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Order expired", "bookingReference", o.BookingReference)
+	return true, nil
+}
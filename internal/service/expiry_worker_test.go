@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+type fakeLock struct {
+	leader bool
+}
+
+func (l *fakeLock) TryAcquire(context.Context) (bool, error) { return l.leader, nil }
+func (l *fakeLock) Release(context.Context) error            { return nil }
+
+type fakeCanceller struct {
+	mu        sync.Mutex
+	cancelled []int64
+	err       error
+}
+
+func (c *fakeCanceller) Cancel(_ context.Context, o order.Order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.cancelled = append(c.cancelled, o.ID)
+	return nil
+}
+
+func TestExpiryWorker_ExpireDue(t *testing.T) {
+	now := time.Date(2030, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	held := func(status order.Status, limit time.Time) order.Order {
+		o := order.Order{
+			Status:    status,
+			Trip:      trip.Trip{Booking: trip.TripBooking{TicketingTimeLimit: limit, ExpiresAt: limit.Add(time.Hour)}},
+			CreatedAt: now.Add(-time.Hour),
+		}
+		if status != order.StatusCreated {
+			o.BookingReference = "PNR"
+		}
+		return o
+	}
+	fresh := held(order.StatusCreated, now.Add(-time.Minute))
+	fresh.CreatedAt = now.Add(-time.Minute) // may be being booked
+
+	tests := []struct {
+		name          string
+		cancelErr     error
+		wantExpired   int
+		wantStatuses  []order.Status
+		wantCancelled []int64
+	}{
+		{
+			name:          "expires due orders",
+			wantExpired:   2,
+			wantStatuses:  []order.Status{order.StatusExpired, order.StatusExpired, order.StatusBooked, order.StatusTicketed, order.StatusCreated},
+			wantCancelled: []int64{1},
+		},
+		{
+			name:          "provider can't cancel",
+			cancelErr:     service.ErrCancelUnsupported,
+			wantExpired:   2,
+			wantStatuses:  []order.Status{order.StatusExpired, order.StatusExpired, order.StatusBooked, order.StatusTicketed, order.StatusCreated},
+			wantCancelled: nil,
+		},
+		{
+			name:        "provider failed",
+			cancelErr:   errors.New("timeout"),
+			wantExpired: 1,
+			// The order stays claimed and is retried on the next scan.
+			wantStatuses:  []order.Status{order.StatusExpiring, order.StatusExpired, order.StatusBooked, order.StatusTicketed, order.StatusCreated},
+			wantCancelled: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepository()
+			for _, o := range []order.Order{
+				held(order.StatusBooked, now.Add(-time.Minute)),
+				held(order.StatusCreated, now.Add(3*time.Minute)), // within the margin
+				held(order.StatusBooked, now.Add(time.Hour)),
+				held(order.StatusTicketed, now.Add(-time.Hour)),
+				fresh,
+			} {
+				if _, err := repo.Create(context.Background(), o); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			bookings := &fakeCanceller{err: tt.cancelErr}
+			w := service.NewExpiryWorker(&fakeLock{leader: true}, repo, bookings)
+
+			n, err := w.ExpireDue(context.Background(), now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.wantExpired {
+				t.Errorf("ExpireDue() = %d, want %d", n, tt.wantExpired)
+			}
+
+			for i, want := range tt.wantStatuses {
+				o, _ := repo.Get(context.Background(), int64(i+1))
+				if o.Status != want {
+					t.Errorf("order %d status = %s, want %s", i+1, o.Status, want)
+				}
+			}
+			if len(bookings.cancelled) != len(tt.wantCancelled) {
+				t.Errorf("cancelled %v, want %v", bookings.cancelled, tt.wantCancelled)
+			}
+
+			events, _ := repo.Events(context.Background(), 2)
+			if len(events) != 2 || events[0].From != order.StatusCreated || events[1].To != order.StatusExpired {
+				t.Errorf("order 2 events = %+v, want created -> expiring -> expired", events)
+			}
+
+			// A repeated scan doesn't touch expired orders again.
+			bookings.err = nil
+			before := len(bookings.cancelled)
+			if _, err := w.ExpireDue(context.Background(), now); err != nil {
+				t.Fatal(err)
+			}
+			if events, _ := repo.Events(context.Background(), 2); len(events) != 2 {
+				t.Errorf("order 2 has %d events after a repeated scan, want 2", len(events))
+			}
+			if tt.cancelErr == nil && len(bookings.cancelled) != before {
+				t.Errorf("repeated scan cancelled %v again", bookings.cancelled[before:])
+			}
+			if o, _ := repo.Get(context.Background(), 1); o.Status != order.StatusExpired {
+				t.Errorf("order 1 status after a repeated scan = %s, want %s", o.Status, order.StatusExpired)
+			}
+		})
+	}
+}
+
+func TestExpiryWorker_Run_NotLeader(t *testing.T) {
+	repo := newFakeOrderRepository()
+	o := order.Order{
+		Status: order.StatusBooked,
+		Trip:   trip.Trip{Booking: trip.TripBooking{TicketingTimeLimit: time.Now().Add(-time.Hour)}},
+	}
+	if _, err := repo.Create(context.Background(), o); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	service.NewExpiryWorker(&fakeLock{leader: false}, repo, &fakeCanceller{}).Run(ctx)
+
+	if o, _ := repo.Get(context.Background(), 1); o.Status != order.StatusBooked {
+		t.Errorf("order status = %s, want %s without the lock", o.Status, order.StatusBooked)
+	}
+}
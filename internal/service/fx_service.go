@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+)
+
+const fxRefreshInterval = time.Minute
+
+type fxRepository interface {
+	List(context.Context) ([]fx.Rate, error)
+	Save(context.Context, fx.Rate) (fx.Rate, error)
+}
+
+// FXService keeps an in-memory copy of exchange rates, reloaded from the
+// repository at most every fxRefreshInterval or right after a change. If a
+// reload fails the rates loaded before keep being used.
+type FXService struct {
+	repo     fxRepository
+	settings fx.Settings
+	rates    *snapshot[[]fx.Rate]
+}
+
+func NewFXService(repo fxRepository, settings fx.Settings) *FXService {
+	return &FXService{
+		repo:     repo,
+		settings: settings,
+		rates:    newSnapshot("exchange rates", fxRefreshInterval, repo.List),
+	}
+}
+
+func (s *FXService) List(ctx context.Context) ([]fx.Rate, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *FXService) Save(ctx context.Context, rate fx.Rate) (fx.Rate, error) {
+	if err := rate.Validate(); err != nil {
+		return fx.Rate{}, err
+	}
+
+	rate, err := s.repo.Save(ctx, rate)
+	if err != nil {
+		return fx.Rate{}, err
+	}
+	s.rates.invalidate()
+	return rate, nil
+}
+
+// Import saves rates read from CSV and returns how many were saved.
+func (s *FXService) Import(ctx context.Context, r io.Reader) (int, error) {
+	rates, err := fx.ParseCSV(r)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, rate := range rates {
+		if _, err := s.repo.Save(ctx, rate); err != nil {
+			return i, fmt.Errorf("saving %s to %s: %w", rate.From, rate.To, err)
+		}
+	}
+	s.rates.invalidate()
+	return len(rates), nil
+}
+
+// Converter returns a converter using the rates in effect right now.
+func (s *FXService) Converter(ctx context.Context) (*fx.Converter, error) {
+	rates, err := s.rates.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fx.NewConverter(fx.NewRates(rates, time.Now()), s.settings), nil
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+type fakeFXRepository struct {
+	rates []fx.Rate
+	err   error
+}
+
+func (r *fakeFXRepository) List(context.Context) ([]fx.Rate, error) {
+	return r.rates, r.err
+}
+
+func (r *fakeFXRepository) Save(_ context.Context, rate fx.Rate) (fx.Rate, error) {
+	rate.ID = int64(len(r.rates) + 1)
+	r.rates = append(r.rates, rate)
+	return rate, nil
+}
+
+func TestMultipleSearchService_SearchByToken_Currency(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &fakeFXRepository{}
+	rates := service.NewFXService(repo, fx.Settings{MarginPercent: 2})
+	if _, err := rates.Import(ctx, strings.NewReader("USD,RUB,100,2024-01-01\nEUR,RUB,120,2999-01-01")); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{currency: "RUB"})
+	svc.SetPricing(service.NewPricingService(&fakePricingRepository{rules: []pricing.Rule{
+		{ID: 1, Name: "markup", MarkupPercent: 10},
+	}}))
+	svc.SetCurrencyConversion(rates)
+
+	ts, err := svc.SearchByToken(ctx, "AKV40000OWE1000000091MOWLED20241015_USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := ts.Get("trip_0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 RUB marked up to 110 RUB, at 0.01 USD per RUB plus 2%.
+	p := tr.Prices
+	if p.Currency != "USD" || p.Price != 1.13 || p.SearchPrice != 1.02 {
+		t.Errorf("trip price = %v %s from %v, want 1.13 USD from 1.02", p.Price, p.Currency, p.SearchPrice)
+	}
+	if p.Original == nil || p.Original.Price != 110 || p.Original.Currency != "RUB" || p.Original.PricerInfo.RuleID != 1 {
+		t.Errorf("original prices = %+v, want priced RUB amounts", p.Original)
+	}
+
+	// Trips are removed rather than shown in the provider currency.
+	ts, err = svc.SearchByToken(ctx, "AKV40000OWE1000000091MOWLED20241015_EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ts.IsEmpty() {
+		t.Errorf("got %d trips without a rate in effect, want none", ts.Count())
+	}
+
+	// The search fails without rates to convert with.
+	svc = service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{currency: "RUB"})
+	svc.SetCurrencyConversion(service.NewFXService(&fakeFXRepository{err: errors.New("connection refused")}, fx.Settings{}))
+	if _, err := svc.SearchByToken(ctx, "AKV40000OWE1000000091MOWLED20241015_USD"); err == nil {
+		t.Error("SearchByToken() error = nil, want exchange rates error")
+	}
+}
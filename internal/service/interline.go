@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/airport"
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+const (
+	DefaultMinConnection = 3 * time.Hour
+	DefaultMaxConnection = 24 * time.Hour
+
+	// maxInterlineCombinations limits self-transfer itineraries per hub.
+	maxInterlineCombinations = 20
+
+	// maxInterlineHubs limits the hubs tried per search, every one costs up
+	// to three searches of the partner.
+	maxInterlineHubs = 2
+)
+
+// VirtualInterlining builds self-transfer itineraries for OW searches out of
+// separate one-way tickets to and from hub airports. Both tickets must meet
+// at the same airport, with a connection between MinConnection and
+// MaxConnection long.
+type VirtualInterlining struct {
+	Partners      []string
+	Hubs          []string
+	MinConnection time.Duration
+	MaxConnection time.Duration
+}
+
+func (vi *VirtualInterlining) enabledFor(s *sro.SRO) bool {
+	return vi != nil && s.IsOW() && len(s.Segments) == 1 && !s.Filters.IsDirectOnly &&
+		slices.Contains(vi.Partners, s.ChannelToken.PartnerCode)
+}
+
+// hubsFor returns up to maxInterlineHubs hubs that make sense for the
+// segment, i.e. not in the origin or destination city, in the configured
+// order.
+func (vi *VirtualInterlining) hubsFor(seg sro.Segment) []string {
+	dir := airport.Default()
+
+	var hubs []string
+	for _, hub := range vi.Hubs {
+		if strings.EqualFold(hub, seg.From) || strings.EqualFold(hub, seg.To) ||
+			dir.SameCity(hub, seg.From) || dir.SameCity(hub, seg.To) {
+			continue
+		}
+		if hubs = append(hubs, hub); len(hubs) == maxInterlineHubs {
+			break
+		}
+	}
+	return hubs
+}
+
+func (vi *VirtualInterlining) connects(parts []trip.Trip) bool {
+	prev, next := parts[0].Segments, parts[1].Segments
+	if len(prev) == 0 || len(next) == 0 {
+		return false
+	}
+
+	// Self-transfers don't include ground transfers between airports.
+	arrival, departure := prev[len(prev)-1].Arrival, next[0].Departure
+	if arrival.Airport != departure.Airport || arrival.Time.IsZero() || departure.Time.IsZero() {
+		return false
+	}
+
+	gap := departure.Time.Sub(arrival.Time)
+	return gap >= vi.MinConnection && gap <= vi.MaxConnection
+}
+
+func (svc *MultipleSearchService) SetVirtualInterlining(vi VirtualInterlining) {
+	if vi.MinConnection == 0 {
+		vi.MinConnection = DefaultMinConnection
+	}
+	if vi.MaxConnection == 0 {
+		vi.MaxConnection = DefaultMaxConnection
+	}
+	vi.Partners = codeList(vi.Partners)
+	vi.Hubs = codeList(vi.Hubs)
+	svc.interlining = &vi
+}
+
+func codeList(codes []string) []string {
+	res := make([]string, 0, len(codes))
+	for _, c := range codes {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// addVirtualInterlines searches both hops via every hub and adds the
+// cheapest self-transfer itineraries to ts. The second hop is only searched
+// when the first one found something.
+func (svc *MultipleSearchService) addVirtualInterlines(ctx context.Context, s *sro.SRO, ts *trip.Trips) {
+	vi := svc.interlining
+	if !vi.enabledFor(s) {
+		return
+	}
+
+	seg := s.Segments[0]
+	hubs := vi.hubsFor(seg)
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, hub := range hubs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			combos := svc.interlineVia(ctx, s, hub)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, t := range combos {
+				ts.AddTrip(t)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (svc *MultipleSearchService) interlineVia(ctx context.Context, s *sro.SRO, hub string) []trip.Trip {
+	seg := s.Segments[0]
+
+	first, err := svc.searchHop(ctx, s, sro.Segment{From: seg.From, To: hub, Date: seg.Date})
+	if err != nil || len(first) == 0 {
+		return nil
+	}
+
+	// The second hop may depart on the next day.
+	hops := []sro.Segment{
+		{From: hub, To: seg.To, Date: seg.Date},
+		{From: hub, To: seg.To, Date: seg.Date.AddDate(0, 0, 1)},
+	}
+
+	results := make([][]trip.Trip, len(hops))
+	wg := sync.WaitGroup{}
+	for i := range hops {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = svc.searchHop(ctx, s, hops[i])
+		}()
+	}
+	wg.Wait()
+
+	second := slices.Concat(results...)
+	combos := trip.CheapestCombinations([][]trip.Trip{first, second}, maxInterlineCombinations, svc.interlining.connects)
+
+	var res []trip.Trip
+	for _, parts := range combos {
+		t := trip.Connect(parts)
+		t.SRO = s
+		if s.Filters.MaxStops > 0 && t.Metadata.NumTransfers > s.Filters.MaxStops {
+			continue
+		}
+		res = append(res, t)
+	}
+
+	slog.DebugContext(ctx, "Built virtual interlines", "hub", hub, "count", len(res))
+	return res
+}
+
+// searchHop searches the segment as an OW search of s. Results of an
+// equivalent search still in the cache are reused, restored to provider
+// prices since they are priced with the itinerary, without their own virtual
+// interlines, which would nest. Other hops reach the providers and count
+// against the partner's search limits.
+func (svc *MultipleSearchService) searchHop(ctx context.Context, s *sro.SRO, seg sro.Segment) ([]trip.Trip, error) {
+	hop := *s
+	hop.Segments = []sro.Segment{seg}
+	key := hop.CanonicalToken()
+
+	if cached, err := svc.cacheFor(&hop).Get(ctx, key); err == nil {
+		res := cached.Filter(func(t *trip.Trip) bool { return !t.Metadata.IsVtrip })
+		for i := range res {
+			fx.Restore(&res[i])
+			pricing.Reset(&res[i])
+		}
+		return res, nil
+	}
+
+	if svc.limiter != nil {
+		if err := svc.limiter.AllowSearch(ctx, hop.ChannelToken.PartnerCode, hop.Metadata.IsTest); err != nil {
+			slog.InfoContext(ctx, "Virtual interline hop not searched", "hop", key, "error", err)
+			return nil, err
+		}
+	}
+
+	ts, err := svc.searchParallel(ctx, hop)
+	if err != nil {
+		slog.WarnContext(ctx, "Virtual interline hop search failed", "hop", key, "error", err)
+		return nil, err
+	}
+	return ts.ToArray(), nil
+}
@@ -0,0 +1,239 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/airport"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type flight struct {
+	id       string
+	from, to string
+	dep      string // "2006-01-02 15:04" in UTC
+	price    float64
+}
+
+// routeProvider returns the flights matching the searched route and date.
+type routeProvider struct {
+	flights []flight
+	calls   atomic.Int32
+}
+
+func (p *routeProvider) Search(_ context.Context, s sro.SRO) (*trip.Trips, error) {
+	p.calls.Add(1)
+	seg := s.Segments[0]
+	dir := airport.Default()
+
+	ts := trip.NewTrips()
+	for _, f := range p.flights {
+		dep, _ := time.Parse("2006-01-02 15:04", f.dep)
+		if dep.Format("20060102") != seg.Date.Format("20060102") {
+			continue
+		}
+		if !dir.SameCity(f.from, seg.From) || !dir.SameCity(f.to, seg.To) {
+			continue
+		}
+		ts.AddTrip(trip.Trip{
+			CacheID: f.id,
+			Prices:  trip.TripPrices{Price: f.price},
+			Segments: []trip.TripSegment{{
+				Carrier:   f.id[:2],
+				Departure: trip.FlightPoint{Airport: f.from, Time: dep},
+				Arrival:   trip.FlightPoint{Airport: f.to, Time: dep.Add(3 * time.Hour)},
+			}},
+		})
+	}
+	return ts, nil
+}
+
+func (p *routeProvider) GetAvailability() bool {
+	return true
+}
+
+var interlineFlights = []flight{
+	{id: "SU100", from: "SVO", to: "LED", dep: "2030-10-15 06:00", price: 500},
+	{id: "TK1", from: "SVO", to: "IST", dep: "2030-10-15 06:00", price: 100},
+	{id: "PC1", from: "SVO", to: "SAW", dep: "2030-10-15 06:00", price: 50},
+	// Lands at 09:00 in IST.
+	{id: "TK2", from: "IST", to: "LED", dep: "2030-10-15 10:00", price: 100}, // connection too short
+	{id: "TK3", from: "IST", to: "LED", dep: "2030-10-15 14:00", price: 150},
+	{id: "PC2", from: "SAW", to: "LED", dep: "2030-10-15 14:00", price: 120},
+	{id: "TK4", from: "IST", to: "LED", dep: "2030-10-16 07:00", price: 90},
+	{id: "TK5", from: "IST", to: "LED", dep: "2030-10-16 12:00", price: 80}, // connection too long
+}
+
+func TestMultipleSearchService_VirtualInterlining(t *testing.T) {
+	p := &routeProvider{flights: interlineFlights}
+
+	newService := func() *service.MultipleSearchService {
+		svc := service.NewMultipleSearchService(newFakeCache())
+		svc.AddProviderService(p)
+		svc.SetVirtualInterlining(service.VirtualInterlining{
+			Partners: []string{"AKV4"},
+			Hubs:     []string{"IST", "LED", "SVO"},
+		})
+		return svc
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  map[string]float64
+	}{
+		{
+			name:  "enabled partner",
+			token: "AKV40000OWE1000000091MOWLED20301015",
+			want: map[string]float64{
+				"SU100":         500,
+				"vtrip_PC1+PC2": 170,
+				"vtrip_TK1+TK4": 190,
+				"vtrip_TK1+TK3": 250,
+			},
+		},
+		{
+			name:  "other partner",
+			token: "BBBB0000OWE1000000091MOWLED20301015",
+			want:  map[string]float64{"SU100": 500},
+		},
+		{
+			name:  "direct only",
+			token: "AKV40000OWE1000001091MOWLED20301015",
+			want:  map[string]float64{"SU100": 500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := newService().SearchByToken(context.Background(), tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]float64)
+			for _, tr := range ts.ToArray() {
+				got[tr.CacheID] = tr.GetPrice()
+				if tr.Metadata.IsVtrip != tr.Metadata.IsVirtualInterline {
+					t.Errorf("trip %s: IsVtrip = %v, IsVirtualInterline = %v", tr.CacheID, tr.Metadata.IsVtrip, tr.Metadata.IsVirtualInterline)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SearchByToken() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMultipleSearchService_VirtualInterlining_HopSearches(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20301015"
+
+	newService := func(p *routeProvider) *service.MultipleSearchService {
+		svc := service.NewMultipleSearchService(newFakeCache())
+		svc.AddProviderService(p)
+		svc.SetVirtualInterlining(service.VirtualInterlining{
+			Partners: []string{"AKV4"},
+			Hubs:     []string{"IST"},
+		})
+		return svc
+	}
+
+	t.Run("cached hops are reused", func(t *testing.T) {
+		p := &routeProvider{flights: interlineFlights}
+		svc := newService(p)
+
+		hops := []string{
+			"AKV40000OWE1000000091MOWIST20301015",
+			"AKV40000OWE1000000091ISTLED20301015",
+			"AKV40000OWE1000000091ISTLED20301016",
+		}
+		for _, hop := range hops {
+			if _, err := svc.SearchByToken(context.Background(), hop); err != nil {
+				t.Fatal(err)
+			}
+		}
+		before := p.calls.Load()
+
+		ts, err := svc.SearchByToken(context.Background(), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls := p.calls.Load() - before; calls != 1 {
+			t.Errorf("provider called %d times, want 1", calls)
+		}
+		if tr, err := ts.Get("vtrip_TK1+TK4"); err != nil || tr.GetPrice() != 190 {
+			t.Errorf("vtrip_TK1+TK4 = %v, %v, want price 190", tr.GetPrice(), err)
+		}
+	})
+
+	t.Run("hops count against search limits", func(t *testing.T) {
+		p := &routeProvider{flights: interlineFlights}
+		svc := newService(p)
+		store := newFakeRateLimitStore()
+		svc.SetRateLimiter(service.NewRateLimiter(store, ratelimit.Limits{SearchesPerMinute: 2}))
+
+		ts, err := svc.SearchByToken(context.Background(), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The search and the first hop are allowed, the second hops are not.
+		if calls := p.calls.Load(); calls != 2 {
+			t.Errorf("provider called %d times, want 2", calls)
+		}
+		if ts.Count() != 1 {
+			t.Errorf("got %d trips, want only the direct one", ts.Count())
+		}
+
+		var exceeded *ratelimit.Exceeded
+		if _, err := svc.SearchByToken(context.Background(), "AKV40000OWE1000000091MOWAER20301015"); !errors.As(err, &exceeded) {
+			t.Errorf("SearchByToken() error = %v, want the limit spent by hops", err)
+		}
+	})
+
+	t.Run("cached virtual interlines are not nested", func(t *testing.T) {
+		const hop = "AKV40000OWE1000000091MOWIST20301015"
+
+		c := newFakeCache()
+		svc := service.NewMultipleSearchService(c)
+		svc.AddProviderService(&routeProvider{flights: interlineFlights})
+		svc.SetVirtualInterlining(service.VirtualInterlining{
+			Partners: []string{"AKV4"},
+			Hubs:     []string{"IST"},
+		})
+
+		// A cached hop result holding a virtual interline of its own.
+		cached := trip.NewTrips()
+		cached.AddTrip(trip.Trip{
+			CacheID:  "vtrip_SU1+TK9",
+			Prices:   trip.TripPrices{Price: 10},
+			Metadata: trip.TripMetadata{IsVtrip: true, IsVirtualInterline: true},
+			Segments: []trip.TripSegment{{
+				Departure: trip.FlightPoint{Airport: "SVO", Time: time.Date(2030, 10, 15, 6, 0, 0, 0, time.UTC)},
+				Arrival:   trip.FlightPoint{Airport: "IST", Time: time.Date(2030, 10, 15, 9, 0, 0, 0, time.UTC)},
+			}},
+		})
+		if err := c.Set(context.Background(), hop, cached); err != nil {
+			t.Fatal(err)
+		}
+
+		ts, err := svc.SearchByToken(context.Background(), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tr := range ts.ToArray() {
+			if strings.Count(tr.CacheID, "vtrip_") > 1 {
+				t.Errorf("got nested virtual interline %s", tr.CacheID)
+			}
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+// maxLegCombinations limits the number of itineraries built out of per-leg
+// results, the full product grows too fast with the number of legs.
+const maxLegCombinations = 50
+
+// routeTypeSupporter is implemented by providers that can't search every
+// route type natively. Providers without it are assumed to support all.
+type routeTypeSupporter interface {
+	SupportsRouteType(sro.RouteType) bool
+}
+
+func supportsRouteType(p provider, t sro.RouteType) bool {
+	rts, ok := p.(routeTypeSupporter)
+	return !ok || rts.SupportsRouteType(t)
+}
+
+// legSearch runs a multi-leg search as separate OW searches, one per leg, and
+// combines up to limit cheapest per-leg results into complete itineraries.
+type legSearch struct {
+	provider
+	limit int
+}
+
+func newLegSearch(p provider, limit int) *legSearch {
+	return &legSearch{provider: p, limit: limit}
+}
+
+func (ls *legSearch) Search(ctx context.Context, s sro.SRO) (*trip.Trips, error) {
+	legs := s.Legs()
+	results := make([][]trip.Trip, len(legs))
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range legs {
+		g.Go(func() error {
+			ts, err := ls.provider.Search(gctx, legs[i])
+			if err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+			if ts != nil {
+				results[i] = ts.ToArray()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	inSequence := func(parts []trip.Trip) bool { return trip.InSequence(parts, 0) }
+
+	ts := trip.NewTrips()
+	for _, parts := range trip.CheapestCombinations(results, ls.limit, inSequence) {
+		t := trip.Combine(parts)
+		t.SRO = &s
+		ts.AddTrip(t)
+	}
+	return ts, nil
+}
@@ -6,8 +6,16 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 
 	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/analytics"
+	"github.com/de4et/flight-booking/internal/model/ban"
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/pricing"
 	"github.com/de4et/flight-booking/internal/model/sro"
 	"github.com/de4et/flight-booking/internal/model/trip"
 )
@@ -17,25 +25,59 @@ var (
 	ErrNoCacheHit = errors.New("")
 )
 
+const searchTimeout = 30 * time.Second
+
 type provider interface {
 	Search(context.Context, sro.SRO) (*trip.Trips, error)
 	GetAvailability() bool
 }
 
+type banList interface {
+	ForPartner(context.Context, string) (*ban.List, error)
+}
+
+type pricerSource interface {
+	PricerFor(context.Context, sro.ChannelToken) (*pricing.Pricer, error)
+}
+
+type converterSource interface {
+	Converter(context.Context) (*fx.Converter, error)
+}
+
+type searchLimiter interface {
+	AllowSearch(context.Context, string, bool) error
+}
+
+type searchRecorder interface {
+	Record(analytics.Search)
+}
+
 type cache interface {
 	Get(context.Context, string) (*trip.Trips, error)
 	Set(context.Context, string, *trip.Trips) error
 }
 
 type MultipleSearchService struct {
-	cache     cache
-	providers []provider
+	cache          cache
+	testCache      cache
+	providers      []provider
+	sandbox        []provider
+	bans           banList
+	pricing        pricerSource
+	fx             converterSource
+	limiter        searchLimiter
+	searchLog      searchRecorder
+	interlining    *VirtualInterlining
+	roundTripPairs int
+	timeout        time.Duration
+	inflight       singleflight.Group
 }
 
 func NewMultipleSearchService(cache cache) *MultipleSearchService {
 	return &MultipleSearchService{
 		providers: make([]provider, 0),
 		cache:     cache,
+		timeout:   searchTimeout,
 	}
 }
 
@@ -43,40 +85,208 @@ func (svc *MultipleSearchService) AddProviderService(p provider) {
 	svc.providers = append(svc.providers, p)
 }
 
+// AddSandboxProvider registers a provider used only by test searches (SRO
+// IsTest). Test searches, actualizations and bookings never reach the
+// providers added with AddProviderService.
+func (svc *MultipleSearchService) AddSandboxProvider(p provider) {
+	svc.sandbox = append(svc.sandbox, p)
+}
+
+// SetTestCache sets the cache of test search results, which must not share
+// keys with the production one. Without it test results are not cached.
+func (svc *MultipleSearchService) SetTestCache(c cache) {
+	svc.testCache = c
+}
+
+func (svc *MultipleSearchService) SetBanList(bans banList) {
+	svc.bans = bans
+}
+
+// SetPricing makes search results priced by the channel rules before they
+// are cached.
+func (svc *MultipleSearchService) SetPricing(pricing pricerSource) {
+	svc.pricing = pricing
+}
+
+// SetCurrencyConversion makes search results converted into the currency
+// of the SRO after pricing.
+func (svc *MultipleSearchService) SetCurrencyConversion(fx converterSource) {
+	svc.fx = fx
+}
+
+// SetRateLimiter limits the searches sent to providers on cache misses per
+// partner. Joining an in-flight search is free.
+func (svc *MultipleSearchService) SetRateLimiter(l searchLimiter) {
+	svc.limiter = l
+}
+
+// SetSearchLog makes answered searches recorded for analytics. Recording
+// must not block.
+func (svc *MultipleSearchService) SetSearchLog(r searchRecorder) {
+	svc.searchLog = r
+}
+
+// SetSearchTimeout bounds a search of all providers, 30 seconds by default.
+func (svc *MultipleSearchService) SetSearchTimeout(d time.Duration) {
+	svc.timeout = d
+}
+
+// SetRoundTripSplitting makes RT searches also run as two OW searches per
+// provider, pairing the pairs cheapest outbound and inbound results. Zero
+// disables it.
+func (svc *MultipleSearchService) SetRoundTripSplitting(pairs int) {
+	svc.roundTripPairs = pairs
+}
+
 func (svc *MultipleSearchService) SearchByToken(ctx context.Context, token string) (*trip.Trips, error) {
 	slog.DebugContext(ctx, "Starting searching token...")
+	start := time.Now()
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
 
-	ts, err := svc.cache.Get(ctx, token)
+	clientSRO, err := sro.FromToken(token)
+	if err != nil {
+		return nil, ErrInvalidSRO
+	}
+	if err := clientSRO.ValidateLegs(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSRO, err)
+	}
+	if err := authorize(ctx, clientSRO); err != nil {
+		return nil, err
+	}
+
+	// Equivalent tokens share one cache entry and one in-flight search.
+	canonical := clientSRO.Canonical()
+	key := canonical.GetToken()
+
+	ctx = logger.WithContext(ctx, "sro.channeltoken", clientSRO.ChannelToken)
+	ctx = logger.WithContext(ctx, "sro.canonical", key)
+	ctx = withTestMode(ctx, clientSRO)
+	slog.DebugContext(ctx, "Sucessfully serialized sro from token")
+
+	cache := svc.cacheFor(clientSRO)
+	ts, err := cache.Get(ctx, key)
 	if err == nil {
 		slog.InfoContext(ctx, "Cache hit!")
-		return ts, nil
+		svc.record(clientSRO, key, true, ts, start)
+		return withSRO(ts, clientSRO), nil
 	}
 
 	if !errors.Is(err, ErrNoCacheHit) {
 		slog.ErrorContext(ctx, "Failed calling cache", "error", err)
 	}
 
-	sro, err := sro.FromToken(token)
-	if err != nil {
-		return nil, ErrInvalidSRO
+	resCh := svc.inflight.DoChan(key, func() (any, error) {
+		// The search is shared between callers, so it must outlive the one
+		// that started it.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), svc.timeout)
+		defer cancel()
+
+		if svc.limiter != nil {
+			if err := svc.limiter.AllowSearch(ctx, canonical.ChannelToken.PartnerCode, canonical.Metadata.IsTest); err != nil {
+				return nil, err
+			}
+		}
+
+		ts, err := svc.searchParallel(ctx, *canonical)
+		if err != nil {
+			return nil, err
+		}
+		svc.addVirtualInterlines(ctx, canonical, ts)
+		if err := svc.price(ctx, canonical, ts); err != nil {
+			return nil, err
+		}
+
+		err = cache.Set(ctx, key, ts)
+		if err != nil {
+			slog.DebugContext(ctx, "Couldn't set cache", "error", err)
+		}
+		return ts, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-resCh:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if res.Shared {
+			slog.InfoContext(ctx, "Joined in-flight search")
+		}
+		ts := res.Val.(*trip.Trips)
+		svc.record(clientSRO, key, false, ts, start)
+		return withSRO(ts, clientSRO), nil
 	}
+}
 
-	ctx = logger.WithContext(ctx, "sro.channeltoken", sro.ChannelToken)
-	slog.DebugContext(ctx, "Sucessfully serialized sro from token")
+// record records the answered search for analytics. Joining an in-flight
+// search counts as a cache miss.
+func (svc *MultipleSearchService) record(s *sro.SRO, key string, hit bool, ts *trip.Trips, start time.Time) {
+	if svc.searchLog == nil {
+		return
+	}
 
-	ts, err = svc.searchParallel(ctx, *sro)
-	if err != nil {
-		return nil, err
+	search := analytics.Search{
+		Token:       key,
+		PartnerCode: s.ChannelToken.PartnerCode,
+		SourceCode:  s.ChannelToken.SourceCode,
+		CacheHit:    hit,
+		Latency:     time.Since(start),
+		IsTest:      s.Metadata.IsTest,
+		At:          start,
 	}
+	search.Summarize(ts)
+	svc.searchLog.Record(search)
+}
 
-	err = svc.cache.Set(ctx, token, ts)
-	if err != nil {
-		slog.DebugContext(ctx, "Couldn't set cache", "error", err)
+// cacheFor returns the cache of the SRO results, keeping test results apart
+// from production ones.
+func (svc *MultipleSearchService) cacheFor(s *sro.SRO) cache {
+	if !s.Metadata.IsTest {
+		return svc.cache
 	}
-	return ts, nil
+	if svc.testCache == nil {
+		return noCache{}
+	}
+	return svc.testCache
+}
+
+// authorize checks that the partner of the request may use the SRO. Requests
+// without a partner are internal and not restricted.
+func authorize(ctx context.Context, s *sro.SRO) error {
+	p, ok := partner.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return p.Authorize(s)
+}
+
+// noCache never stores results.
+type noCache struct{}
+
+func (noCache) Get(context.Context, string) (*trip.Trips, error) { return nil, ErrNoCacheHit }
+func (noCache) Set(context.Context, string, *trip.Trips) error   { return nil }
+
+// withTestMode tags the logs of test requests.
+func withTestMode(ctx context.Context, s *sro.SRO) context.Context {
+	if s == nil || !s.Metadata.IsTest {
+		return ctx
+	}
+	return logger.WithContext(ctx, "test", true)
+}
+
+// withSRO returns a copy of the trips pointing to the SRO the client sent,
+// since cached and shared results carry the canonical one.
+func withSRO(ts *trip.Trips, s *sro.SRO) *trip.Trips {
+	res := ts.Clone()
+	res.Update(func(t *trip.Trip) bool {
+		t.SRO = s
+		t.Booking.Bookable = t.IsBookable()
+		return true
+	})
+	return res
 }
 
 type searchResponse struct {
@@ -84,14 +294,19 @@ type searchResponse struct {
 	err error
 }
 
-func (svc *MultipleSearchService) searchParallel(ctx context.Context, sro sro.SRO) (*trip.Trips, error) {
+func (svc *MultipleSearchService) searchParallel(ctx context.Context, s sro.SRO) (*trip.Trips, error) {
 	ts := trip.NewTrips()
+	bans, err := svc.banListFor(ctx, s.ChannelToken.PartnerCode)
+	if err != nil {
+		return nil, err
+	}
 	outCh := make(chan searchResponse)
 	wg := &sync.WaitGroup{}
 
-	wg.Add(len(svc.providers))
-	for i := range svc.providers {
-		go svc.searchByProvider(ctx, wg, svc.providers[i], outCh, sro)
+	providers := svc.providersFor(allowedProviders(ctx, svc.registered(s.Metadata.IsTest)), s)
+	wg.Add(len(providers))
+	for i := range providers {
+		go svc.searchByProvider(ctx, wg, providers[i], outCh, s)
 	}
 
 	go func() {
@@ -103,12 +318,174 @@ func (svc *MultipleSearchService) searchParallel(ctx context.Context, sro sro.SR
 		if v.err != nil {
 			continue
 		}
+		applyBanList(ctx, bans, v.tr)
+		normalizeTimes(ctx, v.tr)
 		ts.Merge(v.tr)
 	}
 
 	return ts, nil
 }
 
+// registered returns the sandbox providers for test requests and the
+// production ones otherwise.
+func (svc *MultipleSearchService) registered(test bool) []provider {
+	if test {
+		return svc.sandbox
+	}
+	return svc.providers
+}
+
+// allowedProviders drops the providers the partner of the request may not
+// search.
+func allowedProviders(ctx context.Context, providers []provider) []provider {
+	pt, ok := partner.FromContext(ctx)
+	if !ok || len(pt.Providers) == 0 {
+		return providers
+	}
+
+	res := make([]provider, 0, len(providers))
+	for _, p := range providers {
+		if np, ok := p.(namedProvider); ok && pt.AllowsProvider(np.Name()) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// providersFor wraps providers that can't search the route type natively
+// into per-leg searches. With RT splitting enabled RT searches go to both,
+// so one-way fares compete with native RT ones.
+func (svc *MultipleSearchService) providersFor(providers []provider, s sro.SRO) []provider {
+	pairs := maxLegCombinations
+	if svc.roundTripPairs > 0 {
+		pairs = svc.roundTripPairs
+	}
+
+	res := make([]provider, 0, len(providers))
+	for _, p := range providers {
+		switch {
+		case s.IsCX() && !supportsRouteType(p, sro.RouteTypeCX):
+			res = append(res, newLegSearch(p, maxLegCombinations))
+		case s.IsRT() && !supportsRouteType(p, sro.RouteTypeRT):
+			res = append(res, newLegSearch(p, pairs))
+		case s.IsRT() && svc.roundTripPairs > 0:
+			res = append(res, p, newLegSearch(p, pairs))
+		default:
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// banListFor returns the bans of the partner. Unfiltered results are never
+// cached, so the search fails if the bans can't be loaded.
+func (svc *MultipleSearchService) banListFor(ctx context.Context, partnerCode string) (*ban.List, error) {
+	if svc.bans == nil {
+		return nil, nil
+	}
+
+	bans, err := svc.bans.ForPartner(ctx, partnerCode)
+	if err != nil {
+		return nil, fmt.Errorf("loading ban-list: %w", err)
+	}
+	return bans, nil
+}
+
+// price applies pricing rules and converts the merged results into the
+// requested currency.
+func (svc *MultipleSearchService) price(ctx context.Context, s *sro.SRO, ts *trip.Trips) error {
+	if err := svc.applyPricing(ctx, s, ts); err != nil {
+		return err
+	}
+	return svc.applyConversion(ctx, s, ts)
+}
+
+// applyPricing prices the merged results. Unpriced results are never cached,
+// so the search fails if the rules can't be loaded.
+func (svc *MultipleSearchService) applyPricing(ctx context.Context, s *sro.SRO, ts *trip.Trips) error {
+	if svc.pricing == nil {
+		return nil
+	}
+
+	pricer, err := svc.pricing.PricerFor(ctx, s.ChannelToken)
+	if err != nil {
+		return fmt.Errorf("loading pricing rules: %w", err)
+	}
+	if svc.fx != nil {
+		converter, err := svc.fx.Converter(ctx)
+		if err != nil {
+			return fmt.Errorf("loading exchange rates: %w", err)
+		}
+		pricer.SetRates(converter.Rates())
+	}
+
+	ts.Update(func(t *trip.Trip) bool {
+		if err := pricer.Apply(t); err != nil {
+			slog.WarnContext(ctx, "Trip removed, couldn't convert its markup", "cacheId", t.CacheID, "provider", t.Provider.Name, "error", err)
+			return false
+		}
+		return true
+	})
+	return nil
+}
+
+// applyConversion converts prices into the SRO currency. Trips that can't be
+// converted are removed rather than shown in another currency.
+func (svc *MultipleSearchService) applyConversion(ctx context.Context, s *sro.SRO, ts *trip.Trips) error {
+	currency := s.Metadata.Currency
+	if svc.fx == nil || currency == "" {
+		return nil
+	}
+
+	converter, err := svc.fx.Converter(ctx)
+	if err != nil {
+		return fmt.Errorf("loading exchange rates: %w", err)
+	}
+
+	ts.Update(func(t *trip.Trip) bool {
+		if err := converter.Convert(t, currency); err != nil {
+			slog.WarnContext(ctx, "Trip removed, couldn't convert its price", "cacheId", t.CacheID, "provider", t.Provider.Name, "error", err)
+			return false
+		}
+		return true
+	})
+	return nil
+}
+
+// normalizeTimes moves segment times to airport local time and fixes
+// durations that don't match them.
+func normalizeTimes(ctx context.Context, ts *trip.Trips) {
+	if ts == nil {
+		return
+	}
+
+	ts.Update(func(t *trip.Trip) bool {
+		if err := t.NormalizeTimes(); err != nil {
+			slog.WarnContext(ctx, "Provider sent inconsistent segment times", "cacheId", t.CacheID, "provider", t.Provider.Name, "error", err)
+		}
+		return true
+	})
+}
+
+// applyBanList removes banned trips from provider results before they are merged.
+func applyBanList(ctx context.Context, bans *ban.List, ts *trip.Trips) {
+	if bans.Len() == 0 || ts == nil {
+		return
+	}
+
+	banned := make([]trip.Trip, 0)
+	for _, t := range ts.ToArray() {
+		if reason, ok := bans.IsBanned(&t); ok {
+			slog.InfoContext(ctx, "Trip removed by ban-list", "cacheId", t.CacheID, "provider", t.Provider.Name, "reason", reason)
+			banned = append(banned, t)
+		}
+	}
+
+	for i := range banned {
+		ts.RemoveTrip(&banned[i])
+	}
+}
+
 func (svc *MultipleSearchService) searchByProvider(ctx context.Context, wg *sync.WaitGroup, p provider, outCh chan searchResponse, sro sro.SRO) {
 	defer wg.Done()
 
@@ -0,0 +1,397 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/analytics"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type fakeCache struct {
+	mu   sync.Mutex
+	data map[string]*trip.Trips
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{data: make(map[string]*trip.Trips)}
+}
+
+func (c *fakeCache) Get(_ context.Context, key string) (*trip.Trips, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ts, ok := c.data[key]
+	if !ok {
+		return nil, service.ErrNoCacheHit
+	}
+	return ts, nil
+}
+
+func (c *fakeCache) Set(_ context.Context, key string, ts *trip.Trips) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = ts
+	return nil
+}
+
+func (c *fakeCache) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.data))
+	for k := range c.data {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type fakeProvider struct {
+	calls    atomic.Int32
+	delay    time.Duration
+	currency string
+}
+
+func (p *fakeProvider) Search(_ context.Context, s sro.SRO) (*trip.Trips, error) {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+
+	ts := trip.NewTrips()
+	for i := range 3 {
+		ts.AddTrip(trip.Trip{
+			CacheID: fmt.Sprintf("trip_%d", i),
+			Prices:  trip.TripPrices{Price: float64(100 * (i + 1)), ProviderCurrency: p.currency},
+			SRO:     &s,
+		})
+	}
+	return ts, nil
+}
+
+func (p *fakeProvider) GetAvailability() bool {
+	return true
+}
+
+func TestMultipleSearchService_SearchByToken_Coalescing(t *testing.T) {
+	tokens := []string{
+		"AKV40000OWE1000000091MOWLED20241015_I_SU.S7",
+		"AKV40000OWE1000000091MOWLED20241015_I_S7.SU",
+		"AKV40000OWE1000000091MOWLED20241015_I_S7.SU.S7",
+	}
+
+	c := newFakeCache()
+	p := &fakeProvider{delay: 100 * time.Millisecond}
+	svc := service.NewMultipleSearchService(c)
+	svc.AddProviderService(p)
+
+	results := make([]*trip.Trips, len(tokens))
+	wg := sync.WaitGroup{}
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ts, err := svc.SearchByToken(context.Background(), token)
+			if err != nil {
+				t.Errorf("SearchByToken(%s) error = %v", token, err)
+				return
+			}
+			results[i] = ts
+		}()
+	}
+	wg.Wait()
+
+	if calls := p.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times, want 1", calls)
+	}
+	if keys := c.keys(); len(keys) != 1 {
+		t.Errorf("cache has %d entries, want 1: %v", len(keys), keys)
+	}
+
+	for i, ts := range results {
+		if ts == nil || ts.Count() != 3 {
+			t.Fatalf("result %d: unexpected trips %v", i, ts)
+		}
+		want, _ := sro.FromToken(tokens[i])
+		for _, tr := range ts.ToArray() {
+			if tr.SRO.GetToken() != want.GetToken() {
+				t.Errorf("result %d: trip SRO token = %s, want %s", i, tr.SRO.GetToken(), want.GetToken())
+			}
+		}
+	}
+
+	// Served from cache without calling the provider again.
+	if _, err := svc.SearchByToken(context.Background(), tokens[0]); err != nil {
+		t.Fatal(err)
+	}
+	if calls := p.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times after cache hit, want 1", calls)
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_TestMode(t *testing.T) {
+	const (
+		prodToken = "AKV40000OWE1000000091MOWLED20241015"
+		testToken = "AKV40000OWE1000010091MOWLED20241015"
+	)
+
+	prod, sandbox := &fakeProvider{}, &fakeProvider{}
+	prodCache, testCache := newFakeCache(), newFakeCache()
+	svc := service.NewMultipleSearchService(prodCache)
+	svc.AddProviderService(prod)
+	svc.AddSandboxProvider(sandbox)
+	svc.SetTestCache(testCache)
+
+	for range 2 {
+		if _, err := svc.SearchByToken(context.Background(), testToken); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := prod.calls.Load(); calls != 0 {
+		t.Errorf("production provider called %d times by test searches, want 0", calls)
+	}
+	if calls := sandbox.calls.Load(); calls != 1 {
+		t.Errorf("sandbox provider called %d times, want 1", calls)
+	}
+	if keys := prodCache.keys(); len(keys) != 0 {
+		t.Errorf("production cache has test entries: %v", keys)
+	}
+	if keys := testCache.keys(); len(keys) != 1 {
+		t.Errorf("test cache has %d entries, want 1: %v", len(keys), keys)
+	}
+
+	if _, err := svc.SearchByToken(context.Background(), prodToken); err != nil {
+		t.Fatal(err)
+	}
+	if calls := sandbox.calls.Load(); calls != 1 {
+		t.Errorf("sandbox provider called %d times by production search, want 1", calls)
+	}
+	if calls := prod.calls.Load(); calls != 1 {
+		t.Errorf("production provider called %d times, want 1", calls)
+	}
+
+	// Without a test cache test results are not cached at all.
+	svc = service.NewMultipleSearchService(prodCache)
+	svc.AddSandboxProvider(sandbox)
+	if _, err := svc.SearchByToken(context.Background(), testToken); err != nil {
+		t.Fatal(err)
+	}
+	if calls := sandbox.calls.Load(); calls != 2 {
+		t.Errorf("sandbox provider called %d times, want 2", calls)
+	}
+	if keys := prodCache.keys(); len(keys) != 1 {
+		t.Errorf("production cache has %d entries, want 1: %v", len(keys), keys)
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_InvalidToken(t *testing.T) {
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{})
+
+	_, err := svc.SearchByToken(context.Background(), "AKV40000OWE1")
+	if err != service.ErrInvalidSRO {
+		t.Errorf("SearchByToken() error = %v, want %v", err, service.ErrInvalidSRO)
+	}
+}
+
+// legProvider returns a morning and an evening flight for every leg. It
+// searches OW only unless roundTrip is set, then it also returns a single
+// native RT fare.
+type legProvider struct {
+	roundTrip bool
+
+	mu       sync.Mutex
+	searched []string
+}
+
+func (p *legProvider) Search(_ context.Context, s sro.SRO) (*trip.Trips, error) {
+	p.mu.Lock()
+	p.searched = append(p.searched, s.GetToken())
+	p.mu.Unlock()
+
+	ts := trip.NewTrips()
+	if s.IsRT() {
+		out, in := s.Segments[0], s.Segments[1]
+		ts.AddTrip(trip.Trip{
+			CacheID: "native",
+			Prices:  trip.TripPrices{Price: 250},
+			Segments: []trip.TripSegment{
+				{Departure: trip.FlightPoint{Airport: out.From}, Arrival: trip.FlightPoint{Airport: out.To}},
+				{Departure: trip.FlightPoint{Airport: in.From}, Arrival: trip.FlightPoint{Airport: in.To}, Direction: 1},
+			},
+			SRO: &s,
+		})
+		return ts, nil
+	}
+
+	seg := s.Segments[0]
+	for i, hour := range []int{8, 20} {
+		dep := seg.Date.Add(time.Duration(hour) * time.Hour)
+		ts.AddTrip(trip.Trip{
+			CacheID: fmt.Sprintf("%s%s_%d", seg.From, seg.To, i),
+			Prices:  trip.TripPrices{Price: float64(100 * (i + 1))},
+			Segments: []trip.TripSegment{{
+				Departure: trip.FlightPoint{Airport: seg.From, Time: dep},
+				Arrival:   trip.FlightPoint{Airport: seg.To, Time: dep.Add(2 * time.Hour)},
+			}},
+			SRO: &s,
+		})
+	}
+	return ts, nil
+}
+
+func (p *legProvider) GetAvailability() bool {
+	return true
+}
+
+func (p *legProvider) SupportsRouteType(t sro.RouteType) bool {
+	return t == sro.RouteTypeOW || (p.roundTrip && t == sro.RouteTypeRT)
+}
+
+func TestMultipleSearchService_SearchByToken_CX(t *testing.T) {
+	p := &legProvider{}
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(p)
+
+	ts, err := svc.SearchByToken(context.Background(), "AKV40000CXE1000000091MOWLED20301015LEDKZN20301015")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.searched) != 2 {
+		t.Errorf("provider searched %v, want one OW search per leg", p.searched)
+	}
+
+	// Both legs are on the same day, only the morning MOW-LED flight lands
+	// before the evening LED-KZN one departs.
+	want := map[string]float64{
+		"MOWLED_0+LEDKZN_1": 300,
+	}
+	if ts.Count() != len(want) {
+		t.Fatalf("got %d trips, want %d", ts.Count(), len(want))
+	}
+	for _, tr := range ts.ToArray() {
+		price, ok := want[tr.Metadata.VtripComboID]
+		if !ok || !tr.Metadata.IsVtrip || tr.GetPrice() != price {
+			t.Errorf("unexpected trip %s: IsVtrip = %v, price = %v", tr.Metadata.VtripComboID, tr.Metadata.IsVtrip, tr.GetPrice())
+		}
+		if tr.Booking.Bookable {
+			t.Errorf("trip %s: Bookable = true, want virtual trips not bookable", tr.Metadata.VtripComboID)
+		}
+		if len(tr.Segments) != 2 || tr.Segments[0].Direction != 0 || tr.Segments[1].Direction != 1 {
+			t.Errorf("trip %s: segments = %+v, want Direction set per leg", tr.Metadata.VtripComboID, tr.Segments)
+		}
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_InvalidLegs(t *testing.T) {
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{})
+
+	_, err := svc.SearchByToken(context.Background(), "AKV40000CXE1000000091MOWLED20301020LEDKZN20301015")
+	if !errors.Is(err, service.ErrInvalidSRO) || !errors.Is(err, sro.ErrInvalidLegs) {
+		t.Errorf("SearchByToken() error = %v, want %v", err, sro.ErrInvalidLegs)
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_RT(t *testing.T) {
+	const token = "AKV40000RTE1000000091MOWLED20301015LEDMOW20301020"
+
+	tests := []struct {
+		name      string
+		roundTrip bool
+		pairs     int
+		want      map[string]float64
+	}{
+		{
+			name: "OW only provider",
+			want: map[string]float64{
+				"vtrip_MOWLED_0+LEDMOW_0": 200,
+				"vtrip_MOWLED_0+LEDMOW_1": 300,
+				"vtrip_MOWLED_1+LEDMOW_0": 300,
+				"vtrip_MOWLED_1+LEDMOW_1": 400,
+			},
+		},
+		{
+			name:      "native RT without splitting",
+			roundTrip: true,
+			want:      map[string]float64{"native": 250},
+		},
+		{
+			name:      "native RT merged with top pairs",
+			roundTrip: true,
+			pairs:     2,
+			want: map[string]float64{
+				"native":                  250,
+				"vtrip_MOWLED_0+LEDMOW_0": 200,
+				"vtrip_MOWLED_0+LEDMOW_1": 300,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewMultipleSearchService(newFakeCache())
+			svc.AddProviderService(&legProvider{roundTrip: tt.roundTrip})
+			svc.SetRoundTripSplitting(tt.pairs)
+
+			ts, err := svc.SearchByToken(context.Background(), token)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]float64)
+			for _, tr := range ts.ToArray() {
+				got[tr.CacheID] = tr.GetPrice()
+				if len(tr.Segments) != 2 || tr.Segments[0].Direction != 0 || tr.Segments[1].Direction != 1 {
+					t.Errorf("trip %s: segments = %+v, want outbound and inbound", tr.CacheID, tr.Segments)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SearchByToken() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type searchRecorder struct {
+	mu       sync.Mutex
+	searches []analytics.Search
+}
+
+func (r *searchRecorder) Record(s analytics.Search) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches = append(r.searches, s)
+}
+
+func TestMultipleSearchService_SearchByToken_SearchLog(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20241015_I_S7.SU"
+
+	rec := &searchRecorder{}
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{})
+	svc.SetSearchLog(rec)
+
+	for range 2 {
+		if _, err := svc.SearchByToken(context.Background(), token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	canonical, _ := sro.FromToken(token)
+	want := []analytics.Search{
+		{Token: canonical.Canonical().GetToken(), PartnerCode: "AKV4", SourceCode: "0000", CacheHit: false, Results: 3, MinPrice: 100},
+		{Token: canonical.Canonical().GetToken(), PartnerCode: "AKV4", SourceCode: "0000", CacheHit: true, Results: 3, MinPrice: 100},
+	}
+	opts := cmpopts.IgnoreFields(analytics.Search{}, "Latency", "At")
+	if diff := cmp.Diff(want, rec.searches, opts); diff != "" {
+		t.Errorf("recorded searches mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/passenger"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+type orderRepository interface {
+	Create(context.Context, order.Order) (order.Order, error)
+	Get(context.Context, int64) (order.Order, error)
+	UpdateStatus(context.Context, *order.Order, order.Status, string) error
+	Events(context.Context, int64) ([]order.Event, error)
+}
+
+type tripBooker interface {
+	GetTrip(context.Context, string, string) (trip.Trip, error)
+	Book(context.Context, order.Order) (string, error)
+	Cancel(context.Context, order.Order) error
+}
+
+type OrderService struct {
+	repo    orderRepository
+	booking tripBooker
+}
+
+func NewOrderService(repo orderRepository, booking tripBooker) *OrderService {
+	return &OrderService{
+		repo:    repo,
+		booking: booking,
+	}
+}
+
+// Get returns the order. Partners only see their own orders.
+func (s *OrderService) Get(ctx context.Context, id int64) (order.Order, error) {
+	o, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return order.Order{}, err
+	}
+	if p, ok := partner.FromContext(ctx); ok && o.PartnerCode != p.Code {
+		return order.Order{}, order.ErrNotFound
+	}
+	return o, nil
+}
+
+// Events returns the status history of the order.
+func (s *OrderService) Events(ctx context.Context, id int64) ([]order.Event, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.Events(ctx, id)
+}
+
+// Create stores an order for a trip from the cached search result and books
+// it at the provider. Orders the provider fails to book are cancelled, trips
+// that can't be booked at all are rejected before storing an order. Bookings
+// that can't be stored, e.g. because the order was expired meanwhile, are
+// cancelled at the provider.
+func (s *OrderService) Create(ctx context.Context, token, cacheID string, passengers []passenger.Passenger, contacts order.Contacts) (order.Order, error) {
+	if p, ok := partner.FromContext(ctx); ok && !p.BookingEnabled {
+		return order.Order{}, partner.ErrBookingForbidden
+	}
+
+	t, err := s.booking.GetTrip(ctx, token, cacheID)
+	if err != nil {
+		return order.Order{}, err
+	}
+	if !t.IsBookable() {
+		return order.Order{}, fmt.Errorf("%w: virtual trip", ErrBookingUnsupported)
+	}
+
+	o := order.New(token, t, passengers, contacts)
+	if err := o.Validate(); err != nil {
+		return order.Order{}, err
+	}
+
+	// A stored order is booked even if the client goes away meanwhile.
+	ctx = context.WithoutCancel(ctx)
+	o, err = s.repo.Create(ctx, o)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	ctx = logger.WithContext(ctx, "order.id", o.ID)
+	ctx = withTestMode(ctx, t.SRO)
+	slog.InfoContext(ctx, "Order created")
+
+	ref, bookErr := s.booking.Book(ctx, o)
+	if bookErr != nil {
+		slog.WarnContext(ctx, "Booking failed, cancelling order", "error", bookErr)
+		if err := s.transition(ctx, &o, order.StatusCancelled, "booking failed: "+bookErr.Error()); err != nil {
+			slog.ErrorContext(ctx, "Couldn't cancel order", "error", err)
+		}
+		return order.Order{}, bookErr
+	}
+
+	o.BookingReference = ref
+	if err := s.transition(ctx, &o, order.StatusBooked, "booked at "+o.Provider); err != nil {
+		slog.ErrorContext(ctx, "Couldn't store booking, cancelling it at the provider", "bookingReference", ref, "error", err)
+		if err := s.booking.Cancel(ctx, o); err != nil {
+			slog.ErrorContext(ctx, "Couldn't cancel booking", "bookingReference", ref, "error", err)
+		}
+		return order.Order{}, err
+	}
+
+	slog.InfoContext(ctx, "Order booked", "bookingReference", ref)
+	return o, nil
+}
+
+func (s *OrderService) transition(ctx context.Context, o *order.Order, to order.Status, reason string) error {
+	from := o.Status
+	if err := o.Transition(to); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateStatus(ctx, o, from, reason); err != nil {
+		return fmt.Errorf("updating order %d to %s: %w", o.ID, to, err)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/passenger"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+type fakeOrderRepository struct {
+	mu     sync.Mutex
+	orders map[int64]order.Order
+	events []order.Event
+}
+
+func newFakeOrderRepository() *fakeOrderRepository {
+	return &fakeOrderRepository{orders: make(map[int64]order.Order)}
+}
+
+func (r *fakeOrderRepository) Create(_ context.Context, o order.Order) (order.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o.ID = int64(len(r.orders) + 1)
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now()
+	}
+	o.UpdatedAt = time.Now()
+	r.orders[o.ID] = o
+	return o, nil
+}
+
+func (r *fakeOrderRepository) Get(_ context.Context, id int64) (order.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orders[id]
+	if !ok {
+		return order.Order{}, order.ErrNotFound
+	}
+	return o, nil
+}
+
+func (r *fakeOrderRepository) UpdateStatus(_ context.Context, o *order.Order, from order.Status, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.orders[o.ID]
+	if !ok || stored.Status != from {
+		return order.ErrStatusChanged
+	}
+	o.UpdatedAt = time.Now()
+	r.orders[o.ID] = *o
+	r.events = append(r.events, order.Event{ID: int64(len(r.events) + 1), OrderID: o.ID, From: from, To: o.Status, Reason: reason})
+	return nil
+}
+
+func (r *fakeOrderRepository) Events(_ context.Context, id int64) ([]order.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []order.Event
+	for _, e := range r.events {
+		if e.OrderID == id {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeOrderRepository) ListExpiring(_ context.Context, before, createdBefore time.Time, limit int) ([]order.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orders []order.Order
+	for id := int64(1); id <= int64(len(r.orders)) && len(orders) < limit; id++ {
+		o := r.orders[id]
+		hold := o.HoldUntil()
+		due := o.Status == order.StatusBooked || o.Status == order.StatusExpiring ||
+			o.Status == order.StatusCreated && !o.CreatedAt.After(createdBefore)
+		if due && !hold.IsZero() && !hold.After(before) {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+type searchProvider interface {
+	Search(context.Context, sro.SRO) (*trip.Trips, error)
+	GetAvailability() bool
+}
+
+// bookingProvider books every trip except trip_1.
+type bookingProvider struct {
+	repricingProvider
+
+	mu        sync.Mutex
+	cancelled []string
+}
+
+func (p *bookingProvider) Book(_ context.Context, o order.Order) (string, error) {
+	if o.Trip.CacheID == "trip_1" {
+		return "", errors.New("segment sold out")
+	}
+	return "PNR" + o.Trip.CacheID, nil
+}
+
+func (p *bookingProvider) Cancel(_ context.Context, o order.Order) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cancelled = append(p.cancelled, o.BookingReference)
+	return nil
+}
+
+// expiredOrderRepository expires orders before they are stored as booked.
+type expiredOrderRepository struct {
+	*fakeOrderRepository
+}
+
+func (r expiredOrderRepository) UpdateStatus(ctx context.Context, o *order.Order, from order.Status, reason string) error {
+	if o.Status == order.StatusBooked {
+		return order.ErrStatusChanged
+	}
+	return r.fakeOrderRepository.UpdateStatus(ctx, o, from, reason)
+}
+
+// virtualProvider finds virtual trips only.
+type virtualProvider struct {
+	bookingProvider
+}
+
+func (p *virtualProvider) Search(ctx context.Context, s sro.SRO) (*trip.Trips, error) {
+	ts, err := p.bookingProvider.Search(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	res := trip.NewTrips()
+	for _, t := range ts.ToArray() {
+		t.Metadata.IsVtrip = true
+		res.AddTrip(t)
+	}
+	return res, nil
+}
+
+func TestOrderService_Create(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20301015"
+
+	passengers := []passenger.Passenger{{
+		Type:      sro.PassengerTypeADT,
+		FirstName: "IVAN",
+		LastName:  "IVANOV",
+		BirthDate: passenger.NewDate(1990, 1, 1),
+		Gender:    passenger.GenderMale,
+		Document:  passenger.Document{Type: passenger.DocumentPassport, Number: "1234567890", Country: "RU", ExpiresAt: passenger.NewDate(2035, 1, 1)},
+	}}
+	contacts := order.Contacts{Email: "ivan@example.com"}
+
+	tests := []struct {
+		name       string
+		provider   searchProvider
+		cacheID    string
+		passengers []passenger.Passenger
+		wantRef    string
+		wantStatus order.Status
+		wantErr    error
+	}{
+		{
+			name:       "booked",
+			provider:   &bookingProvider{},
+			cacheID:    "trip_0",
+			passengers: passengers,
+			wantRef:    "PNRtrip_0",
+			wantStatus: order.StatusBooked,
+		},
+		{
+			name:       "provider failed",
+			provider:   &bookingProvider{},
+			cacheID:    "trip_1",
+			passengers: passengers,
+			wantStatus: order.StatusCancelled,
+			wantErr:    service.ErrProviderFailed,
+		},
+		{
+			name:       "provider can't book",
+			provider:   &repricingProvider{},
+			cacheID:    "trip_0",
+			passengers: passengers,
+			wantStatus: order.StatusCancelled,
+			wantErr:    service.ErrBookingUnsupported,
+		},
+		{
+			name:       "virtual trip",
+			provider:   &virtualProvider{},
+			cacheID:    "trip_0",
+			passengers: passengers,
+			wantErr:    service.ErrBookingUnsupported,
+		},
+		{
+			name:     "invalid order",
+			provider: &bookingProvider{},
+			cacheID:  "trip_0",
+			wantErr:  order.ErrInvalidOrder,
+		},
+		{
+			name:       "unknown trip",
+			provider:   &bookingProvider{},
+			cacheID:    "trip_9",
+			passengers: passengers,
+			wantErr:    service.ErrTripNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewMultipleSearchService(newFakeCache())
+			svc.AddProviderService(tt.provider)
+			if _, err := svc.SearchByToken(context.Background(), token); err != nil {
+				t.Fatal(err)
+			}
+
+			repo := newFakeOrderRepository()
+			orders := service.NewOrderService(repo, svc)
+
+			o, err := orders.Create(context.Background(), token, tt.cacheID, tt.passengers, contacts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (o.BookingReference != tt.wantRef || o.Status != tt.wantStatus) {
+				t.Errorf("Create() = %s %s, want %s %s", o.Status, o.BookingReference, tt.wantStatus, tt.wantRef)
+			}
+
+			// The order is stored unless it was rejected before booking.
+			stored, err := orders.Get(context.Background(), 1)
+			if tt.wantStatus == "" {
+				if !errors.Is(err, order.ErrNotFound) {
+					t.Errorf("Get() error = %v, want %v", err, order.ErrNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.wantStatus || stored.BookingReference != tt.wantRef {
+				t.Errorf("stored order = %s %s, want %s %s", stored.Status, stored.BookingReference, tt.wantStatus, tt.wantRef)
+			}
+			if stored.Trip.CacheID != tt.cacheID || stored.Provider != "repricing" {
+				t.Errorf("stored order trip = %s from %s, want %s from repricing", stored.Trip.CacheID, stored.Provider, tt.cacheID)
+			}
+		})
+	}
+}
+
+func TestOrderService_Create_TestMode(t *testing.T) {
+	const token = "AKV40000OWE1000010091MOWLED20301015"
+
+	// Both providers have the same name, the test trip must not be booked
+	// at the production one.
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&bookingProvider{})
+	svc.AddSandboxProvider(&repricingProvider{})
+	svc.SetTestCache(newFakeCache())
+	if _, err := svc.SearchByToken(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+
+	passengers := []passenger.Passenger{{
+		Type:      sro.PassengerTypeADT,
+		FirstName: "IVAN",
+		LastName:  "IVANOV",
+		BirthDate: passenger.NewDate(1990, 1, 1),
+		Gender:    passenger.GenderMale,
+		Document:  passenger.Document{Type: passenger.DocumentPassport, Number: "1234567890", Country: "RU", ExpiresAt: passenger.NewDate(2035, 1, 1)},
+	}}
+
+	repo := newFakeOrderRepository()
+	orders := service.NewOrderService(repo, svc)
+	_, err := orders.Create(context.Background(), token, "trip_0", passengers, order.Contacts{Email: "ivan@example.com"})
+	if !errors.Is(err, service.ErrBookingUnsupported) {
+		t.Fatalf("Create() error = %v, want %v", err, service.ErrBookingUnsupported)
+	}
+
+	stored, err := orders.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.IsTest || stored.Status != order.StatusCancelled {
+		t.Errorf("stored order IsTest = %v, status = %s, want true, %s", stored.IsTest, stored.Status, order.StatusCancelled)
+	}
+}
+
+func TestOrderService_Create_StoreFailed(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20301015"
+
+	provider := &bookingProvider{}
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(provider)
+	if _, err := svc.SearchByToken(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+
+	passengers := []passenger.Passenger{{
+		Type:      sro.PassengerTypeADT,
+		FirstName: "IVAN",
+		LastName:  "IVANOV",
+		BirthDate: passenger.NewDate(1990, 1, 1),
+		Gender:    passenger.GenderMale,
+		Document:  passenger.Document{Type: passenger.DocumentPassport, Number: "1234567890", Country: "RU", ExpiresAt: passenger.NewDate(2035, 1, 1)},
+	}}
+
+	orders := service.NewOrderService(expiredOrderRepository{newFakeOrderRepository()}, svc)
+	_, err := orders.Create(context.Background(), token, "trip_0", passengers, order.Contacts{Email: "ivan@example.com"})
+	if !errors.Is(err, order.ErrStatusChanged) {
+		t.Fatalf("Create() error = %v, want %v", err, order.ErrStatusChanged)
+	}
+
+	// The booking the order couldn't keep is released at the provider.
+	if len(provider.cancelled) != 1 || provider.cancelled[0] != "PNRtrip_0" {
+		t.Errorf("cancelled %v, want [PNRtrip_0]", provider.cancelled)
+	}
+}
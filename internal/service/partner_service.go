@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+)
+
+const partnerRefreshInterval = 30 * time.Second
+
+type partnerRepository interface {
+	List(context.Context) ([]partner.Partner, error)
+	Create(context.Context, partner.Partner) (partner.Partner, error)
+	Delete(context.Context, int64) error
+}
+
+// PartnerService keeps an in-memory copy of the partner registry, reloaded
+// from the repository at most every partnerRefreshInterval or right after a
+// change. If a reload fails the partners loaded before keep being used.
+type PartnerService struct {
+	repo   partnerRepository
+	byCode *snapshot[map[string]partner.Partner]
+}
+
+func NewPartnerService(repo partnerRepository) *PartnerService {
+	return &PartnerService{
+		repo: repo,
+		byCode: newSnapshot("partners", partnerRefreshInterval, func(ctx context.Context) (map[string]partner.Partner, error) {
+			partners, err := repo.List(ctx)
+			if err != nil {
+				return nil, err
+			}
+			byCode := make(map[string]partner.Partner, len(partners))
+			for _, p := range partners {
+				byCode[p.Code] = p
+			}
+			return byCode, nil
+		}),
+	}
+}
+
+// List returns the partners without their secrets.
+func (s *PartnerService) List(ctx context.Context) ([]partner.Partner, error) {
+	partners, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range partners {
+		partners[i].Secret = ""
+	}
+	return partners, nil
+}
+
+// Create registers a partner with a generated secret, which is returned only
+// here.
+func (s *PartnerService) Create(ctx context.Context, p partner.Partner) (partner.Partner, error) {
+	if err := p.Validate(); err != nil {
+		return partner.Partner{}, err
+	}
+
+	secret, err := partner.GenerateSecret()
+	if err != nil {
+		return partner.Partner{}, err
+	}
+	p.Secret = secret
+
+	p, err = s.repo.Create(ctx, p)
+	if err != nil {
+		return partner.Partner{}, err
+	}
+	s.byCode.invalidate()
+	return p, nil
+}
+
+func (s *PartnerService) Delete(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.byCode.invalidate()
+	return nil
+}
+
+// Get returns the partner with the code, including its secret.
+func (s *PartnerService) Get(ctx context.Context, code string) (partner.Partner, error) {
+	byCode, err := s.byCode.get(ctx)
+	if err != nil {
+		return partner.Partner{}, err
+	}
+
+	p, ok := byCode[code]
+	if !ok {
+		return partner.Partner{}, partner.ErrNotFound
+	}
+	return p, nil
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/order"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+type fakePartnerRepository struct {
+	partners []partner.Partner
+	err      error
+}
+
+func (r *fakePartnerRepository) List(context.Context) ([]partner.Partner, error) {
+	return r.partners, r.err
+}
+
+func (r *fakePartnerRepository) Create(_ context.Context, p partner.Partner) (partner.Partner, error) {
+	p.ID = int64(len(r.partners) + 1)
+	r.partners = append(r.partners, p)
+	return p, nil
+}
+
+func (r *fakePartnerRepository) Delete(context.Context, int64) error {
+	return nil
+}
+
+func TestPartnerService_Get(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakePartnerRepository{}
+	svc := service.NewPartnerService(repo)
+
+	if _, err := svc.Create(ctx, partner.Partner{Code: "AKV"}); !errors.Is(err, partner.ErrInvalidPartner) {
+		t.Fatalf("Create() error = %v, want %v", err, partner.ErrInvalidPartner)
+	}
+	if _, err := svc.Get(ctx, "AKV4"); !errors.Is(err, partner.ErrNotFound) {
+		t.Fatalf("Get() error = %v, want %v", err, partner.ErrNotFound)
+	}
+
+	created, err := svc.Create(ctx, partner.Partner{Code: "AKV4", Name: "Aviakassa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Secret == "" {
+		t.Fatal("Create() returned no secret")
+	}
+
+	// New partners can authenticate right away.
+	p, err := svc.Get(ctx, "AKV4")
+	if err != nil || p.Secret != created.Secret {
+		t.Fatalf("Get() = %v, %v, want the created partner", p, err)
+	}
+
+	// Loaded partners are kept while the repository fails.
+	repo.err = errors.New("connection refused")
+	if _, err := svc.Create(ctx, partner.Partner{Code: "BBBB", Name: "Other"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, "AKV4"); err != nil {
+		t.Errorf("Get() with failing repository error = %v, want nil", err)
+	}
+
+	repo.err = nil
+	listed, err := svc.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range listed {
+		if p.Secret != "" {
+			t.Errorf("List() exposes the secret of %s", p.Code)
+		}
+	}
+}
+
+// blockingPartnerRepository counts the loads and holds them until released.
+type blockingPartnerRepository struct {
+	fakePartnerRepository
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (r *blockingPartnerRepository) List(ctx context.Context) ([]partner.Partner, error) {
+	r.calls.Add(1)
+	<-r.release
+	return r.fakePartnerRepository.List(ctx)
+}
+
+func TestPartnerService_Get_SharedReload(t *testing.T) {
+	repo := &blockingPartnerRepository{
+		fakePartnerRepository: fakePartnerRepository{partners: []partner.Partner{{Code: "AKV4"}}},
+		release:               make(chan struct{}),
+	}
+	svc := service.NewPartnerService(repo)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.Get(context.Background(), "AKV4"); err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}()
+	}
+	for repo.calls.Load() == 0 {
+		runtime.Gosched()
+	}
+	close(repo.release)
+	wg.Wait()
+
+	if n := repo.calls.Load(); n != 1 {
+		t.Errorf("partners loaded %d times, want once", n)
+	}
+}
+
+func TestMultipleSearchService_Partner(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20301015"
+
+	newService := func() *service.MultipleSearchService {
+		svc := service.NewMultipleSearchService(newFakeCache())
+		svc.AddProviderService(&bookingProvider{})
+		svc.AddProviderService(&fakeProvider{})
+		return svc
+	}
+
+	t.Run("other partner code", func(t *testing.T) {
+		ctx := partner.WithContext(context.Background(), partner.Partner{Code: "BBBB"})
+		if _, err := newService().SearchByToken(ctx, token); !errors.Is(err, partner.ErrForbidden) {
+			t.Errorf("SearchByToken() error = %v, want %v", err, partner.ErrForbidden)
+		}
+	})
+
+	t.Run("allowed providers", func(t *testing.T) {
+		ctx := partner.WithContext(context.Background(), partner.Partner{Code: "AKV4", Providers: []string{"repricing"}})
+		ts, err := newService().SearchByToken(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts.IsEmpty() {
+			t.Fatal("SearchByToken() found nothing")
+		}
+		for _, tr := range ts.ToArray() {
+			if tr.Provider.Name != "repricing" {
+				t.Errorf("trip %s from provider %q the partner may not search", tr.CacheID, tr.Provider.Name)
+			}
+		}
+	})
+
+	t.Run("booking disabled", func(t *testing.T) {
+		svc := newService()
+		ctx := partner.WithContext(context.Background(), partner.Partner{Code: "AKV4"})
+		if _, err := svc.SearchByToken(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+
+		orders := service.NewOrderService(newFakeOrderRepository(), svc)
+		_, err := orders.Create(ctx, token, "trip_0", nil, order.Contacts{})
+		if !errors.Is(err, partner.ErrBookingForbidden) {
+			t.Errorf("Create() error = %v, want %v", err, partner.ErrBookingForbidden)
+		}
+	})
+}
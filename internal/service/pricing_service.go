@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/model/sro"
+)
+
+const pricingRefreshInterval = 30 * time.Second
+
+type pricingRepository interface {
+	List(context.Context) ([]pricing.Rule, error)
+	ListActive(context.Context, time.Time) ([]pricing.Rule, error)
+	Create(context.Context, pricing.Rule) (pricing.Rule, error)
+	Delete(context.Context, int64) error
+}
+
+// PricingService keeps an in-memory copy of active pricing rules, reloaded
+// from the repository at most every pricingRefreshInterval or right after a
+// change. If a reload fails the rules loaded before keep being used.
+type PricingService struct {
+	repo   pricingRepository
+	active *snapshot[[]pricing.Rule]
+}
+
+func NewPricingService(repo pricingRepository) *PricingService {
+	return &PricingService{
+		repo: repo,
+		active: newSnapshot("pricing rules", pricingRefreshInterval, func(ctx context.Context) ([]pricing.Rule, error) {
+			return repo.ListActive(ctx, time.Now())
+		}),
+	}
+}
+
+func (s *PricingService) List(ctx context.Context) ([]pricing.Rule, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *PricingService) Create(ctx context.Context, r pricing.Rule) (pricing.Rule, error) {
+	if err := r.Validate(); err != nil {
+		return pricing.Rule{}, err
+	}
+
+	r, err := s.repo.Create(ctx, r)
+	if err != nil {
+		return pricing.Rule{}, err
+	}
+	s.active.invalidate()
+	return r, nil
+}
+
+func (s *PricingService) Delete(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.active.invalidate()
+	return nil
+}
+
+// PricerFor returns the rules applying to the channel right now.
+func (s *PricingService) PricerFor(ctx context.Context, ct sro.ChannelToken) (*pricing.Pricer, error) {
+	active, err := s.active.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pricing.NewPricer(active, ct, time.Now()), nil
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+type fakePricingRepository struct {
+	rules []pricing.Rule
+	err   error
+	loads int
+}
+
+func (r *fakePricingRepository) List(context.Context) ([]pricing.Rule, error) {
+	return r.rules, r.err
+}
+
+func (r *fakePricingRepository) ListActive(context.Context, time.Time) ([]pricing.Rule, error) {
+	r.loads++
+	return r.rules, r.err
+}
+
+func (r *fakePricingRepository) Create(_ context.Context, rule pricing.Rule) (pricing.Rule, error) {
+	rule.ID = int64(len(r.rules) + 1)
+	r.rules = append(r.rules, rule)
+	return rule, nil
+}
+
+func (r *fakePricingRepository) Delete(context.Context, int64) error {
+	return nil
+}
+
+func TestPricingService_PricerFor(t *testing.T) {
+	ctx := context.Background()
+	ct := sro.ChannelToken{PartnerCode: "AKV4", SourceCode: "0000"}
+	repo := &fakePricingRepository{}
+	svc := service.NewPricingService(repo)
+
+	if _, err := svc.Create(ctx, pricing.Rule{MarkupPercent: 5}); !errors.Is(err, pricing.ErrInvalidRule) {
+		t.Fatalf("Create() error = %v, want %v", err, pricing.ErrInvalidRule)
+	}
+	if p, err := svc.PricerFor(ctx, ct); err != nil || p.Len() != 0 {
+		t.Fatalf("PricerFor() = %v, %v, want no rules", p, err)
+	}
+
+	// New rules apply right away.
+	if _, err := svc.Create(ctx, pricing.Rule{Name: "markup", MarkupPercent: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := svc.PricerFor(ctx, ct); err != nil || p.Len() != 1 {
+		t.Fatalf("PricerFor() = %v, %v, want the created rule", p, err)
+	}
+
+	// Loaded rules are kept while the repository fails.
+	repo.err = errors.New("connection refused")
+	svc.Delete(ctx, 1)
+	if p, err := svc.PricerFor(ctx, ct); err != nil || p.Len() != 1 {
+		t.Fatalf("PricerFor() = %v, %v, want the loaded rule", p, err)
+	}
+	loads := repo.loads
+	if _, err := svc.PricerFor(ctx, ct); err != nil || repo.loads != loads {
+		t.Errorf("PricerFor() reloaded %d times after a failure, want 0", repo.loads-loads)
+	}
+
+	// Nothing to fall back to.
+	if _, err := service.NewPricingService(repo).PricerFor(ctx, ct); err == nil {
+		t.Error("PricerFor() error = nil, want the repository error")
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_Pricing(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20241015"
+
+	repo := &fakePricingRepository{rules: []pricing.Rule{
+		{ID: 1, Name: "AKV4", PartnerCode: "AKV4", MarkupPercent: 10},
+		{ID: 2, Name: "other partner", PartnerCode: "ZZZZ", MarkupPercent: 50},
+	}}
+	c := newFakeCache()
+	svc := service.NewMultipleSearchService(c)
+	svc.AddProviderService(&fakeProvider{})
+	svc.SetPricing(service.NewPricingService(repo))
+
+	ts, err := svc.SearchByToken(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := ts.Get("trip_0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Prices.Price != 110 || tr.Prices.SearchPrice != 100 || tr.Prices.PricerInfo.RuleID != 1 {
+		t.Errorf("trip priced %v from %v by rule %d, want 110 from 100 by rule 1",
+			tr.Prices.Price, tr.Prices.SearchPrice, tr.Prices.PricerInfo.RuleID)
+	}
+
+	// Unpriced results are never served.
+	repo.err = errors.New("connection refused")
+	svc = service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&fakeProvider{})
+	svc.SetPricing(service.NewPricingService(repo))
+	if _, err := svc.SearchByToken(context.Background(), token); err == nil {
+		t.Error("SearchByToken() error = nil, want pricing error")
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/health"
+)
+
+var (
+	errProviderUnavailable  = errors.New("provider is unavailable")
+	errProvidersUnavailable = errors.New("every provider is unavailable")
+	errBreakerOpen          = errors.New("circuit breaker is open")
+)
+
+// Circuit breaker states reported by providers.
+const (
+	BreakerClosed   = "closed"
+	BreakerHalfOpen = "half-open"
+	BreakerOpen     = "open"
+)
+
+// breakerReporter is implemented by providers behind a circuit breaker.
+type breakerReporter interface {
+	BreakerState() string
+}
+
+// RegisterHealthChecks registers a check of every production provider and a
+// critical one of them all. A single provider isn't critical, searches go on
+// with the other ones, but without any the API can't serve.
+func (svc *MultipleSearchService) RegisterHealthChecks(r *health.Registry) {
+	for i, p := range svc.providers {
+		name := strconv.Itoa(i + 1)
+		if np, ok := p.(namedProvider); ok {
+			name = np.Name()
+		}
+		r.Register("provider_"+name, false, providerCheck(p))
+	}
+	r.Register("providers", true, providersCheck(svc.providers))
+}
+
+// providerCheck reports the provider down while it is unavailable or its
+// breaker is open, and degraded while the breaker is probing.
+func providerCheck(p provider) health.Checker {
+	return health.CheckerFunc(func(context.Context) health.Result {
+		res := health.Up()
+		if !p.GetAvailability() {
+			res = health.Down(errProviderUnavailable)
+		}
+
+		br, ok := p.(breakerReporter)
+		if !ok {
+			return res
+		}
+		state := br.BreakerState()
+		switch {
+		case state == BreakerOpen:
+			res = health.Down(errBreakerOpen)
+		case state == BreakerHalfOpen && res.Status == health.StatusUp:
+			res.Status = health.StatusDegraded
+		}
+		res.Details = map[string]string{"breaker": state}
+		return res
+	})
+}
+
+func breakerOpen(p provider) bool {
+	br, ok := p.(breakerReporter)
+	return ok && br.BreakerState() == BreakerOpen
+}
+
+// providersCheck reports the providers down when none is available and
+// degraded when some are not. A provider with an open breaker isn't
+// available.
+func providersCheck(providers []provider) health.Checker {
+	return health.CheckerFunc(func(context.Context) health.Result {
+		var available int
+		for _, p := range providers {
+			if p.GetAvailability() && !breakerOpen(p) {
+				available++
+			}
+		}
+
+		res := health.Up()
+		switch {
+		case available == 0:
+			res = health.Down(errProvidersUnavailable)
+		case available < len(providers):
+			res.Status = health.StatusDegraded
+		}
+		res.Details = map[string]string{"available": fmt.Sprintf("%d/%d", available, len(providers))}
+		return res
+	})
+}
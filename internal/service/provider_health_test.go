@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/health"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+// downProvider is a provider that is unavailable while down is set.
+type downProvider struct {
+	fakeProvider
+	down bool
+}
+
+func (p *downProvider) GetAvailability() bool {
+	return !p.down
+}
+
+func TestMultipleSearchService_RegisterHealthChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		down       []bool
+		wantStatus health.Status
+	}{
+		{name: "all up", down: []bool{false, false}, wantStatus: health.StatusUp},
+		{name: "one down", down: []bool{true, false}, wantStatus: health.StatusDegraded},
+		{name: "all down", down: []bool{true, true}, wantStatus: health.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewMultipleSearchService(newFakeCache())
+			for _, down := range tt.down {
+				svc.AddProviderService(&downProvider{down: down})
+			}
+
+			checks := health.NewRegistry(time.Second)
+			svc.RegisterHealthChecks(checks)
+
+			report := checks.Check(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Check() status = %s, want %s (%+v)", report.Status, tt.wantStatus, report.Checks)
+			}
+			if len(report.Checks) != len(tt.down)+1 {
+				t.Errorf("Check() ran %d checks, want one per provider and the aggregate", len(report.Checks))
+			}
+		})
+	}
+}
+
+// breakerProvider is an available provider behind a breaker in state.
+type breakerProvider struct {
+	fakeProvider
+	state string
+}
+
+func (p *breakerProvider) GetAvailability() bool {
+	return true
+}
+
+func (p *breakerProvider) BreakerState() string {
+	return p.state
+}
+
+func TestMultipleSearchService_RegisterHealthChecks_Breaker(t *testing.T) {
+	tests := []struct {
+		state      string
+		wantStatus health.Status
+	}{
+		{state: service.BreakerClosed, wantStatus: health.StatusUp},
+		{state: service.BreakerHalfOpen, wantStatus: health.StatusDegraded},
+		{state: service.BreakerOpen, wantStatus: health.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			svc := service.NewMultipleSearchService(newFakeCache())
+			svc.AddProviderService(&breakerProvider{state: tt.state})
+
+			checks := health.NewRegistry(time.Second)
+			svc.RegisterHealthChecks(checks)
+
+			res := checks.Check(context.Background()).Checks["provider_1"]
+			if res.Status != tt.wantStatus {
+				t.Errorf("provider check status = %s, want %s", res.Status, tt.wantStatus)
+			}
+			if res.Details["breaker"] != tt.state {
+				t.Errorf("provider check breaker = %q, want %q", res.Details["breaker"], tt.state)
+			}
+		})
+	}
+}
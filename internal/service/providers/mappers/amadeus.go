@@ -0,0 +1,113 @@
+package mappers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/supplier"
+)
+
+const amadeusRecommendations = 250
+
+var amadeusPTC = map[sro.PassengerType]string{
+	sro.PassengerTypeADT: "ADT",
+	sro.PassengerTypeCHD: "CH",
+	sro.PassengerTypeINF: "INF",
+	sro.PassengerTypeSRC: "YCD",
+	sro.PassengerTypeYTH: "YTH",
+}
+
+var amadeusCabin = map[supplier.Cabin]string{
+	supplier.CabinEconomy:        "M",
+	supplier.CabinPremiumEconomy: "W",
+	supplier.CabinBusiness:       "C",
+	supplier.CabinFirst:          "F",
+}
+
+type AmadeusRequest struct {
+	NumberOfUnits  AmadeusUnits       `json:"numberOfUnits"`
+	PaxReference   []AmadeusPax       `json:"paxReference"`
+	Cabin          string             `json:"cabin"`
+	MaxConnections int                `json:"maxConnections"`
+	NonStop        bool               `json:"nonStop"`
+	FreeBaggage    bool               `json:"freeBaggage"`
+	Carriers       *AmadeusCarriers   `json:"carriers,omitempty"`
+	Itineraries    []AmadeusItinerary `json:"itineraries"`
+	Currency       string             `json:"currency,omitempty"`
+}
+
+type AmadeusUnits struct {
+	Seats           int `json:"seats"`
+	Recommendations int `json:"recommendations"`
+}
+
+type AmadeusPax struct {
+	PTC   string `json:"ptc"`
+	Count int    `json:"count"`
+}
+
+// AmadeusCarriers qualifier is "M" for mandatory and "X" for excluded carriers.
+type AmadeusCarriers struct {
+	Qualifier string   `json:"qualifier"`
+	Codes     []string `json:"codes"`
+}
+
+type AmadeusItinerary struct {
+	Ref       int    `json:"ref"`
+	Departure string `json:"departure"`
+	Arrival   string `json:"arrival"`
+	Date      string `json:"date"`
+}
+
+type AmadeusMapper struct{}
+
+func NewAmadeusMapper() *AmadeusMapper {
+	return &AmadeusMapper{}
+}
+
+func (m *AmadeusMapper) Map(r supplier.Request) (*AmadeusRequest, error) {
+	cabin, ok := amadeusCabin[r.Cabin]
+	if !ok {
+		return nil, fmt.Errorf("%w: cabin %q", supplier.ErrUnsupportedRequest, r.Cabin)
+	}
+
+	req := &AmadeusRequest{
+		Cabin:          cabin,
+		MaxConnections: r.MaxStops,
+		NonStop:        r.DirectOnly,
+		FreeBaggage:    r.BaggageOnly,
+		Currency:       r.Currency,
+		NumberOfUnits: AmadeusUnits{
+			Recommendations: amadeusRecommendations,
+		},
+	}
+
+	for _, p := range r.Passengers {
+		req.PaxReference = append(req.PaxReference, AmadeusPax{PTC: amadeusPTC[p.Type], Count: p.Count})
+		if p.Type != sro.PassengerTypeINF {
+			req.NumberOfUnits.Seats += p.Count
+		}
+	}
+
+	if len(r.Carriers.Include) > 0 {
+		req.Carriers = &AmadeusCarriers{Qualifier: "M", Codes: r.Carriers.Include}
+	} else if len(r.Carriers.Exclude) > 0 {
+		req.Carriers = &AmadeusCarriers{Qualifier: "X", Codes: r.Carriers.Exclude}
+	}
+
+	for i, leg := range r.Legs {
+		date, err := time.Parse("2006-01-02", leg.Date)
+		if err != nil {
+			return nil, err
+		}
+		req.Itineraries = append(req.Itineraries, AmadeusItinerary{
+			Ref:       i + 1,
+			Departure: leg.Origin,
+			Arrival:   leg.Destination,
+			Date:      date.Format("020106"),
+		})
+	}
+
+	return req, nil
+}
@@ -0,0 +1,26 @@
+package mappers
+
+import (
+	"fmt"
+
+	"github.com/de4et/flight-booking/internal/model/supplier"
+)
+
+// Func translates a provider-neutral request into the request of a GDS.
+type Func func(supplier.Request) (any, error)
+
+// For returns the mapper of the GDS.
+func For(gds supplier.GDS) (Func, error) {
+	switch gds {
+	case supplier.GDSAmadeus:
+		m := NewAmadeusMapper()
+		return func(r supplier.Request) (any, error) { return m.Map(r) }, nil
+	case supplier.GDSSabre:
+		m := NewSabreMapper()
+		return func(r supplier.Request) (any, error) { return m.Map(r) }, nil
+	case supplier.GDSTravelport:
+		m := NewTravelportMapper()
+		return func(r supplier.Request) (any, error) { return m.Map(r) }, nil
+	}
+	return nil, fmt.Errorf("%w: GDS %q", supplier.ErrUnsupportedRequest, gds)
+}
@@ -0,0 +1,91 @@
+package mappers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/supplier"
+	"github.com/de4et/flight-booking/internal/service/providers/mappers"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var cases = []struct {
+	name  string
+	token string
+}{
+	{name: "ow_simple", token: "AKV40000OWE1000000091MOWLED20241015"},
+	{name: "rt_family_included_carriers", token: "AKV40000RTE2110000020MOWAER20241015AERMOW20241025_I_SU.S7_RUB"},
+	{name: "cx_business_excluded_carriers", token: "AKV40000CXB1000100110LEDIST20241015ISTDXB20241020DXBLED20241101_E_TK_USD_EN"},
+	{name: "ow_direct_senior_youth", token: "AKV40000OWW0001101190SVOOVB20241015"},
+}
+
+func TestMappers(t *testing.T) {
+	gdss := []supplier.GDS{supplier.GDSAmadeus, supplier.GDSSabre, supplier.GDSTravelport}
+
+	for _, gds := range gdss {
+		mapFn, err := mappers.For(gds)
+		if err != nil {
+			t.Fatalf("For(%s) error = %v", gds, err)
+		}
+
+		for _, tt := range cases {
+			t.Run(fmt.Sprintf("%s/%s", gds, tt.name), func(t *testing.T) {
+				s, err := sro.FromToken(tt.token)
+				if err != nil {
+					t.Fatalf("FromToken() error = %v", err)
+				}
+
+				r, err := supplier.FromSRO(*s)
+				if err != nil {
+					t.Fatalf("FromSRO() error = %v", err)
+				}
+
+				req, err := mapFn(r)
+				if err != nil {
+					t.Fatalf("Map() error = %v", err)
+				}
+
+				got, err := json.MarshalIndent(req, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assertGolden(t, filepath.Join("testdata", string(gds)+"_"+tt.name+".golden"), got)
+			})
+		}
+	}
+}
+
+func TestFor_Unknown(t *testing.T) {
+	if _, err := mappers.For("galileo"); !errors.Is(err, supplier.ErrUnsupportedRequest) {
+		t.Errorf("For(galileo) error = %v, want %v", err, supplier.ErrUnsupportedRequest)
+	}
+}
+
+func assertGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("couldn't read golden file (run with -update to create): %v", err)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,117 @@
+package mappers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/supplier"
+)
+
+var sabrePTC = map[sro.PassengerType]string{
+	sro.PassengerTypeADT: "ADT",
+	sro.PassengerTypeCHD: "CNN",
+	sro.PassengerTypeINF: "INF",
+	sro.PassengerTypeSRC: "SRC",
+	sro.PassengerTypeYTH: "YTH",
+}
+
+var sabreCabin = map[supplier.Cabin]string{
+	supplier.CabinEconomy:        "Y",
+	supplier.CabinPremiumEconomy: "S",
+	supplier.CabinBusiness:       "C",
+	supplier.CabinFirst:          "F",
+}
+
+type SabreRequest struct {
+	OriginDestinationInformation []SabreOriginDestination `json:"OriginDestinationInformation"`
+	TravelPreferences            SabreTravelPreferences   `json:"TravelPreferences"`
+	TravelerInfoSummary          SabreTravelerInfo        `json:"TravelerInfoSummary"`
+}
+
+type SabreOriginDestination struct {
+	RPH                 string `json:"RPH"`
+	DepartureDateTime   string `json:"DepartureDateTime"`
+	OriginLocation      string `json:"OriginLocation"`
+	DestinationLocation string `json:"DestinationLocation"`
+}
+
+type SabreTravelPreferences struct {
+	CabinPref        SabreCabinPref    `json:"CabinPref"`
+	VendorPref       []SabreVendorPref `json:"VendorPref,omitempty"`
+	MaxStopsQuantity int               `json:"MaxStopsQuantity"`
+	FreeBaggage      bool              `json:"FreeBaggage"`
+}
+
+type SabreCabinPref struct {
+	Cabin       string `json:"Cabin"`
+	PreferLevel string `json:"PreferLevel"`
+}
+
+// SabreVendorPref prefer level is "Only" for included and "Unacceptable" for
+// excluded carriers.
+type SabreVendorPref struct {
+	Code        string `json:"Code"`
+	PreferLevel string `json:"PreferLevel"`
+}
+
+type SabreTravelerInfo struct {
+	SeatsRequested   int                 `json:"SeatsRequested"`
+	AirTravelerAvail []SabrePassengerQty `json:"AirTravelerAvail"`
+	Currency         string              `json:"Currency,omitempty"`
+}
+
+type SabrePassengerQty struct {
+	Code     string `json:"Code"`
+	Quantity int    `json:"Quantity"`
+}
+
+type SabreMapper struct{}
+
+func NewSabreMapper() *SabreMapper {
+	return &SabreMapper{}
+}
+
+func (m *SabreMapper) Map(r supplier.Request) (*SabreRequest, error) {
+	cabin, ok := sabreCabin[r.Cabin]
+	if !ok {
+		return nil, fmt.Errorf("%w: cabin %q", supplier.ErrUnsupportedRequest, r.Cabin)
+	}
+
+	req := &SabreRequest{
+		TravelPreferences: SabreTravelPreferences{
+			CabinPref:        SabreCabinPref{Cabin: cabin, PreferLevel: "Preferred"},
+			MaxStopsQuantity: r.MaxStops,
+			FreeBaggage:      r.BaggageOnly,
+		},
+		TravelerInfoSummary: SabreTravelerInfo{
+			Currency: r.Currency,
+		},
+	}
+
+	for _, leg := range r.Legs {
+		req.OriginDestinationInformation = append(req.OriginDestinationInformation, SabreOriginDestination{
+			RPH:                 strconv.Itoa(len(req.OriginDestinationInformation) + 1),
+			DepartureDateTime:   leg.Date + "T00:00:00",
+			OriginLocation:      leg.Origin,
+			DestinationLocation: leg.Destination,
+		})
+	}
+
+	for _, p := range r.Passengers {
+		req.TravelerInfoSummary.AirTravelerAvail = append(req.TravelerInfoSummary.AirTravelerAvail,
+			SabrePassengerQty{Code: sabrePTC[p.Type], Quantity: p.Count})
+		if p.Type != sro.PassengerTypeINF {
+			req.TravelerInfoSummary.SeatsRequested += p.Count
+		}
+	}
+
+	for _, c := range r.Carriers.Include {
+		req.TravelPreferences.VendorPref = append(req.TravelPreferences.VendorPref, SabreVendorPref{Code: c, PreferLevel: "Only"})
+	}
+	for _, c := range r.Carriers.Exclude {
+		req.TravelPreferences.VendorPref = append(req.TravelPreferences.VendorPref, SabreVendorPref{Code: c, PreferLevel: "Unacceptable"})
+	}
+
+	return req, nil
+}
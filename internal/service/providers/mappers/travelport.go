@@ -0,0 +1,106 @@
+package mappers
+
+import (
+	"fmt"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/supplier"
+)
+
+// Travelport requires an age for children and infants; the defaults are used
+// until real passenger data is known at booking time.
+const (
+	travelportChildAge  = 8
+	travelportInfantAge = 1
+)
+
+var travelportPTC = map[sro.PassengerType]string{
+	sro.PassengerTypeADT: "ADT",
+	sro.PassengerTypeCHD: "CNN",
+	sro.PassengerTypeINF: "INF",
+	sro.PassengerTypeSRC: "SRC",
+	sro.PassengerTypeYTH: "YTH",
+}
+
+var travelportCabin = map[supplier.Cabin]string{
+	supplier.CabinEconomy:        "Economy",
+	supplier.CabinPremiumEconomy: "PremiumEconomy",
+	supplier.CabinBusiness:       "Business",
+	supplier.CabinFirst:          "First",
+}
+
+type TravelportRequest struct {
+	SearchAirLeg       []TravelportAirLeg        `json:"SearchAirLeg"`
+	AirSearchModifiers TravelportSearchModifiers `json:"AirSearchModifiers"`
+	SearchPassenger    []TravelportPassenger     `json:"SearchPassenger"`
+	CurrencyType       string                    `json:"CurrencyType,omitempty"`
+	RequireFreeBaggage bool                      `json:"RequireFreeBaggage"`
+	ReturnBrandedFares bool                      `json:"ReturnBrandedFares"`
+}
+
+type TravelportAirLeg struct {
+	SearchOrigin      string `json:"SearchOrigin"`
+	SearchDestination string `json:"SearchDestination"`
+	SearchDepTime     string `json:"SearchDepTime"`
+}
+
+type TravelportSearchModifiers struct {
+	PreferredCabins    []string `json:"PreferredCabins"`
+	PermittedCarriers  []string `json:"PermittedCarriers,omitempty"`
+	ProhibitedCarriers []string `json:"ProhibitedCarriers,omitempty"`
+	MaxStops           int      `json:"MaxStops"`
+}
+
+type TravelportPassenger struct {
+	Code string `json:"Code"`
+	Age  int    `json:"Age,omitempty"`
+}
+
+type TravelportMapper struct{}
+
+func NewTravelportMapper() *TravelportMapper {
+	return &TravelportMapper{}
+}
+
+func (m *TravelportMapper) Map(r supplier.Request) (*TravelportRequest, error) {
+	cabin, ok := travelportCabin[r.Cabin]
+	if !ok {
+		return nil, fmt.Errorf("%w: cabin %q", supplier.ErrUnsupportedRequest, r.Cabin)
+	}
+
+	req := &TravelportRequest{
+		AirSearchModifiers: TravelportSearchModifiers{
+			PreferredCabins:    []string{cabin},
+			PermittedCarriers:  r.Carriers.Include,
+			ProhibitedCarriers: r.Carriers.Exclude,
+			MaxStops:           r.MaxStops,
+		},
+		CurrencyType:       r.Currency,
+		RequireFreeBaggage: r.BaggageOnly,
+		ReturnBrandedFares: true,
+	}
+
+	for _, leg := range r.Legs {
+		req.SearchAirLeg = append(req.SearchAirLeg, TravelportAirLeg{
+			SearchOrigin:      leg.Origin,
+			SearchDestination: leg.Destination,
+			SearchDepTime:     leg.Date,
+		})
+	}
+
+	// Travelport lists every passenger separately.
+	for _, p := range r.Passengers {
+		for range p.Count {
+			passenger := TravelportPassenger{Code: travelportPTC[p.Type]}
+			switch p.Type {
+			case sro.PassengerTypeCHD:
+				passenger.Age = travelportChildAge
+			case sro.PassengerTypeINF:
+				passenger.Age = travelportInfantAge
+			}
+			req.SearchPassenger = append(req.SearchPassenger, passenger)
+		}
+	}
+
+	return req, nil
+}
@@ -3,28 +3,55 @@ package providers
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/de4et/flight-booking/internal/model/order"
 	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/supplier"
 	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service/providers/mappers"
 )
 
 type StubGDS struct {
-	secs int
+	name   string
+	gds    supplier.GDS
+	mapper mappers.Func
+	delay  time.Duration
 }
 
-func NewStubGDS(secs int) *StubGDS {
-	return &StubGDS{
-		secs: secs,
+// NewStubGDS returns a stub answering searches after the delay. Searches are
+// mapped into requests of the GDS as a real provider would send them. The
+// name must be unique, later calls find the provider of a trip by it.
+func NewStubGDS(name string, gds supplier.GDS, delay time.Duration) (*StubGDS, error) {
+	mapper, err := mappers.For(gds)
+	if err != nil {
+		return nil, err
 	}
+	return &StubGDS{
+		name:   name,
+		gds:    gds,
+		mapper: mapper,
+		delay:  delay,
+	}, nil
 }
 
 func (gds *StubGDS) Search(ctx context.Context, s sro.SRO) (*trip.Trips, error) {
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
+	r, err := supplier.FromSRO(s)
+	if err != nil {
+		return nil, err
+	}
+	req, err := gds.mapper(r)
+	if err != nil {
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Stub GDS search", "gds", gds.gds, "request", req)
+
 	select {
-	case <-time.After(time.Duration(gds.secs) * time.Second):
+	case <-time.After(gds.delay):
 		ts := trip.NewTrips()
 		for i := range 15 {
 			ts.AddTrip(trip.Trip{
@@ -33,17 +60,17 @@ func (gds *StubGDS) Search(ctx context.Context, s sro.SRO) (*trip.Trips, error)
 				SID:     "SSIIIDDD",
 				CacheID: fmt.Sprintf("best_cached_id_ever_%d", i),
 				Provider: trip.Provider{
-					Name:              "MyMan",
-					GDS:               "luchiy",
+					Name:              gds.name,
+					GDS:               string(gds.gds),
 					GDSServer:         "111.111.bs.3",
 					OfficeID:          "tam-to",
 					ValidatingCarrier: "chto?",
 				},
 				Segments: []trip.TripSegment{},
-				Prices:   trip.TripPrices{},
+				Prices:   trip.TripPrices{ProviderCurrency: "RUB"},
 				Rules:    trip.FareRules{},
 				Metadata: trip.TripMetadata{},
-				Booking:  trip.TripBooking{},
+				Booking:  trip.TripBooking{BookingActualizationAllowed: true},
 				SRO:      &s,
 			})
 		}
@@ -56,3 +83,28 @@ func (gds *StubGDS) Search(ctx context.Context, s sro.SRO) (*trip.Trips, error)
 func (gds *StubGDS) GetAvailability() bool {
 	return true
 }
+
+func (gds *StubGDS) Name() string {
+	return gds.name
+}
+
+// Actualize confirms the fare as is for another 20 minutes.
+func (gds *StubGDS) Actualize(ctx context.Context, t trip.Trip) (trip.Trip, error) {
+	if ctx.Err() != nil {
+		return trip.Trip{}, ctx.Err()
+	}
+	t.Booking.ProviderRecommendationLimit = time.Now().Add(20 * time.Minute)
+	return t, nil
+}
+
+// Book returns a fake booking reference made of the order ID.
+func (gds *StubGDS) Book(ctx context.Context, o order.Order) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return fmt.Sprintf("STUB%06d", o.ID), nil
+}
+
+func (gds *StubGDS) Cancel(ctx context.Context, _ order.Order) error {
+	return ctx.Err()
+}
@@ -0,0 +1,39 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/supplier"
+	"github.com/de4et/flight-booking/internal/service/providers"
+)
+
+func TestStubGDS_Search(t *testing.T) {
+	gds, err := providers.NewStubGDS("stub-sabre", supplier.GDSSabre, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := sro.FromToken("AKV40000OWE1000000091MOWLED20241015")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := gds.Search(context.Background(), *s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tr := range ts.ToArray() {
+		if tr.Provider.Name != gds.Name() || tr.Provider.GDS != string(supplier.GDSSabre) {
+			t.Fatalf("trip provider = %+v, want stub-sabre at sabre", tr.Provider)
+		}
+	}
+}
+
+func TestNewStubGDS_UnknownGDS(t *testing.T) {
+	if _, err := providers.NewStubGDS("stub", "galileo", 0); !errors.Is(err, supplier.ErrUnsupportedRequest) {
+		t.Errorf("NewStubGDS() error = %v, want %v", err, supplier.ErrUnsupportedRequest)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/metrics"
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+)
+
+type rateLimitStore interface {
+	Take(context.Context, string, ratelimit.Bucket) (time.Duration, error)
+	Count(context.Context, string, time.Time) (int, error)
+}
+
+// RateLimiter limits partners by their ChannelToken.PartnerCode, separately
+// for API requests and for searches that reach providers. Limits are not
+// enforced while the store is unavailable, so a Redis outage doesn't take
+// the API down with it.
+type RateLimiter struct {
+	store    rateLimitStore
+	defaults ratelimit.Limits
+}
+
+func NewRateLimiter(store rateLimitStore, defaults ratelimit.Limits) *RateLimiter {
+	return &RateLimiter{
+		store:    store,
+		defaults: defaults,
+	}
+}
+
+// AllowRequest takes an API request of the partner from its limits.
+func (l *RateLimiter) AllowRequest(ctx context.Context, p partner.Partner, test bool) error {
+	if !test {
+		metrics.PartnerRequestsTotal.WithLabelValues(p.Code).Inc()
+	}
+
+	limits := p.Limits.Or(l.defaults)
+	if limits.RequestsPerSecond == 0 {
+		return nil
+	}
+	return l.take(ctx, p.Code, ratelimit.KindRequests, ratelimit.PerSecond(limits.RequestsPerSecond), test)
+}
+
+// AllowSearch takes a provider fan-out of the partner code from its limits
+// and daily quota. Limits of the authenticated partner of the request are
+// used, the defaults otherwise.
+func (l *RateLimiter) AllowSearch(ctx context.Context, partnerCode string, test bool) error {
+	limits := l.defaults
+	if p, ok := partner.FromContext(ctx); ok && p.Code == partnerCode {
+		limits = p.Limits.Or(l.defaults)
+	}
+
+	if limits.SearchesPerMinute > 0 {
+		if err := l.take(ctx, partnerCode, ratelimit.KindSearches, ratelimit.PerMinute(limits.SearchesPerMinute), test); err != nil {
+			return err
+		}
+	}
+
+	if limits.DailySearches > 0 {
+		now := time.Now()
+		n, err := l.store.Count(ctx, partnerCode+"_"+string(ratelimit.KindDailySearches), now)
+		if err != nil {
+			slog.WarnContext(ctx, "Couldn't check daily search quota, allowing", "error", err)
+		} else if n > limits.DailySearches {
+			return l.exceeded(partnerCode, ratelimit.KindDailySearches, ratelimit.EndOfDay(now).Sub(now), test)
+		}
+	}
+
+	if !test {
+		metrics.ProviderSearchesTotal.WithLabelValues(partnerCode).Inc()
+	}
+	return nil
+}
+
+func (l *RateLimiter) take(ctx context.Context, partnerCode string, kind ratelimit.Kind, b ratelimit.Bucket, test bool) error {
+	wait, err := l.store.Take(ctx, partnerCode+"_"+string(kind), b)
+	if err != nil {
+		slog.WarnContext(ctx, "Couldn't check rate limit, allowing", "limit", kind, "error", err)
+		return nil
+	}
+	if wait > 0 {
+		return l.exceeded(partnerCode, kind, wait, test)
+	}
+	return nil
+}
+
+func (l *RateLimiter) exceeded(partnerCode string, kind ratelimit.Kind, wait time.Duration, test bool) error {
+	if !test {
+		metrics.RateLimitedTotal.WithLabelValues(partnerCode, string(kind)).Inc()
+	}
+	return &ratelimit.Exceeded{Kind: kind, RetryAfter: wait}
+}
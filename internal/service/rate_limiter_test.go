@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/partner"
+	"github.com/de4et/flight-booking/internal/model/ratelimit"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+// fakeRateLimitStore never refills buckets.
+type fakeRateLimitStore struct {
+	mu     sync.Mutex
+	taken  map[string]int
+	counts map[string]int
+	err    error
+}
+
+func newFakeRateLimitStore() *fakeRateLimitStore {
+	return &fakeRateLimitStore{taken: make(map[string]int), counts: make(map[string]int)}
+}
+
+func (s *fakeRateLimitStore) Take(_ context.Context, key string, b ratelimit.Bucket) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.taken[key] >= b.Burst {
+		return time.Second, nil
+	}
+	s.taken[key]++
+	return 0, nil
+}
+
+func (s *fakeRateLimitStore) Count(_ context.Context, key string, _ time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.counts[key]++
+	return s.counts[key], nil
+}
+
+func TestRateLimiter_AllowRequest(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeRateLimitStore()
+	limiter := service.NewRateLimiter(store, ratelimit.Limits{RequestsPerSecond: 2})
+
+	p := partner.Partner{Code: "AKV4"}
+	for range 2 {
+		if err := limiter.AllowRequest(ctx, p, false); err != nil {
+			t.Fatalf("AllowRequest() error = %v, want nil", err)
+		}
+	}
+	var exceeded *ratelimit.Exceeded
+	if err := limiter.AllowRequest(ctx, p, false); !errors.As(err, &exceeded) || exceeded.Kind != ratelimit.KindRequests {
+		t.Fatalf("AllowRequest() error = %v, want requests limit", err)
+	}
+
+	// Partners have separate buckets and may have their own limits.
+	if err := limiter.AllowRequest(ctx, partner.Partner{Code: "BBBB"}, false); err != nil {
+		t.Errorf("AllowRequest() of another partner error = %v, want nil", err)
+	}
+	p.Limits.RequestsPerSecond = 5
+	if err := limiter.AllowRequest(ctx, p, false); err != nil {
+		t.Errorf("AllowRequest() with a higher partner limit error = %v, want nil", err)
+	}
+
+	// A failing store doesn't block requests.
+	store.err = errors.New("connection refused")
+	if err := limiter.AllowRequest(ctx, partner.Partner{Code: "BBBB", Limits: ratelimit.Limits{RequestsPerSecond: 1}}, false); err != nil {
+		t.Errorf("AllowRequest() with failing store error = %v, want nil", err)
+	}
+}
+
+func TestMultipleSearchService_SearchByToken_RateLimit(t *testing.T) {
+	tokens := []string{
+		"AKV40000OWE1000000091MOWLED20241015",
+		"AKV40000OWE1000000091MOWLED20241016",
+		"AKV40000OWE1000000091MOWLED20241017",
+	}
+
+	p := &fakeProvider{}
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(p)
+	svc.SetRateLimiter(service.NewRateLimiter(newFakeRateLimitStore(), ratelimit.Limits{SearchesPerMinute: 5, DailySearches: 2}))
+
+	for _, token := range tokens[:2] {
+		if _, err := svc.SearchByToken(context.Background(), token); err != nil {
+			t.Fatalf("SearchByToken(%s) error = %v", token, err)
+		}
+	}
+
+	// Cache hits don't count.
+	if _, err := svc.SearchByToken(context.Background(), tokens[0]); err != nil {
+		t.Fatalf("SearchByToken() cache hit error = %v", err)
+	}
+
+	var exceeded *ratelimit.Exceeded
+	_, err := svc.SearchByToken(context.Background(), tokens[2])
+	if !errors.As(err, &exceeded) || exceeded.Kind != ratelimit.KindDailySearches {
+		t.Fatalf("SearchByToken() error = %v, want daily quota", err)
+	}
+	if calls := p.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/metrics"
+	"github.com/de4et/flight-booking/internal/model/analytics"
+)
+
+const (
+	DefaultSearchLogQueueSize     = 10000
+	DefaultSearchLogBatchSize     = 500
+	DefaultSearchLogFlushInterval = 2 * time.Second
+	// searchLogWriteTimeout bounds a batch write, including the last one on
+	// shutdown.
+	searchLogWriteTimeout = 5 * time.Second
+)
+
+type searchLogRepository interface {
+	SaveSearches(context.Context, []analytics.Search) error
+	LookToBook(context.Context, analytics.Period) ([]analytics.LookToBook, error)
+}
+
+// SearchAnalytics writes searches to the repository in batches in the
+// background. Searches never wait for it: when the queue is full or a write
+// fails the searches are dropped and counted in metrics.
+type SearchAnalytics struct {
+	repo          searchLogRepository
+	queue         chan analytics.Search
+	batchSize     int
+	flushInterval time.Duration
+}
+
+func NewSearchAnalytics(repo searchLogRepository, queueSize int) *SearchAnalytics {
+	if queueSize <= 0 {
+		queueSize = DefaultSearchLogQueueSize
+	}
+	return &SearchAnalytics{
+		repo:          repo,
+		queue:         make(chan analytics.Search, queueSize),
+		batchSize:     DefaultSearchLogBatchSize,
+		flushInterval: DefaultSearchLogFlushInterval,
+	}
+}
+
+// Record queues the search for writing. It doesn't block.
+func (a *SearchAnalytics) Record(s analytics.Search) {
+	select {
+	case a.queue <- s:
+	default:
+		metrics.SearchLogDroppedTotal.Inc()
+	}
+}
+
+// Run writes queued searches every flush interval, or as soon as a batch is
+// full, until ctx is done. Searches queued by then are written before it
+// returns.
+func (a *SearchAnalytics) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]analytics.Search, 0, a.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			a.drain(context.WithoutCancel(ctx), batch)
+			return
+		case s := <-a.queue:
+			batch = append(batch, s)
+			if len(batch) < a.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+
+		a.flush(ctx, batch)
+		batch = batch[:0]
+	}
+}
+
+func (a *SearchAnalytics) drain(ctx context.Context, batch []analytics.Search) {
+	for {
+		select {
+		case s := <-a.queue:
+			batch = append(batch, s)
+			if len(batch) == a.batchSize {
+				a.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		default:
+			a.flush(ctx, batch)
+			return
+		}
+	}
+}
+
+func (a *SearchAnalytics) flush(ctx context.Context, batch []analytics.Search) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, searchLogWriteTimeout)
+	defer cancel()
+	if err := a.repo.SaveSearches(ctx, batch); err != nil {
+		slog.ErrorContext(ctx, "Couldn't write search log", "count", len(batch), "error", err)
+		metrics.SearchLogDroppedTotal.Add(float64(len(batch)))
+	}
+}
+
+// LookToBook returns production searches and orders per partner in the
+// period. Searches still queued are not counted.
+func (a *SearchAnalytics) LookToBook(ctx context.Context, p analytics.Period) ([]analytics.LookToBook, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return a.repo.LookToBook(ctx, p)
+}
@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/de4et/flight-booking/internal/model/analytics"
+	"github.com/de4et/flight-booking/internal/service"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeSearchLogRepo struct {
+	mu      sync.Mutex
+	batches [][]analytics.Search
+}
+
+func (r *fakeSearchLogRepo) SaveSearches(_ context.Context, searches []analytics.Search) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, append([]analytics.Search(nil), searches...))
+	return nil
+}
+
+func (r *fakeSearchLogRepo) LookToBook(context.Context, analytics.Period) ([]analytics.LookToBook, error) {
+	return nil, nil
+}
+
+func (r *fakeSearchLogRepo) tokens() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([][]string, 0, len(r.batches))
+	for _, b := range r.batches {
+		tokens := make([]string, 0, len(b))
+		for _, s := range b {
+			tokens = append(tokens, s.Token)
+		}
+		res = append(res, tokens)
+	}
+	return res
+}
+
+func TestSearchAnalytics_Run(t *testing.T) {
+	repo := &fakeSearchLogRepo{}
+	a := service.NewSearchAnalytics(repo, 2)
+
+	a.Record(analytics.Search{Token: "a"})
+	a.Record(analytics.Search{Token: "b"})
+	// Dropped, the queue is full.
+	a.Record(analytics.Search{Token: "c"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(repo.tokens()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Queued before shutdown, written by Run before it returns.
+	a.Record(analytics.Search{Token: "d"})
+	cancel()
+	<-done
+
+	got := repo.tokens()
+	want := [][]string{{"a", "b"}, {"d"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("written batches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearchAnalytics_LookToBook_InvalidPeriod(t *testing.T) {
+	a := service.NewSearchAnalytics(&fakeSearchLogRepo{}, 0)
+	now := time.Now()
+
+	_, err := a.LookToBook(context.Background(), analytics.Period{From: now, To: now.Add(-time.Hour)})
+	if err != analytics.ErrInvalidPeriod {
+		t.Errorf("LookToBook() error = %v, want %v", err, analytics.ErrInvalidPeriod)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// snapshot keeps an in-memory copy of what load returns, reloaded at most
+// every interval or right after invalidate. Callers needing a reload at the
+// same time share one load. If a reload fails the value loaded before keeps
+// being used.
+type snapshot[T any] struct {
+	name     string
+	interval time.Duration
+	load     func(context.Context) (T, error)
+
+	inflight singleflight.Group
+
+	mu        sync.RWMutex
+	value     T
+	loaded    bool
+	loadedAt  time.Time
+	loadedGen int
+	// gen counts invalidations, a load started before the last one is
+	// stale as soon as it finishes.
+	gen int
+}
+
+func newSnapshot[T any](name string, interval time.Duration, load func(context.Context) (T, error)) *snapshot[T] {
+	return &snapshot[T]{
+		name:     name,
+		interval: interval,
+		load:     load,
+	}
+}
+
+// get returns the loaded value, reloading it first if it is stale.
+func (s *snapshot[T]) get(ctx context.Context) (T, error) {
+	s.mu.RLock()
+	value, fresh, gen := s.value, s.isFresh(time.Now()), s.gen
+	s.mu.RUnlock()
+	if fresh {
+		return value, nil
+	}
+
+	resCh := s.inflight.DoChan(strconv.Itoa(gen), func() (any, error) {
+		// The load is shared between callers, so it must outlive the one
+		// that started it.
+		return s.reload(context.WithoutCancel(ctx), gen)
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case res := <-resCh:
+		if res.Err != nil {
+			var zero T
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	}
+}
+
+func (s *snapshot[T]) reload(ctx context.Context, gen int) (T, error) {
+	now := time.Now()
+	value, err := s.load(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err == nil:
+		s.value, s.loaded = value, true
+	case s.loaded:
+		slog.WarnContext(ctx, "Couldn't reload "+s.name+", using the loaded ones", "error", err)
+	default:
+		return value, err
+	}
+	s.loadedAt, s.loadedGen = now, gen
+	return s.value, nil
+}
+
+func (s *snapshot[T]) isFresh(now time.Time) bool {
+	return s.loaded && s.loadedGen == s.gen && now.Sub(s.loadedAt) <= s.interval
+}
+
+// invalidate makes the next get reload.
+func (s *snapshot[T]) invalidate() {
+	s.mu.Lock()
+	s.gen++
+	s.mu.Unlock()
+}
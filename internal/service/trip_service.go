@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/de4et/flight-booking/internal/logger"
+	"github.com/de4et/flight-booking/internal/model/fx"
+	"github.com/de4et/flight-booking/internal/model/pricing"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+)
+
+var (
+	ErrTripNotFound             = errors.New("trip not found, search again")
+	ErrActualizationUnsupported = errors.New("trip can't be actualized")
+	ErrProviderFailed           = errors.New("provider request failed")
+)
+
+// namedProvider is implemented by providers that support calls after the
+// search. Name must match Trip.Provider.Name of their trips.
+type namedProvider interface {
+	Name() string
+}
+
+// actualizer is implemented by providers that can re-price a trip they found.
+type actualizer interface {
+	Actualize(context.Context, trip.Trip) (trip.Trip, error)
+}
+
+// GetTrip loads a trip from the cached result of the token search.
+func (svc *MultipleSearchService) GetTrip(ctx context.Context, token, cacheID string) (trip.Trip, error) {
+	t, _, err := svc.getTrip(ctx, token, cacheID)
+	return t, err
+}
+
+func (svc *MultipleSearchService) getTrip(ctx context.Context, token, cacheID string) (trip.Trip, *trip.Trips, error) {
+	clientSRO, err := sro.FromToken(token)
+	if err != nil {
+		return trip.Trip{}, nil, ErrInvalidSRO
+	}
+	if err := authorize(ctx, clientSRO); err != nil {
+		return trip.Trip{}, nil, err
+	}
+
+	ts, err := svc.cacheFor(clientSRO).Get(ctx, clientSRO.CanonicalToken())
+	if err != nil {
+		if errors.Is(err, ErrNoCacheHit) {
+			return trip.Trip{}, nil, ErrTripNotFound
+		}
+		return trip.Trip{}, nil, err
+	}
+
+	t, err := ts.Get(cacheID)
+	if err != nil {
+		return trip.Trip{}, nil, ErrTripNotFound
+	}
+	t.SRO = clientSRO
+	t.Booking.Bookable = t.IsBookable()
+	return t, ts, nil
+}
+
+// Actualize re-prices a found trip at its provider. The cached result is
+// updated with the new fare, so booking picks it up, or loses the trip if it
+// is no longer available.
+func (svc *MultipleSearchService) Actualize(ctx context.Context, token, cacheID string) (trip.Actualization, error) {
+	found, ts, err := svc.getTrip(ctx, token, cacheID)
+	if err != nil {
+		return trip.Actualization{}, err
+	}
+
+	ctx = logger.WithContext(ctx, "cacheId", cacheID)
+	ctx = logger.WithContext(ctx, "provider", found.Provider.Name)
+	ctx = withTestMode(ctx, found.SRO)
+
+	// Virtual trips are sold as separate tickets and can't be booked.
+	if !found.IsBookable() {
+		return trip.Actualization{}, fmt.Errorf("%w: virtual trip", ErrActualizationUnsupported)
+	}
+	if !found.Booking.BookingActualizationAllowed {
+		return trip.Actualization{}, fmt.Errorf("%w: not allowed for the fare", ErrActualizationUnsupported)
+	}
+
+	a, ok := svc.providerOf(found.Provider.Name, found.SRO.Metadata.IsTest).(actualizer)
+	if !ok {
+		return trip.Actualization{}, fmt.Errorf("%w: not supported by %s", ErrActualizationUnsupported, found.Provider.Name)
+	}
+
+	// Providers get their own price back and re-price from it.
+	unpriced := found
+	fx.Restore(&unpriced)
+	pricing.Reset(&unpriced)
+
+	actual, err := a.Actualize(ctx, unpriced)
+	if errors.Is(err, trip.ErrUnavailable) {
+		slog.InfoContext(ctx, "Trip is no longer available")
+		return svc.unavailable(ctx, found, ts), nil
+	}
+	if err != nil {
+		return trip.Actualization{}, fmt.Errorf("%w: %s: %w", ErrProviderFailed, found.Provider.Name, err)
+	}
+
+	// Cached results carry the canonical SRO.
+	canonical := found.SRO.Canonical()
+
+	// The new fare goes through the same steps as search results.
+	bans, err := svc.banListFor(ctx, canonical.ChannelToken.PartnerCode)
+	if err != nil {
+		return trip.Actualization{}, err
+	}
+	priced := trip.NewTrips()
+	priced.Set(actual.CacheID, actual)
+	applyBanList(ctx, bans, priced)
+	if !priced.Contains(actual.CacheID) {
+		return svc.unavailable(ctx, found, ts), nil
+	}
+	normalizeTimes(ctx, priced)
+	if err := svc.price(ctx, canonical, priced); err != nil {
+		return trip.Actualization{}, err
+	}
+	actual, err = priced.Get(actual.CacheID)
+	if err != nil {
+		return trip.Actualization{}, fmt.Errorf("%w: %s", fx.ErrNoRate, canonical.Metadata.Currency)
+	}
+
+	res := trip.NewActualization(found, actual)
+	slog.InfoContext(ctx, "Trip actualized", "priceDelta", res.PriceDelta, "rulesChanged", res.RulesChanged)
+
+	actual.SRO = canonical
+
+	updated := trip.NewTrips()
+	updated.Merge(ts)
+	updated.Set(actual.CacheID, actual)
+	if err := svc.cacheFor(canonical).Set(ctx, canonical.GetToken(), updated); err != nil {
+		slog.WarnContext(ctx, "Couldn't update actualized trip in cache", "error", err)
+	}
+
+	res.Trip.SRO = found.SRO
+	res.Trip.Booking.Bookable = res.Trip.IsBookable()
+	return res, nil
+}
+
+// unavailable removes the trip from the cached result, so it is neither
+// offered nor booked any more.
+func (svc *MultipleSearchService) unavailable(ctx context.Context, found trip.Trip, ts *trip.Trips) trip.Actualization {
+	canonical := found.SRO.Canonical()
+
+	updated := ts.Clone()
+	updated.RemoveTrip(&found)
+	if err := svc.cacheFor(canonical).Set(ctx, canonical.GetToken(), updated); err != nil {
+		slog.WarnContext(ctx, "Couldn't remove unavailable trip from cache", "error", err)
+	}
+	return trip.Actualization{Trip: found, OldPrice: found.GetPrice()}
+}
+
+// providerOf returns the provider that found the trip, or nil if it is
+// unknown. Test trips are looked up among the sandbox providers only.
+func (svc *MultipleSearchService) providerOf(name string, test bool) provider {
+	for _, p := range svc.registered(test) {
+		if np, ok := p.(namedProvider); ok && np.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
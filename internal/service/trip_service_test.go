@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/de4et/flight-booking/internal/model/ban"
+	"github.com/de4et/flight-booking/internal/model/sro"
+	"github.com/de4et/flight-booking/internal/model/trip"
+	"github.com/de4et/flight-booking/internal/service"
+)
+
+// repricingProvider raises the price of trip_0, sells out trip_1 and doesn't
+// allow actualization of trip_2.
+type repricingProvider struct{}
+
+func (p *repricingProvider) Search(_ context.Context, s sro.SRO) (*trip.Trips, error) {
+	ts := trip.NewTrips()
+	for i := range 3 {
+		ts.AddTrip(trip.Trip{
+			CacheID:  fmt.Sprintf("trip_%d", i),
+			Provider: trip.Provider{Name: p.Name()},
+			Prices:   trip.TripPrices{Price: 100},
+			Booking:  trip.TripBooking{BookingActualizationAllowed: i != 2},
+			SRO:      &s,
+		})
+	}
+	return ts, nil
+}
+
+func (p *repricingProvider) GetAvailability() bool {
+	return true
+}
+
+func (p *repricingProvider) Name() string {
+	return "repricing"
+}
+
+func (p *repricingProvider) Actualize(_ context.Context, t trip.Trip) (trip.Trip, error) {
+	if t.CacheID == "trip_1" {
+		return trip.Trip{}, trip.ErrUnavailable
+	}
+	t.Prices.Price += 50
+	t.Rules.IsRefund = true
+	return t, nil
+}
+
+func TestMultipleSearchService_Actualize(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20301015_I_SU.S7"
+
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&repricingProvider{})
+	if _, err := svc.SearchByToken(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		cacheID   string
+		want      trip.Actualization
+		wantPrice float64
+		wantGone  bool
+		wantErr   error
+	}{
+		{
+			name:      "price changed",
+			token:     token,
+			cacheID:   "trip_0",
+			want:      trip.Actualization{Available: true, OldPrice: 100, PriceDelta: 50, RulesChanged: true},
+			wantPrice: 150,
+		},
+		{
+			name:     "sold out",
+			token:    token,
+			cacheID:  "trip_1",
+			want:     trip.Actualization{OldPrice: 100},
+			wantGone: true,
+		},
+		{
+			name:    "not allowed",
+			token:   token,
+			cacheID: "trip_2",
+			wantErr: service.ErrActualizationUnsupported,
+		},
+		{
+			name:    "unknown trip",
+			token:   token,
+			cacheID: "trip_3",
+			wantErr: service.ErrTripNotFound,
+		},
+		{
+			name:    "not searched",
+			token:   "AKV40000OWE1000000091MOWLED20301016",
+			cacheID: "trip_0",
+			wantErr: service.ErrTripNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.Actualize(context.Background(), tt.token, tt.cacheID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Actualize() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Available != tt.want.Available || got.OldPrice != tt.want.OldPrice ||
+				got.PriceDelta != tt.want.PriceDelta || got.RulesChanged != tt.want.RulesChanged {
+				t.Errorf("Actualize() = %+v, want %+v", got, tt.want)
+			}
+			if got.Trip.SRO.GetToken() != tt.token {
+				t.Errorf("Actualize() trip SRO = %s, want %s", got.Trip.SRO.GetToken(), tt.token)
+			}
+
+			// Later requests see the actualized fare, or no trip at all.
+			cached, err := svc.GetTrip(context.Background(), tt.token, tt.cacheID)
+			if tt.wantGone {
+				if !errors.Is(err, service.ErrTripNotFound) {
+					t.Errorf("GetTrip() error = %v, want %v", err, service.ErrTripNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cached.GetPrice() != tt.wantPrice {
+				t.Errorf("cached price = %v, want %v", cached.GetPrice(), tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestMultipleSearchService_Actualize_Banned(t *testing.T) {
+	const token = "AKV40000OWE1000000091MOWLED20301015"
+	ctx := context.Background()
+
+	bans := service.NewBanService(&fakeBanRepository{})
+	svc := service.NewMultipleSearchService(newFakeCache())
+	svc.AddProviderService(&repricingProvider{})
+	svc.SetBanList(bans)
+	if _, err := svc.SearchByToken(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Banned after the search.
+	if _, err := bans.Create(ctx, ban.Ban{Type: ban.TypeProvider, Value: "repricing", Reason: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.Actualize(ctx, token, "trip_0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Available {
+		t.Error("Actualize() trip available, want banned")
+	}
+	if _, err := svc.GetTrip(ctx, token, "trip_0"); !errors.Is(err, service.ErrTripNotFound) {
+		t.Errorf("GetTrip() error = %v, want %v", err, service.ErrTripNotFound)
+	}
+}